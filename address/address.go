@@ -0,0 +1,347 @@
+// Package address decodes, inspects and encodes Cardano addresses offline, without contacting a cardano-wallet server.
+//
+// Shelley addresses are Bech32-encoded (addr, addr_test, stake, stake_test), Byron addresses are Base58-encoded.
+// Address.Inspect returns the same structure as the InspectAddress operation of the cardano-wallet API.
+package address
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/godano/cardano-wallet-client/bech32"
+)
+
+// Type is the address type, stored in the high nibble of the header byte of Shelley addresses.
+type Type byte
+
+const (
+	TypeBase             Type = 0  // Payment key hash, stake key hash
+	TypeBaseScriptKey    Type = 1  // Payment script hash, stake key hash
+	TypeBaseKeyScript    Type = 2  // Payment key hash, stake script hash
+	TypeBaseScriptScript Type = 3  // Payment script hash, stake script hash
+	TypePointer          Type = 4  // Payment key hash, stake pointer
+	TypePointerScript    Type = 5  // Payment script hash, stake pointer
+	TypeEnterprise       Type = 6  // Payment key hash
+	TypeEnterpriseScript Type = 7  // Payment script hash
+	TypeByron            Type = 8  // Byron (bootstrap) address
+	TypeReward           Type = 14 // Stake key hash
+	TypeRewardScript     Type = 15 // Stake script hash
+)
+
+const typeHeaderNetworkMask = 0x0F
+
+// Network tags of Shelley addresses
+const (
+	NetworkTestnet = 0
+	NetworkMainnet = 1
+)
+
+// HashSize is the size of key hashes and script hashes in addresses (blake2b-224).
+const HashSize = 28
+
+// CredentialKind distinguishes credentials based on key hashes and script hashes.
+type CredentialKind int
+
+const (
+	KeyHash CredentialKind = iota
+	ScriptHash
+)
+
+func (k CredentialKind) String() string {
+	if k == ScriptHash {
+		return "script hash"
+	}
+	return "key hash"
+}
+
+// Credential is a payment or stake credential of a Shelley address.
+type Credential struct {
+	Kind CredentialKind
+	Hash []byte
+}
+
+// Pointer references a stake key registration certificate on the chain.
+type Pointer struct {
+	Slot        uint64
+	TxIndex     uint64
+	OutputIndex uint64
+}
+
+// Address is a decoded Cardano address.
+type Address struct {
+	Type Type
+
+	// Network is the network ID of Shelley addresses, or the protocol magic of Byron addresses.
+	// For Byron mainnet addresses, which do not contain a protocol magic, Network is nil.
+	Network *uint32
+
+	Payment *Credential // nil for reward addresses
+	Stake   *Credential // only for base and reward addresses
+	Pointer *Pointer    // only for pointer addresses
+
+	// Only for Byron addresses
+	Byron *ByronAddress
+
+	raw []byte
+}
+
+// Decode decodes a Bech32-encoded Shelley address or a Base58-encoded Byron address.
+func Decode(addr string) (*Address, error) {
+	if strings.HasPrefix(addr, "addr") || strings.HasPrefix(addr, "stake") {
+		hrp, data, err := bech32.Decode(addr)
+		if err != nil {
+			return nil, err
+		}
+		result, err := FromBytes(data)
+		if err != nil {
+			return nil, err
+		}
+		if expected := result.hrp(); hrp != expected {
+			return nil, fmt.Errorf("Unexpected Bech32 prefix %v for address, expected %v", hrp, expected)
+		}
+		return result, nil
+	}
+	data, err := decodeBase58(addr)
+	if err != nil {
+		return nil, err
+	}
+	return FromBytes(data)
+}
+
+// FromBytes decodes an address from its raw binary representation.
+func FromBytes(data []byte) (*Address, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("Empty address")
+	}
+	header := data[0]
+	addrType := Type(header >> 4)
+	if addrType == TypeByron {
+		// Byron addresses are CBOR arrays, starting with 0x82
+		return decodeByron(data)
+	}
+	network := uint32(header & typeHeaderNetworkMask)
+	result := &Address{
+		Type:    addrType,
+		Network: &network,
+		raw:     append([]byte{}, data...),
+	}
+	payload := data[1:]
+
+	switch addrType {
+	case TypeBase, TypeBaseScriptKey, TypeBaseKeyScript, TypeBaseScriptScript:
+		if len(payload) != 2*HashSize {
+			return nil, fmt.Errorf("Unexpected length %v of base address", len(data))
+		}
+		result.Payment = &Credential{Kind: KeyHash, Hash: payload[:HashSize]}
+		result.Stake = &Credential{Kind: KeyHash, Hash: payload[HashSize:]}
+		if addrType == TypeBaseScriptKey || addrType == TypeBaseScriptScript {
+			result.Payment.Kind = ScriptHash
+		}
+		if addrType == TypeBaseKeyScript || addrType == TypeBaseScriptScript {
+			result.Stake.Kind = ScriptHash
+		}
+	case TypePointer, TypePointerScript:
+		if len(payload) < HashSize+3 {
+			return nil, fmt.Errorf("Unexpected length %v of pointer address", len(data))
+		}
+		result.Payment = &Credential{Kind: KeyHash, Hash: payload[:HashSize]}
+		if addrType == TypePointerScript {
+			result.Payment.Kind = ScriptHash
+		}
+		pointer, err := decodePointer(payload[HashSize:])
+		if err != nil {
+			return nil, err
+		}
+		result.Pointer = pointer
+	case TypeEnterprise, TypeEnterpriseScript:
+		if len(payload) != HashSize {
+			return nil, fmt.Errorf("Unexpected length %v of enterprise address", len(data))
+		}
+		result.Payment = &Credential{Kind: KeyHash, Hash: payload}
+		if addrType == TypeEnterpriseScript {
+			result.Payment.Kind = ScriptHash
+		}
+	case TypeReward, TypeRewardScript:
+		if len(payload) != HashSize {
+			return nil, fmt.Errorf("Unexpected length %v of reward address", len(data))
+		}
+		result.Stake = &Credential{Kind: KeyHash, Hash: payload}
+		if addrType == TypeRewardScript {
+			result.Stake.Kind = ScriptHash
+		}
+	default:
+		return nil, fmt.Errorf("Unknown address type %v", addrType)
+	}
+	return result, nil
+}
+
+// decodePointer decodes three variable-length natural numbers (7 bits per byte, big-endian).
+func decodePointer(data []byte) (*Pointer, error) {
+	var values [3]uint64
+	for i := range values {
+		var val uint64
+		for {
+			if len(data) == 0 {
+				return nil, fmt.Errorf("Truncated stake pointer")
+			}
+			b := data[0]
+			data = data[1:]
+			if val > (1<<64-1)>>7 {
+				return nil, fmt.Errorf("Stake pointer value too large")
+			}
+			val = val<<7 | uint64(b&0x7f)
+			if b&0x80 == 0 {
+				break
+			}
+		}
+		values[i] = val
+	}
+	if len(data) > 0 {
+		return nil, fmt.Errorf("Unexpected %v bytes after stake pointer", len(data))
+	}
+	return &Pointer{Slot: values[0], TxIndex: values[1], OutputIndex: values[2]}, nil
+}
+
+func encodePointerValue(val uint64) []byte {
+	result := []byte{byte(val & 0x7f)}
+	for val >>= 7; val > 0; val >>= 7 {
+		result = append([]byte{byte(val&0x7f) | 0x80}, result...)
+	}
+	return result
+}
+
+// Bytes returns the raw binary representation of the address.
+func (a *Address) Bytes() []byte {
+	if a.raw == nil {
+		a.raw = a.encode()
+	}
+	return append([]byte{}, a.raw...)
+}
+
+func (a *Address) encode() []byte {
+	network := byte(0)
+	if a.Network != nil {
+		network = byte(*a.Network) & typeHeaderNetworkMask
+	}
+	result := []byte{byte(a.Type)<<4 | network}
+	if a.Payment != nil {
+		result = append(result, a.Payment.Hash...)
+	}
+	if a.Pointer != nil {
+		result = append(result, encodePointerValue(a.Pointer.Slot)...)
+		result = append(result, encodePointerValue(a.Pointer.TxIndex)...)
+		result = append(result, encodePointerValue(a.Pointer.OutputIndex)...)
+	}
+	if a.Stake != nil {
+		result = append(result, a.Stake.Hash...)
+	}
+	return result
+}
+
+func (a *Address) hrp() string {
+	prefix := "addr"
+	if a.Type == TypeReward || a.Type == TypeRewardScript {
+		prefix = "stake"
+	}
+	if a.Network == nil || *a.Network != NetworkMainnet {
+		prefix += "_test"
+	}
+	return prefix
+}
+
+// String returns the Bech32 encoding of Shelley addresses, or the Base58 encoding of Byron addresses.
+func (a *Address) String() string {
+	if a.Type == TypeByron {
+		return encodeBase58(a.Bytes())
+	}
+	encoded, err := bech32.Encode(a.hrp(), a.Bytes())
+	if err != nil {
+		// Addresses are far below the maximum length of Bech32 strings
+		panic(err)
+	}
+	return encoded
+}
+
+// IsShelley returns true for all addresses except Byron addresses.
+func (a *Address) IsShelley() bool {
+	return a.Type != TypeByron
+}
+
+// IsMainnet returns true if the address belongs to the Cardano mainnet.
+func (a *Address) IsMainnet() bool {
+	if a.Type == TypeByron {
+		return a.Network == nil
+	}
+	return a.Network != nil && *a.Network == NetworkMainnet
+}
+
+func network(id uint32) *uint32 {
+	return &id
+}
+
+func validateCredential(name string, cred *Credential) error {
+	if cred == nil {
+		return fmt.Errorf("Missing %v credential", name)
+	}
+	if len(cred.Hash) != HashSize {
+		return fmt.Errorf("Unexpected %v credential hash length %v, expected %v", name, len(cred.Hash), HashSize)
+	}
+	return nil
+}
+
+// NewBaseAddress returns a base address with the given payment and stake credentials.
+func NewBaseAddress(networkId uint32, payment, stake *Credential) (*Address, error) {
+	if err := validateCredential("payment", payment); err != nil {
+		return nil, err
+	}
+	if err := validateCredential("stake", stake); err != nil {
+		return nil, err
+	}
+	addrType := TypeBase
+	if payment.Kind == ScriptHash {
+		addrType |= 1
+	}
+	if stake.Kind == ScriptHash {
+		addrType |= 2
+	}
+	return &Address{Type: addrType, Network: network(networkId), Payment: payment, Stake: stake}, nil
+}
+
+// NewEnterpriseAddress returns an enterprise address, which has a payment credential but no stake credential.
+func NewEnterpriseAddress(networkId uint32, payment *Credential) (*Address, error) {
+	if err := validateCredential("payment", payment); err != nil {
+		return nil, err
+	}
+	addrType := TypeEnterprise
+	if payment.Kind == ScriptHash {
+		addrType = TypeEnterpriseScript
+	}
+	return &Address{Type: addrType, Network: network(networkId), Payment: payment}, nil
+}
+
+// NewPointerAddress returns a pointer address with the given payment credential and stake pointer.
+func NewPointerAddress(networkId uint32, payment *Credential, pointer *Pointer) (*Address, error) {
+	if err := validateCredential("payment", payment); err != nil {
+		return nil, err
+	}
+	if pointer == nil {
+		return nil, fmt.Errorf("Missing stake pointer")
+	}
+	addrType := TypePointer
+	if payment.Kind == ScriptHash {
+		addrType = TypePointerScript
+	}
+	return &Address{Type: addrType, Network: network(networkId), Payment: payment, Pointer: pointer}, nil
+}
+
+// NewRewardAddress returns a reward (stake) address for the given stake credential.
+func NewRewardAddress(networkId uint32, stake *Credential) (*Address, error) {
+	if err := validateCredential("stake", stake); err != nil {
+		return nil, err
+	}
+	addrType := TypeReward
+	if stake.Kind == ScriptHash {
+		addrType = TypeRewardScript
+	}
+	return &Address{Type: addrType, Network: network(networkId), Stake: stake}, nil
+}
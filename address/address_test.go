@@ -0,0 +1,162 @@
+package address
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/godano/cardano-wallet-client/bech32"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type AddressTestSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestAddress(t *testing.T) {
+	testSuite := new(AddressTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *AddressTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+}
+
+const (
+	testShelleyAddress = "addr1qx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgse35a3x"
+	testPaymentHash    = "9493315cd92eb5d8c4304e67b7e16ae36d61d34502694657811a2c8e"
+	testStakeHash      = "337b62cfff6403a06a3acbc34f8c46003c69fe79a3628cefa9c47251"
+	testIcarusAddress  = "Ae2tdPwUPEZFRbyhz3cpfC2CumGzNkFBN2L42rcUc2yjQpEkxDbkPodpMAi"
+	testByronAddress   = "37btjrVyb4KDXBNC4haBVPCrro8AQPHwvCMp3RFhhSVWwfFmZ6wwzSK6JK1hY6wHNmtrpTf1kdbva8TCneM2YsiXT7mrzT21EacHnPpz5YyUdj64na"
+)
+
+func (s *AddressTestSuite) hash(hexHash string) []byte {
+	decoded, err := hex.DecodeString(hexHash)
+	s.NoError(err)
+	return decoded
+}
+
+func (s *AddressTestSuite) TestShelleyBaseAddress() {
+	addr, err := Decode(testShelleyAddress)
+	s.NoError(err)
+	s.Equal(TypeBase, addr.Type)
+	s.True(addr.IsMainnet())
+	s.Equal(testPaymentHash, hex.EncodeToString(addr.Payment.Hash))
+	s.Equal(testStakeHash, hex.EncodeToString(addr.Stake.Hash))
+	s.Equal(testShelleyAddress, addr.String())
+
+	inspection, err := addr.Inspect()
+	s.NoError(err)
+	s.Equal(AddressStyleShelley, inspection.AddressStyle)
+	s.Equal(StakeReferenceByValue, inspection.StakeReference)
+	s.Equal(1, *inspection.NetworkTag)
+	s.Equal(testPaymentHash, *inspection.SpendingKeyHash)
+	s.Equal(testStakeHash, *inspection.StakeKeyHash)
+	s.Contains(*inspection.SpendingKeyBech32, SpendingKeyHashPrefix+"1")
+	s.Nil(inspection.AddressRoot)
+}
+
+func (s *AddressTestSuite) TestConstructors() {
+	payment := &Credential{Kind: ScriptHash, Hash: s.hash(testPaymentHash)}
+	stake := &Credential{Kind: KeyHash, Hash: s.hash(testStakeHash)}
+
+	base, err := NewBaseAddress(NetworkTestnet, payment, stake)
+	s.NoError(err)
+	s.Equal(TypeBaseScriptKey, base.Type)
+	s.Contains(base.String(), "addr_test1")
+
+	enterprise, err := NewEnterpriseAddress(NetworkMainnet, stake)
+	s.NoError(err)
+	pointer, err := NewPointerAddress(NetworkTestnet, stake, &Pointer{Slot: 2498243, TxIndex: 27, OutputIndex: 3})
+	s.NoError(err)
+	reward, err := NewRewardAddress(NetworkMainnet, stake)
+	s.NoError(err)
+	s.Contains(reward.String(), "stake1")
+
+	for _, addr := range []*Address{base, enterprise, pointer, reward} {
+		decoded, err := Decode(addr.String())
+		s.NoError(err)
+		s.Equal(addr.Bytes(), decoded.Bytes())
+		s.Equal(addr.Type, decoded.Type)
+		s.Equal(addr.Payment, decoded.Payment)
+		s.Equal(addr.Stake, decoded.Stake)
+		s.Equal(addr.Pointer, decoded.Pointer)
+	}
+
+	inspection, err := Inspect(pointer.String())
+	s.NoError(err)
+	s.Equal(StakeReferenceByPointer, inspection.StakeReference)
+	s.Equal(2498243, inspection.Pointer.SlotNum)
+	s.Equal(27, inspection.Pointer.TransactionIndex)
+	s.Equal(3, inspection.Pointer.OutputIndex)
+
+	inspection, err = Inspect(base.String())
+	s.NoError(err)
+	s.Equal(testPaymentHash, *inspection.ScriptHash)
+	s.Contains(*inspection.ScriptHashBech32, ScriptHashPrefix+"1")
+	s.Nil(inspection.SpendingKeyHash)
+
+	_, err = NewEnterpriseAddress(NetworkMainnet, &Credential{Hash: []byte{1, 2, 3}})
+	s.Error(err)
+}
+
+func (s *AddressTestSuite) TestIcarusAddress() {
+	addr, err := Decode(testIcarusAddress)
+	s.NoError(err)
+	s.Equal(TypeByron, addr.Type)
+	s.False(addr.IsShelley())
+	s.True(addr.IsMainnet())
+	s.Equal(AddressStyleIcarus, addr.Byron.Style())
+	s.Equal(testIcarusAddress, addr.String())
+
+	inspection, err := addr.Inspect()
+	s.NoError(err)
+	s.Equal(AddressStyleIcarus, inspection.AddressStyle)
+	s.Equal(StakeReferenceNone, inspection.StakeReference)
+	s.NotNil(inspection.AddressRoot)
+	s.Nil(inspection.NetworkTag)
+	s.Nil(inspection.DerivationPath)
+}
+
+func (s *AddressTestSuite) TestByronAddress() {
+	addr, err := Decode(testByronAddress)
+	s.NoError(err)
+	s.Equal(AddressStyleByron, addr.Byron.Style())
+	s.NotEmpty(addr.Byron.DerivationPath)
+	s.Equal(testByronAddress, addr.String())
+
+	inspection, err := addr.Inspect()
+	s.NoError(err)
+	s.Equal(hex.EncodeToString(addr.Byron.DerivationPath), *inspection.DerivationPath)
+}
+
+func (s *AddressTestSuite) TestInvalidAddresses() {
+	for _, invalid := range []string{
+		"",
+		testShelleyAddress[:len(testShelleyAddress)-1] + "y", // Bech32 checksum
+		testIcarusAddress[:len(testIcarusAddress)-1] + "j",   // CRC
+		"addr1" + "0OIl", // Bech32 charset
+		"0OIl",           // Base58 alphabet
+	} {
+		_, err := Decode(invalid)
+		s.Error(err, invalid)
+	}
+
+	// Reward address with addr prefix
+	reward, err := NewRewardAddress(NetworkMainnet, &Credential{Hash: s.hash(testStakeHash)})
+	s.NoError(err)
+	wrongPrefix, err := bech32.Encode("addr", reward.Bytes())
+	s.NoError(err)
+	_, err = Decode(wrongPrefix)
+	s.Error(err)
+}
+
+func (s *AddressTestSuite) TestBase58() {
+	for _, data := range [][]byte{{}, {0}, {0, 0, 1}, {0xff, 0xee, 0x00}} {
+		decoded, err := decodeBase58(encodeBase58(data))
+		s.NoError(err)
+		s.Equal(data, decoded)
+	}
+	s.Equal("StV1DL6CwTryKyV", encodeBase58([]byte("hello world")))
+}
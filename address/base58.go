@@ -0,0 +1,50 @@
+package address
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var bigRadix = big.NewInt(58)
+
+// encodeBase58 encodes the given bytes with the Bitcoin alphabet, as used for Byron addresses.
+func encodeBase58(data []byte) string {
+	num := new(big.Int).SetBytes(data)
+	mod := new(big.Int)
+	var result []byte
+	for num.Sign() > 0 {
+		num.DivMod(num, bigRadix, mod)
+		result = append(result, base58Alphabet[mod.Int64()])
+	}
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		result = append(result, base58Alphabet[0])
+	}
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return string(result)
+}
+
+func decodeBase58(s string) ([]byte, error) {
+	num := new(big.Int)
+	for _, c := range s {
+		digit := strings.IndexRune(base58Alphabet, c)
+		if digit < 0 {
+			return nil, fmt.Errorf("Invalid Base58 character: %q", c)
+		}
+		num.Mul(num, bigRadix)
+		num.Add(num, big.NewInt(int64(digit)))
+	}
+	decoded := num.Bytes()
+	leadingZeros := 0
+	for leadingZeros < len(s) && s[leadingZeros] == base58Alphabet[0] {
+		leadingZeros++
+	}
+	return append(make([]byte, leadingZeros), decoded...), nil
+}
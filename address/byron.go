@@ -0,0 +1,146 @@
+package address
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/godano/cardano-wallet-client/internal/cbor"
+)
+
+const (
+	byronAttributeDerivationPath = 1
+	byronAttributeProtocolMagic  = 2
+	byronPayloadTag              = 24
+	byronRootSize                = 28
+	byronAddressStyleByron       = "Byron"
+	byronAddressStyleIcarus      = "Icarus"
+)
+
+// ByronAddress contains the fields of a decoded Byron address.
+type ByronAddress struct {
+	// Root is the hash of the address spending data and attributes.
+	Root []byte
+
+	// DerivationPath is the encrypted derivation path of random (Byron style) wallets.
+	// It is nil for sequential (Icarus style) wallets.
+	DerivationPath []byte
+
+	// ProtocolMagic identifies the network. It is nil for mainnet addresses.
+	ProtocolMagic *uint32
+
+	// AddressType is 0 for public key addresses and 2 for redemption addresses.
+	AddressType uint64
+}
+
+// Style returns "Byron" for addresses of random wallets, and "Icarus" for addresses of sequential wallets.
+func (b *ByronAddress) Style() string {
+	if b.DerivationPath != nil {
+		return byronAddressStyleByron
+	}
+	return byronAddressStyleIcarus
+}
+
+// decodeByron decodes the CBOR structure [tag24(bytes(payload)), crc32], where the payload is [root, attributes, type].
+func decodeByron(data []byte) (*Address, error) {
+	decoded, err := cbor.DecodeAll(data)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid Byron address: %v", err)
+	}
+	outer, ok := decoded.([]interface{})
+	if !ok || len(outer) != 2 {
+		return nil, fmt.Errorf("Invalid Byron address: expected array of 2 elements")
+	}
+	tag, ok := outer[0].(cbor.Tag)
+	if !ok || tag.Number != byronPayloadTag {
+		return nil, fmt.Errorf("Invalid Byron address: expected payload with tag %v", byronPayloadTag)
+	}
+	payload, ok := tag.Content.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("Invalid Byron address: payload is not a bytestring")
+	}
+	checksum, ok := outer[1].(uint64)
+	if !ok {
+		return nil, fmt.Errorf("Invalid Byron address: missing checksum")
+	}
+	if actual := crc32.ChecksumIEEE(payload); uint64(actual) != checksum {
+		return nil, fmt.Errorf("Invalid Byron address: checksum mismatch (expected %08x, got %08x)", checksum, actual)
+	}
+
+	decodedPayload, err := cbor.DecodeAll(payload)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid Byron address payload: %v", err)
+	}
+	fields, ok := decodedPayload.([]interface{})
+	if !ok || len(fields) != 3 {
+		return nil, fmt.Errorf("Invalid Byron address payload: expected array of 3 elements")
+	}
+	root, ok := fields[0].([]byte)
+	if !ok || len(root) != byronRootSize {
+		return nil, fmt.Errorf("Invalid Byron address root")
+	}
+	attributes, ok := fields[1].(cbor.Map)
+	if !ok {
+		return nil, fmt.Errorf("Invalid Byron address attributes")
+	}
+	addrType, ok := fields[2].(uint64)
+	if !ok {
+		return nil, fmt.Errorf("Invalid Byron address type")
+	}
+
+	byron := &ByronAddress{Root: root, AddressType: addrType}
+	if rawPath, ok := attributes.Get(byronAttributeDerivationPath); ok {
+		// The attribute value is a CBOR-encoded bytestring
+		path, err := decodeByronAttribute(rawPath)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid Byron derivation path: %v", err)
+		}
+		pathBytes, ok := path.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("Invalid Byron derivation path: expected bytestring")
+		}
+		byron.DerivationPath = pathBytes
+	}
+	if rawMagic, ok := attributes.Get(byronAttributeProtocolMagic); ok {
+		magic, err := decodeByronAttribute(rawMagic)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid Byron protocol magic: %v", err)
+		}
+		magicVal, ok := magic.(uint64)
+		if !ok || magicVal > 1<<32-1 {
+			return nil, fmt.Errorf("Invalid Byron protocol magic: %v", magic)
+		}
+		byron.ProtocolMagic = network(uint32(magicVal))
+	}
+
+	return &Address{
+		Type:    TypeByron,
+		Network: byron.ProtocolMagic,
+		Byron:   byron,
+		raw:     append([]byte{}, data...),
+	}, nil
+}
+
+// decodeByronAttribute decodes an attribute value, which is stored as CBOR inside a bytestring.
+func decodeByronAttribute(raw interface{}) (interface{}, error) {
+	encoded, ok := raw.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("expected bytestring")
+	}
+	return cbor.DecodeAll(encoded)
+}
+
+func (b *ByronAddress) inspect(result *Inspection) {
+	root := hex.EncodeToString(b.Root)
+	result.AddressRoot = &root
+	result.AddressStyle = b.Style()
+	if b.DerivationPath != nil {
+		path := hex.EncodeToString(b.DerivationPath)
+		result.DerivationPath = &path
+	}
+	if b.ProtocolMagic != nil {
+		tag := int(*b.ProtocolMagic)
+		result.NetworkTag = &tag
+	}
+	result.StakeReference = StakeReferenceNone
+}
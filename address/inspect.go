@@ -0,0 +1,133 @@
+package address
+
+import (
+	"encoding/hex"
+
+	"github.com/godano/cardano-wallet-client/bech32"
+)
+
+// Values of Inspection.StakeReference
+const (
+	StakeReferenceNone      = "none"
+	StakeReferenceByValue   = "by value"
+	StakeReferenceByPointer = "by pointer"
+)
+
+// Values of Inspection.AddressStyle. Byron addresses have the style "Byron" or "Icarus", see ByronAddress.Style().
+const (
+	AddressStyleShelley = "Shelley"
+	AddressStyleByron   = byronAddressStyleByron
+	AddressStyleIcarus  = byronAddressStyleIcarus
+)
+
+// Bech32 prefixes of the hashes in Inspection
+const (
+	SpendingKeyHashPrefix = "addr_vkh"
+	StakeKeyHashPrefix    = "stake_vkh"
+	ScriptHashPrefix      = "script"
+)
+
+// Inspection has the same fields and JSON representation as the result of the InspectAddress operation
+// of the cardano-wallet. The field types are identical to InspectAddressResponse.JSON200, so an Inspection
+// can be assigned to *resp.JSON200 or converted to its type directly.
+type Inspection struct {
+
+	// Only for 'Icarus' and 'Byron' styles.
+	AddressRoot  *string `json:"address_root,omitempty"`
+	AddressStyle string  `json:"address_style"`
+
+	// Only for 'Byron' style.
+	DerivationPath *string `json:"derivation_path,omitempty"`
+
+	// Can be null for 'Icarus' and 'Byron' styles.
+	NetworkTag *int `json:"network_tag,omitempty"`
+	Pointer    *struct {
+		OutputIndex      int `json:"output_index"`
+		SlotNum          int `json:"slot_num"`
+		TransactionIndex int `json:"transaction_index"`
+	} `json:"pointer,omitempty"`
+	ScriptHash        *string `json:"script_hash,omitempty"`
+	ScriptHashBech32  *string `json:"script_hash_bech32,omitempty"`
+	SpendingKeyBech32 *string `json:"spending_key_bech32,omitempty"`
+	SpendingKeyHash   *string `json:"spending_key_hash,omitempty"`
+	StakeKeyBech32    *string `json:"stake_key_bech32,omitempty"`
+	StakeKeyHash      *string `json:"stake_key_hash,omitempty"`
+	StakeReference    string  `json:"stake_reference"`
+}
+
+// Inspect decodes the given address string and returns its structure.
+func Inspect(addr string) (*Inspection, error) {
+	decoded, err := Decode(addr)
+	if err != nil {
+		return nil, err
+	}
+	return decoded.Inspect()
+}
+
+// Inspect returns the structure of the address in the format of the InspectAddress operation.
+// The cardano-wallet only reports a single script hash: the payment script hash if present, otherwise the stake script hash.
+func (a *Address) Inspect() (*Inspection, error) {
+	result := new(Inspection)
+	if a.Type == TypeByron {
+		a.Byron.inspect(result)
+		return result, nil
+	}
+
+	result.AddressStyle = AddressStyleShelley
+	if a.Network != nil {
+		tag := int(*a.Network)
+		result.NetworkTag = &tag
+	}
+	if a.Payment != nil {
+		if err := result.setCredential(a.Payment, true); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case a.Stake != nil:
+		result.StakeReference = StakeReferenceByValue
+		if err := result.setCredential(a.Stake, false); err != nil {
+			return nil, err
+		}
+	case a.Pointer != nil:
+		result.StakeReference = StakeReferenceByPointer
+		result.Pointer = &struct {
+			OutputIndex      int `json:"output_index"`
+			SlotNum          int `json:"slot_num"`
+			TransactionIndex int `json:"transaction_index"`
+		}{
+			OutputIndex:      int(a.Pointer.OutputIndex),
+			SlotNum:          int(a.Pointer.Slot),
+			TransactionIndex: int(a.Pointer.TxIndex),
+		}
+	default:
+		result.StakeReference = StakeReferenceNone
+	}
+	return result, nil
+}
+
+func (i *Inspection) setCredential(cred *Credential, isPayment bool) error {
+	hash := hex.EncodeToString(cred.Hash)
+	prefix := StakeKeyHashPrefix
+	if cred.Kind == ScriptHash {
+		if i.ScriptHash != nil {
+			return nil
+		}
+		prefix = ScriptHashPrefix
+	} else if isPayment {
+		prefix = SpendingKeyHashPrefix
+	}
+	encoded, err := bech32.Encode(prefix, cred.Hash)
+	if err != nil {
+		return err
+	}
+	switch {
+	case cred.Kind == ScriptHash:
+		i.ScriptHash, i.ScriptHashBech32 = &hash, &encoded
+	case isPayment:
+		i.SpendingKeyHash, i.SpendingKeyBech32 = &hash, &encoded
+	default:
+		i.StakeKeyHash, i.StakeKeyBech32 = &hash, &encoded
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// Package bech32 implements the Bech32 encoding defined in BIP-173, as used by Cardano for addresses and keys.
+// Unlike BIP-173, the overall length of encoded strings is not limited to 90 characters,
+// since Cardano addresses and extended keys exceed this limit.
+package bech32
+
+import (
+	"fmt"
+	"strings"
+)
+
+const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// MaxLength is the maximum length of an encoded string accepted by Decode.
+const MaxLength = 1023
+
+var charsetRev = func() [128]int8 {
+	var rev [128]int8
+	for i := range rev {
+		rev[i] = -1
+	}
+	for i, c := range charset {
+		rev[c] = int8(i)
+	}
+	return rev
+}()
+
+// Decode decodes the given Bech32 string into its human-readable part and its data bytes.
+func Decode(s string) (string, []byte, error) {
+	if len(s) > MaxLength {
+		return "", nil, fmt.Errorf("Bech32 string too long (%v characters)", len(s))
+	}
+	lower := strings.ToLower(s)
+	if lower != s && strings.ToUpper(s) != s {
+		return "", nil, fmt.Errorf("Bech32 string must not use mixed case: %v", s)
+	}
+	sep := strings.LastIndexByte(lower, '1')
+	if sep < 1 || sep+7 > len(lower) {
+		return "", nil, fmt.Errorf("Invalid Bech32 separator position in: %v", s)
+	}
+	hrp := lower[:sep]
+	for _, c := range hrp {
+		if c < 33 || c > 126 {
+			return "", nil, fmt.Errorf("Invalid character in Bech32 prefix: %q", c)
+		}
+	}
+
+	values := make([]byte, 0, len(lower)-sep-1)
+	for _, c := range lower[sep+1:] {
+		if c >= 128 || charsetRev[c] < 0 {
+			return "", nil, fmt.Errorf("Invalid Bech32 character: %q", c)
+		}
+		values = append(values, byte(charsetRev[c]))
+	}
+	if polymod(append(expandHRP(hrp), values...)) != 1 {
+		return "", nil, fmt.Errorf("Invalid Bech32 checksum in: %v", s)
+	}
+
+	data, err := ConvertBits(values[:len(values)-6], 5, 8, false)
+	if err != nil {
+		return "", nil, err
+	}
+	return hrp, data, nil
+}
+
+// Encode encodes the given data bytes with the given human-readable part.
+func Encode(hrp string, data []byte) (string, error) {
+	if hrp == "" {
+		return "", fmt.Errorf("Bech32 prefix must not be empty")
+	}
+	hrp = strings.ToLower(hrp)
+	values, err := ConvertBits(data, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	checksum := createChecksum(hrp, values)
+
+	var builder strings.Builder
+	builder.Grow(len(hrp) + 1 + len(values) + len(checksum))
+	builder.WriteString(hrp)
+	builder.WriteByte('1')
+	for _, v := range append(values, checksum...) {
+		builder.WriteByte(charset[v])
+	}
+	if builder.Len() > MaxLength {
+		return "", fmt.Errorf("Bech32 string too long (%v characters)", builder.Len())
+	}
+	return builder.String(), nil
+}
+
+// ConvertBits regroups the given values of fromBits bits each into values of toBits bits each.
+func ConvertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	var acc, bits uint
+	maxVal := uint(1)<<toBits - 1
+	result := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+	for _, value := range data {
+		if uint(value)>>fromBits != 0 {
+			return nil, fmt.Errorf("Invalid data value for bit conversion: %v", value)
+		}
+		acc = acc<<fromBits | uint(value)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			result = append(result, byte(acc>>bits&maxVal))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			result = append(result, byte(acc<<(toBits-bits)&maxVal))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxVal != 0 {
+		return nil, fmt.Errorf("Invalid padding in Bech32 data")
+	}
+	return result, nil
+}
+
+func polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func expandHRP(hrp string) []byte {
+	result := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		result = append(result, hrp[i]>>5)
+	}
+	result = append(result, 0)
+	for i := 0; i < len(hrp); i++ {
+		result = append(result, hrp[i]&31)
+	}
+	return result
+}
+
+func createChecksum(hrp string, values []byte) []byte {
+	input := append(expandHRP(hrp), values...)
+	input = append(input, 0, 0, 0, 0, 0, 0)
+	mod := polymod(input) ^ 1
+	checksum := make([]byte, 6)
+	for i := range checksum {
+		checksum[i] = byte(mod >> uint(5*(5-i)) & 31)
+	}
+	return checksum
+}
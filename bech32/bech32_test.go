@@ -0,0 +1,66 @@
+package bech32
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type Bech32TestSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestBech32(t *testing.T) {
+	testSuite := new(Bech32TestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *Bech32TestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+}
+
+// Valid checksums from BIP-173
+func (s *Bech32TestSuite) TestValidChecksums() {
+	for _, valid := range []string{
+		"A12UEL5L",
+		"a12uel5l",
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+		"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w",
+		"?1ezyfcl",
+	} {
+		_, _, err := Decode(valid)
+		s.NoError(err, valid)
+	}
+}
+
+func (s *Bech32TestSuite) TestInvalid() {
+	for _, invalid := range []string{
+		"pzry9x0s0muk",    // No separator
+		"1pzry9x0s0muk",   // Empty prefix
+		"x1b4n0q5v",       // Invalid character
+		"li1dgmt3",        // Too short checksum
+		"A1G7SGD8",        // Checksum calculated with uppercase prefix
+		"a12UEL5L",        // Mixed case
+		"abcdef1qpzry9x8", // Invalid checksum
+	} {
+		_, _, err := Decode(invalid)
+		s.Error(err, invalid)
+	}
+}
+
+func (s *Bech32TestSuite) TestRoundTrip() {
+	data := make([]byte, 57) // Length of a Shelley base address
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	encoded, err := Encode("addr_test", data)
+	s.NoError(err)
+	s.True(len(encoded) > 90)
+
+	hrp, decoded, err := Decode(encoded)
+	s.NoError(err)
+	s.Equal("addr_test", hrp)
+	s.Equal(data, decoded)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+
+	"github.com/godano/cardano-wallet-client/wallet"
+	"github.com/spf13/cobra"
+)
+
+// auditCaller identifies the user of the CLI in the audit log as user@host.
+func auditCaller() string {
+	name := "unknown"
+	if current, err := user.Current(); err == nil {
+		name = current.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		name += "@" + host
+	}
+	return name
+}
+
+func (c *walletCLI) auditLogVerifyCommand() *cobra.Command {
+	var (
+		expectSeq  uint64
+		expectHash string
+	)
+	cmd := &cobra.Command{
+		Use:   "verify [file]",
+		Short: "Verify the hash chain of an audit log",
+		Long: `Verify that no records of an audit log, as written with --audit-log, were modified,
+inserted or removed. The output contains the last_seq and last_hash of the log. Store them
+outside of the log, and pass them as --expect-seq and --expect-hash to the next verification:
+it fails, if the record is missing or differs, so records removed at the end of the log are
+detected as well.
+The file defaults to --audit-log or the audit_log of the profile. Logs written with a key
+must be verified with the same --audit-log-key-from. Without a key, anybody who can write
+the file can recompute all hashes, which is only detected by --expect-seq and --expect-hash.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			file := c.auditLog
+			if len(args) > 0 {
+				file = args[0]
+			}
+			if file == "" {
+				c.checkErr(fmt.Errorf("No audit log given, use an argument, --audit-log or the audit_log of the profile"))
+			}
+			var anchor *wallet.AuditLogAnchor
+			if cmd.Flags().Changed("expect-seq") || cmd.Flags().Changed("expect-hash") {
+				if expectSeq == 0 || expectHash == "" {
+					c.checkErr(fmt.Errorf("--expect-seq and --expect-hash must be given together"))
+				}
+				anchor = &wallet.AuditLogAnchor{Seq: expectSeq, Hash: expectHash}
+			}
+			key, err := wallet.ReadAuditLogKey(c.ctx, c.auditLogKeyFrom)
+			c.checkErr(err)
+			summary, err := wallet.VerifyAuditLog(file, key, anchor)
+			c.checkErr(err)
+			c.log.Infof("Audit log %v is valid", file)
+			c.outputObject(map[string]interface{}{
+				"records":   summary.Records,
+				"last_seq":  summary.LastSeq,
+				"last_hash": summary.LastHash,
+			})
+		},
+	}
+	flags := cmd.Flags()
+	flags.Uint64Var(&expectSeq, "expect-seq", 0, "Sequence number of a previously verified record, which must be unchanged")
+	flags.StringVar(&expectHash, "expect-hash", "", "Hash of the record given by --expect-seq")
+	return cmd
+}
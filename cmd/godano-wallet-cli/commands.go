@@ -21,6 +21,9 @@ import (
 
 var dryRunErr = errors.New("Request dry-run")
 
+// walletIdArg is the name of the argument that can be omitted, if the profile defines a default wallet.
+const walletIdArg = "walletId"
+
 func (c *walletCLI) initRootCommand() {
 	c.rootCmd = &cobra.Command{
 		Use:   "godano-wallet-cli object operation",
@@ -30,13 +33,25 @@ translates the CLI commands and parameters to appropriate REST API calls`,
 	}
 
 	flags := c.rootCmd.PersistentFlags()
-	flags.StringVarP(&c.serverAddress, "server", "s", c.serverAddress, "Endpoint of the cardano-wallet process to connect to")
+	flags.StringVarP(&c.serverAddress, "server", "s", c.serverAddress,
+		"Endpoint of the cardano-wallet process, an http://, https:// or unix:// URL (default from the profile or "+wallet.EnvVarWalletServerAddress+")")
+	flags.StringVar(&c.configFile, "config", c.configFile, "Profiles file (default "+wallet.EnvConfigFile+" or ~/.config/"+wallet.ConfigFileName+")")
+	flags.StringVar(&c.daedalus, "daedalus", c.daedalus, fmt.Sprintf("Connect to the cardano-wallet started by Daedalus for the given network, one of %v", wallet.DaedalusNetworks))
+	flags.StringVar(&c.profileName, "profile", c.profileName, "Profile to use from the profiles file (default "+wallet.EnvProfile+" or the default_profile of the file)")
 	flags.BoolVarP(&c.logQuiet, "quiet", "q", c.logQuiet, "Set the log level to Warning")
 	flags.BoolVarP(&c.logVeryQuiet, "quieter", "Q", c.logVeryQuiet, "Set the log level to Error")
 	flags.BoolVarP(&c.logVerbose, "verbose", "v", c.logVerbose, "Set the log level to Debug")
 	flags.BoolVarP(&c.logTrace, "trace", "V", c.logTrace, "Set the log level to Trace")
 	flags.BoolVarP(&c.dryRun, "dry-run", "n", c.dryRun, "Show the resulting request instead of executing it")
 	flags.BoolVarP(&c.outputYAML, "yaml", "y", c.outputYAML, "Output responses as YAML instead of JSON (more compact)")
+	flags.BoolVar(&c.networkGuard, "network-guard", c.networkGuard, "Reject transactions to addresses of a different network than the server's")
+	flags.StringVar(&c.passphraseFrom, "passphrase-from", c.passphraseFrom,
+		"Insert the wallet passphrase into request bodies, read from: prompt, fd:<number>, file:<path>, env:<variable> or cmd:<command>")
+	flags.StringVar(&c.newPassphraseFrom, "new-passphrase-from", c.newPassphraseFrom,
+		"Like --passphrase-from, for the new passphrase of the WalletPassphrase put, Wallet create and Wallet restore commands")
+	flags.StringVar(&c.auditLog, "audit-log", c.auditLog, "Record all mutating operations in this hash-chained audit log (default audit_log of the profile)")
+	flags.StringVar(&c.auditLogKeyFrom, "audit-log-key-from", c.auditLogKeyFrom,
+		"Chain the audit log with an HMAC key, read like --passphrase-from (default audit_log_key_from of the profile)")
 }
 
 func (c *walletCLI) initByronCommand() {
@@ -50,15 +65,15 @@ All Byron-era commands have equivalents in the main command.`,
 	c.rootCmd.AddCommand(c.byronCmd)
 }
 
-func (c *walletCLI) objectCommand(m *methodCommand, allObjectVerbs []string) *cobra.Command {
+func (c *walletCLI) objectCommand(object string, isByron bool, allObjectVerbs []string) *cobra.Command {
 	objectCommands := c.objectCommands
 	parentCmd := c.rootCmd
-	if m.method.isByronMethod {
+	if isByron {
 		objectCommands = c.byronObjectCommands
 		parentCmd = c.byronCmd
 	}
 
-	objectCommand, ok := objectCommands[m.method.object]
+	objectCommand, ok := objectCommands[object]
 	if !ok {
 		// These messages cover the case that there are multiple sub-commands for this object
 		var joinedVerbs string
@@ -70,21 +85,28 @@ func (c *walletCLI) objectCommand(m *methodCommand, allObjectVerbs []string) *co
 			joinedVerbs = strings.Join(allObjectVerbs[:len(allObjectVerbs)-1], ", ") + ", or " + allObjectVerbs[len(allObjectVerbs)-1]
 		}
 
-		shortMessage := fmt.Sprintf("%v %v objects", joinedVerbs, m.objectStr())
+		shortMessage := fmt.Sprintf("%v %v objects", joinedVerbs, objectStr(object, isByron))
 		longMessage := shortMessage
 
 		objectCommand = &cobra.Command{
-			Use:     m.method.object,
+			Use:     object,
 			Short:   shortMessage,
 			Long:    longMessage,
-			Aliases: []string{strings.ToLower(m.method.object)},
+			Aliases: []string{strings.ToLower(object)},
 		}
 		parentCmd.AddCommand(objectCommand)
-		objectCommands[m.method.object] = objectCommand
+		objectCommands[object] = objectCommand
 	}
 	return objectCommand
 }
 
+func objectStr(object string, isByron bool) string {
+	if isByron {
+		return "Byron-era " + object
+	}
+	return object
+}
+
 type methodCommand struct {
 	cli    *walletCLI
 	method *clientMethod
@@ -95,10 +117,14 @@ type methodCommand struct {
 	// Only if method.hasBody
 	bodyFile    string
 	bodyContent string
+
+	// Method-specific hooks, registered through methodExtensions
+	bodyHooks     []func(body interface{}) error
+	responseHooks []func(content []byte)
 }
 
 func (c *methodCommand) verbCommand(allObjectVerbs []string) {
-	objectCommand := c.cli.objectCommand(c, allObjectVerbs)
+	objectCommand := c.cli.objectCommand(c.method.object, c.method.isByronMethod, allObjectVerbs)
 	isOnlyCommand := len(allObjectVerbs) == 1
 
 	var cmd *cobra.Command
@@ -115,20 +141,25 @@ func (c *methodCommand) verbCommand(allObjectVerbs []string) {
 
 	cmd.Short = fmt.Sprintf("%v %v objects", c.method.verb, c.objectStr())
 	cmd.Long = fmt.Sprintf("%v operation for %v objects", c.method.verb, c.objectStr())
+	if wallet.IsSigningOperation(c.method.method.Name) {
+		cmd.Long += "\nThis operation requires the wallet passphrase, it is rejected for watch-only wallets restored from an account public key."
+	}
 }
 
 func (c *methodCommand) objectStr() string {
-	res := c.method.object
-	if c.method.isByronMethod {
-		res = "Byron-era " + res
-	}
-	return res
+	return objectStr(c.method.object, c.method.isByronMethod)
 }
 
 func (c *methodCommand) configureCommand(cmd *cobra.Command) {
 	cmd.Args = cobra.ExactArgs(len(c.method.stringArgs))
 	for _, arg := range c.method.stringArgs {
-		cmd.Use += fmt.Sprintf(" <%v>", arg)
+		if arg == walletIdArg {
+			// The walletId can be omitted, if the profile defines a default wallet
+			cmd.Args = cobra.RangeArgs(len(c.method.stringArgs)-1, len(c.method.stringArgs))
+			cmd.Use += fmt.Sprintf(" [<%v>]", arg)
+		} else {
+			cmd.Use += fmt.Sprintf(" <%v>", arg)
+		}
 	}
 
 	if c.method.hasParams || c.method.hasBody {
@@ -145,12 +176,38 @@ func (c *methodCommand) configureCommand(cmd *cobra.Command) {
 		}
 	}
 
+	for _, extension := range methodExtensions[c.method.method.Name] {
+		extension(c, cmd)
+	}
+
 	cmd.Run = func(cmd *cobra.Command, args []string) {
 		c.callMethod(args)
 	}
 }
 
+// insertDefaultWallet inserts the default wallet of the profile, if the walletId argument was omitted.
+func (c *methodCommand) insertDefaultWallet(stringArgs []string) ([]string, error) {
+	if len(stringArgs) == len(c.method.stringArgs) {
+		return stringArgs, nil
+	}
+	if c.cli.profile.DefaultWallet == "" {
+		return nil, fmt.Errorf("Missing argument <%v>, and no default_wallet is configured in the profile", walletIdArg)
+	}
+	result := make([]string, 0, len(c.method.stringArgs))
+	for i, arg := range c.method.stringArgs {
+		if arg == walletIdArg {
+			result = append(result, c.cli.profile.DefaultWallet)
+			result = append(result, stringArgs[i:]...)
+			break
+		}
+		result = append(result, stringArgs[i])
+	}
+	return result, nil
+}
+
 func (c *methodCommand) callMethod(stringArgs []string) {
+	stringArgs, err := c.insertDefaultWallet(stringArgs)
+	c.cli.checkErr(err)
 	args, err := c.buildMethodArguments(stringArgs)
 	c.cli.checkErr(err)
 
@@ -158,7 +215,12 @@ func (c *methodCommand) callMethod(stringArgs []string) {
 	if c.cli.log.Level >= logrus.DebugLevel {
 		formattedArgs := make([]string, len(args))
 		for i, arg := range args {
-			formattedArgs[i] = fmt.Sprintf("%+v", arg)
+			if i == len(args)-1 && (c.method.hasParams || c.method.hasBody) {
+				// The body can contain passphrases and mnemonic sentences
+				formattedArgs[i] = wallet.RedactObject(arg)
+			} else {
+				formattedArgs[i] = fmt.Sprintf("%+v", arg)
+			}
 		}
 		c.cli.log.Debugf("Calling %v with arguments: %v", c.method.method.Name, formattedArgs)
 	}
@@ -180,7 +242,7 @@ func (c *methodCommand) callMethod(stringArgs []string) {
 		return
 	}
 	c.cli.checkErr(err)
-	c.cli.outputResponse(res)
+	c.cli.outputResponse(res, c.responseHooks...)
 }
 
 func (c *methodCommand) buildMethodArguments(stringArgs []string) ([]interface{}, error) {
@@ -250,6 +312,11 @@ func (c *methodCommand) loadBody() error {
 			return fmt.Errorf("Failed to parse body into type %T: %v", c.extraArg, err)
 		}
 	}
+	for _, hook := range c.bodyHooks {
+		if err := hook(c.extraArg); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// customCommand is a hand-written command, which is added next to the commands generated from wallet.Client methods.
+// Custom commands are always added as sub-command of their object command, even if the object has only one verb.
+type customCommand struct {
+	object  string
+	verb    string
+	isByron bool
+	build   func() *cobra.Command
+}
+
+func (c *walletCLI) customCommands() []*customCommand {
+	return []*customCommand{
+		{object: "AuditLog", verb: "verify", build: c.auditLogVerifyCommand},
+		{object: "Metadata", verb: "verify", build: c.metadataVerifyCommand},
+		{object: "Metadata", verb: "search", build: c.metadataSearchCommand},
+		{object: "Voting", verb: "register", build: c.votingRegisterCommand},
+		{object: "Wallet", verb: "create", build: c.walletCreateCommand},
+		{object: "Wallet", verb: "restore", build: c.walletRestoreCommand},
+		{object: "Wallet", verb: "restore", isByron: true, build: c.byronWalletRestoreCommand},
+		{object: "Wallet", verb: "import-xpub", build: c.walletImportXPubCommand},
+	}
+}
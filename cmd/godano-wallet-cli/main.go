@@ -1,13 +1,13 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
-	"os"
 	"sort"
 
 	"github.com/ghodss/yaml"
@@ -32,21 +32,33 @@ type walletCLI struct {
 	logQuiet      bool
 	logVeryQuiet  bool
 	outputYAML    bool
+	networkGuard  bool
+
+	passphraseFrom    string
+	newPassphraseFrom string
+	passphrases       []wallet.PassphraseProvider
+	auditLog          string
+	auditLogKeyFrom   string
+
+	configFile  string
+	profileName string
+	daedalus    string
+	profile     *wallet.Profile
+
+	prompter *prompter
 }
 
 func main() {
 	cli := walletCLI{
 		log:                 logrus.StandardLogger(),
-		ctx:                 context.Background(),
+		ctx:                 wallet.ContextWithCaller(context.Background(), auditCaller()),
 		objectCommands:      make(map[string]*cobra.Command),
 		byronObjectCommands: make(map[string]*cobra.Command),
-
-		// Read default value from the environment
-		serverAddress: os.Getenv(wallet.EnvVarWalletServerAddress),
+		networkGuard:        true,
 	}
 	cli.configureEarlyLogLevel()
 	cli.log.SetFormatter(newLogFormatter())
-	cobra.OnInitialize(cli.configureLogLevel)
+	cobra.OnInitialize(cli.configureLogLevel, cli.loadProfile)
 
 	// Inspect the client object type and find all methods that we can represent as commands
 	// This does not yet connect to the server
@@ -60,6 +72,11 @@ func main() {
 		objectVerbs[method.isByronMethod][method.object] =
 			append(objectVerbs[method.isByronMethod][method.object], method.verb)
 	}
+	customCommands := cli.customCommands()
+	for _, custom := range customCommands {
+		objectVerbs[custom.isByron][custom.object] =
+			append(objectVerbs[custom.isByron][custom.object], custom.verb)
+	}
 	for _, eraVerbs := range objectVerbs {
 		for _, verbs := range eraVerbs {
 			sort.Strings(verbs)
@@ -76,8 +93,15 @@ func main() {
 		}
 		cmd.verbCommand(objectVerbs[method.isByronMethod][method.object])
 	}
+	for _, custom := range customCommands {
+		objectCmd := cli.objectCommand(custom.object, custom.isByron, objectVerbs[custom.isByron][custom.object])
+		objectCmd.AddCommand(custom.build())
+	}
 
+	// Cached passphrases are zeroed on exit, also when exiting through checkErr()
+	logrus.RegisterExitHandler(cli.closePassphrases)
 	cli.rootCmd.Execute() // The returned error is already printed by Cobra itself
+	cli.closePassphrases()
 }
 
 func (c *walletCLI) checkErr(err interface{}) {
@@ -87,14 +111,124 @@ func (c *walletCLI) checkErr(err interface{}) {
 }
 
 func (c *walletCLI) connectClient() (*wallet.Client, error) {
-	tlsConfig, err := wallet.MakeTLSConfig()
+	clientOpts, err := c.clientOptions()
 	if err != nil {
 		return nil, err
 	}
-	return wallet.NewHTTPSClient(c.serverAddress, tlsConfig)
+	if c.serverAddress == "" {
+		if !c.dryRun {
+			return nil, fmt.Errorf("No server address configured, use --server, %v or a profile", wallet.EnvVarWalletServerAddress)
+		}
+		// Dry-run requests are only printed, so no server is needed
+		return wallet.NewClient("", clientOpts...)
+	}
+	dialOpts, err := c.profile.DialOptions()
+	if err != nil {
+		return nil, err
+	}
+	// No probe: dry-run must not contact the server, and otherwise the first request reports connection errors as well
+	dialOpts = append(dialOpts, wallet.WithoutProbe(), wallet.WithClientOptions(clientOpts...))
+	return wallet.DialClient(c.ctx, c.serverAddress, dialOpts...)
+}
+
+func (c *walletCLI) connectClientWithResponses() (*wallet.ClientWithResponses, error) {
+	client, err := c.connectClient()
+	if err != nil {
+		return nil, err
+	}
+	return &wallet.ClientWithResponses{ClientInterface: client}, nil
 }
 
-func (c *walletCLI) outputResponse(response *http.Response) {
+// loadProfile loads the selected profile. Flags take precedence over environment variables, which take precedence
+// over the values in the profiles file.
+func (c *walletCLI) loadProfile() {
+	profile, err := wallet.LoadProfileFromFile(c.configFile, c.profileName)
+	c.checkErr(err)
+	if c.daedalus != "" {
+		// The discovered connection replaces the connection of the profile, the other settings are kept
+		instance, err := wallet.DiscoverDaedalus(c.daedalus)
+		c.checkErr(err)
+		c.log.Debugf("Found Daedalus cardano-wallet process %v at %v", instance.Pid, instance.Server)
+		profile = instance.MergeProfile(profile)
+	}
+	c.profile = profile
+	flags := c.rootCmd.PersistentFlags()
+	if !flags.Changed("server") {
+		c.serverAddress = profile.Server
+	}
+	if !flags.Changed("yaml") {
+		c.outputYAML = profile.Output == wallet.OutputYAML
+	}
+	if !flags.Changed("audit-log") {
+		c.auditLog = profile.AuditLog
+	}
+	if !flags.Changed("audit-log-key-from") {
+		c.auditLogKeyFrom = profile.AuditLogKeyFrom
+	}
+	if profile.Name != "" {
+		c.log.Debugf("Using profile %v", profile.Name)
+	}
+}
+
+func (c *walletCLI) clientOptions() ([]wallet.ClientOption, error) {
+	var opts []wallet.ClientOption
+	// In dry-run mode, nothing is sent and the server should not be contacted to determine its network
+	if c.networkGuard && !c.dryRun {
+		opts = append(opts, wallet.WithNetworkGuard())
+	}
+	if !c.dryRun {
+		opts = append(opts, wallet.WithWatchOnlyGuard())
+	}
+	// In dry-run mode, the passphrase is not read, so it is not part of the printed request
+	if c.passphraseFrom != "" && !c.dryRun {
+		provider, err := c.passphraseSource(c.passphraseFrom, "Wallet passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, wallet.WithPassphraseProvider(provider))
+	}
+	if c.newPassphraseFrom != "" && !c.dryRun {
+		provider, err := c.passphraseSource(c.newPassphraseFrom, "New wallet passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, wallet.WithNewPassphraseProvider(provider))
+	}
+	// The passphrase providers are request editors, so the audit log sees the bodies with passphrases and redacts them
+	if c.auditLog != "" && !c.dryRun {
+		key, err := wallet.ReadAuditLogKey(c.ctx, c.auditLogKeyFrom)
+		if err != nil {
+			return nil, err
+		}
+		log, err := wallet.OpenAuditLog(c.auditLog, key)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, wallet.WithAuditLog(log))
+	}
+	return opts, nil
+}
+
+// passphraseSource parses the source of a --passphrase-from flag, and remembers the provider for closePassphrases().
+func (c *walletCLI) passphraseSource(source string, prompt string) (wallet.PassphraseProvider, error) {
+	provider, err := wallet.ParsePassphraseSource(source, prompt)
+	if err != nil {
+		return nil, err
+	}
+	c.passphrases = append(c.passphrases, provider)
+	return provider, nil
+}
+
+// closePassphrases overwrites the cached passphrases with zeros, see wallet.CachedPassphrase.
+func (c *walletCLI) closePassphrases() {
+	for _, provider := range c.passphrases {
+		if closer, ok := provider.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
+
+func (c *walletCLI) outputResponse(response *http.Response, hooks ...func(content []byte)) {
 	success := response.StatusCode >= http.StatusOK && response.StatusCode < http.StatusMultipleChoices
 	if success {
 		c.log.Debugf("Response status: %v", response.Status)
@@ -102,9 +236,33 @@ func (c *walletCLI) outputResponse(response *http.Response) {
 		c.log.Errorf("Response status: %v", response.Status)
 	}
 
+	if success && len(hooks) > 0 {
+		// Read the response once and hand the content to all hooks, before outputting it
+		content, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			c.log.Errorf("Failed to read HTTP body data: %v", err)
+			return
+		}
+		for _, hook := range hooks {
+			hook(content)
+		}
+		response.Body = ioutil.NopCloser(bytes.NewReader(content))
+	}
+
 	c.outputData(response.Body)
 }
 
+// outputObject prints the given object in the same format as response bodies.
+// Like printed requests, the object is redacted, as it could be a request body.
+func (c *walletCLI) outputObject(obj interface{}) {
+	marshalled, err := json.Marshal(obj)
+	if err != nil {
+		c.log.Errorf("Failed to JSON-marshal object: %v", err)
+		return
+	}
+	c.outputData(ioutil.NopCloser(bytes.NewReader(wallet.Redact(marshalled))))
+}
+
 func (c *walletCLI) outputData(data io.ReadCloser) {
 	// Fully read the request or response data
 	content, err := ioutil.ReadAll(data)
@@ -144,7 +302,24 @@ func (c *walletCLI) outputDryRunRequest(req *http.Request) {
 	c.log.Info("Dry-run mode, would have performed the following request:")
 	c.log.Infof("%v request to URL: %v", req.Method, req.URL)
 	if req.Body != nil {
-		c.log.Info("Dumping request body...")
-		c.outputData(req.Body)
+		content, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			c.log.Errorf("Failed to read HTTP body data: %v", err)
+			return
+		}
+		c.log.Info("Dumping request body (passphrases and mnemonic sentences are redacted)...")
+		c.outputData(ioutil.NopCloser(bytes.NewReader(wallet.Redact(content))))
+	}
+}
+
+// dryRunEditors returns a request editor that prints the request instead of executing it, if --dry-run is set.
+// The request then fails with dryRunErr.
+func (c *walletCLI) dryRunEditors() []wallet.RequestEditorFn {
+	if !c.dryRun {
+		return nil
 	}
+	return []wallet.RequestEditorFn{func(ctx context.Context, req *http.Request) error {
+		c.outputDryRunRequest(req)
+		return dryRunErr
+	}}
 }
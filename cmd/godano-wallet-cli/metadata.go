@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/godano/cardano-wallet-client/metaindex"
+	"github.com/godano/cardano-wallet-client/wallet"
+	"github.com/spf13/cobra"
+)
+
+func (c *walletCLI) metadataVerifyCommand() *cobra.Command {
+	var (
+		metadataContent string
+		metadataFile    string
+		signature       string
+		signatureFile   string
+		publicKey       string
+	)
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify a metadata signature offline",
+		Long: `Verify a signature created by "Metadata sign", without connecting to the server.
+The public key can be obtained through "WalletKey" or "AccountKey" (Bech32 stake_vk or acct_xvk).`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			meta, err := loadMetadata(metadataContent, metadataFile)
+			c.checkErr(err)
+			sig, err := loadSignature(signature, signatureFile)
+			c.checkErr(err)
+			c.checkErr(wallet.VerifyMetadataSignature(meta, sig, publicKey))
+			c.log.Info("Signature is valid")
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVarP(&metadataContent, "metadata", "m", "", "JSON-encoded metadata that was signed")
+	flags.StringVarP(&metadataFile, "metadata-file", "M", "", "JSON file with the metadata that was signed")
+	flags.StringVar(&signature, "signature", "", "Hex-encoded signature")
+	flags.StringVar(&signatureFile, "signature-file", "", "File with the binary signature, as returned by 'Metadata sign'")
+	flags.StringVarP(&publicKey, "key", "k", "", "Public key of the signer (Bech32 or hex)")
+	cmd.MarkFlagRequired("key")
+	return cmd
+}
+
+func loadMetadata(content, file string) (wallet.Metadata, error) {
+	if (content == "") == (file == "") {
+		return nil, fmt.Errorf("Exactly one of --metadata/-m and --metadata-file/-M must be specified")
+	}
+	data := []byte(content)
+	if file != "" {
+		var err error
+		data, err = ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Use json.Number to encode large integers exactly
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var meta wallet.Metadata
+	if err := decoder.Decode(&meta); err != nil {
+		return nil, fmt.Errorf("Failed to parse metadata: %v", err)
+	}
+	return meta, nil
+}
+
+func loadSignature(content, file string) ([]byte, error) {
+	if (content == "") == (file == "") {
+		return nil, fmt.Errorf("Exactly one of --signature and --signature-file must be specified")
+	}
+	if file != "" {
+		return ioutil.ReadFile(file)
+	}
+	return hex.DecodeString(strings.TrimSpace(content))
+}
+
+func (c *walletCLI) metadataSearchCommand() *cobra.Command {
+	var (
+		indexFile string
+		walletIds []string
+		offline   bool
+	)
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search the metadata of wallet transactions",
+		Long: `Search the metadata of wallet transactions, using a local index.
+Before searching, the index is updated with new transactions of the wallets given by --wallet,
+and of all wallets that were indexed before. Example query:
+
+  label=674 msg contains "invoice-42"
+
+The optional label=<n> term restricts the search to a metadata label. Other terms have the form
+<selector> <op> <value>, with op being one of =, !=, contains, or <selector> exists.
+Selectors are JSONPath-like, e.g. msg, msg[0], $.order.id or items[*].sku.`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if indexFile == "" {
+				var err error
+				indexFile, err = defaultIndexFile()
+				c.checkErr(err)
+			}
+			idx, err := metaindex.Open(indexFile)
+			c.checkErr(err)
+
+			if !offline {
+				// The given wallets may have been indexed before
+				known := make(map[string]bool, len(idx.Wallets)+len(walletIds))
+				for _, walletId := range walletIds {
+					known[walletId] = true
+				}
+				for walletId := range idx.Wallets {
+					known[walletId] = true
+				}
+				update := make([]string, 0, len(known))
+				for walletId := range known {
+					update = append(update, walletId)
+				}
+				sort.Strings(update)
+				client, err := c.connectClientWithResponses()
+				c.checkErr(err)
+				added, err := idx.Update(c.ctx, client, update...)
+				c.checkErr(err)
+				c.checkErr(idx.Save())
+				c.log.Debugf("Added %v metadata entries to index %v", added, indexFile)
+			}
+
+			result, err := idx.Search(strings.Join(args, " "))
+			c.checkErr(err)
+			if result == nil {
+				result = []*metaindex.Entry{}
+			}
+			c.outputObject(result)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&indexFile, "index", "", "Index file (default: metadata-index.json in the user cache directory)")
+	flags.StringSliceVarP(&walletIds, "wallet", "w", nil, "Index the transactions of these wallets")
+	flags.BoolVar(&offline, "offline", false, "Search the index without updating it")
+	return cmd
+}
+
+func defaultIndexFile() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "godano-wallet-client", "metadata-index.json"), nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/godano/cardano-wallet-client/wallet"
+	"github.com/spf13/cobra"
+)
+
+// methodExtensions add flags and behavior to the commands generated for specific wallet.Client methods.
+// The map keys are the method names (not the names remapped by methodNameRemappings).
+var methodExtensions = map[string][]func(c *methodCommand, cmd *cobra.Command){
+	"PostTransaction":    {addMessageFlag},
+	"PostTransactionFee": {addMessageFlag},
+	"ListTransactions":   {showTransactionMessages},
+}
+
+// addMessageFlag adds the --message flag, which attaches a CIP-20 transaction message to the request body.
+func addMessageFlag(c *methodCommand, cmd *cobra.Command) {
+	var message string
+	cmd.Flags().StringVarP(&message, "message", "m", "", "Attach a transaction message (CIP-20 metadata, label 674)")
+
+	c.bodyHooks = append(c.bodyHooks, func(body interface{}) error {
+		if message == "" {
+			return nil
+		}
+		meta, err := wallet.CIP20Metadata(message)
+		if err != nil {
+			return err
+		}
+		return mergeBodyMetadata(body, meta)
+	})
+}
+
+// mergeBodyMetadata adds the given metadata to the "metadata" field of a JSON body, which was parsed into a map.
+// Existing labels in the body are kept, unless they are overwritten by the given metadata.
+func mergeBodyMetadata(body interface{}, meta wallet.Metadata) error {
+	bodyMap, ok := body.(*map[string]interface{})
+	if !ok {
+		return fmt.Errorf("Cannot add metadata to body of type %T", body)
+	}
+	merged := make(map[string]interface{}, len(meta))
+	if existing, ok := (*bodyMap)["metadata"]; ok && existing != nil {
+		existingMap, ok := existing.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("Unexpected type of existing metadata in body: %T", existing)
+		}
+		for label, val := range existingMap {
+			merged[label] = val
+		}
+	}
+	for label, val := range meta {
+		merged[strconv.FormatUint(uint64(label), 10)] = val
+	}
+	(*bodyMap)["metadata"] = merged
+	return nil
+}
+
+// showTransactionMessages logs the CIP-20 messages of all listed transactions.
+// The messages are logged instead of printed, to keep the standard output parsable.
+func showTransactionMessages(c *methodCommand, _ *cobra.Command) {
+	c.responseHooks = append(c.responseHooks, func(content []byte) {
+		var transactions []struct {
+			Id       string           `json:"id"`
+			Metadata *wallet.Metadata `json:"metadata"`
+		}
+		if err := json.Unmarshal(content, &transactions); err != nil {
+			c.cli.log.Warnf("Failed to parse transactions for decoding messages: %v", err)
+			return
+		}
+		for _, tx := range transactions {
+			msg, err := wallet.ParseCIP20(tx.Metadata)
+			if err != nil {
+				c.cli.log.Warnf("Transaction %v: failed to decode message: %v", tx.Id, err)
+			} else if len(msg) > 0 {
+				c.cli.log.Infof("Transaction %v message: %v", tx.Id, strings.Join(msg, "\n"))
+			}
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/godano/cardano-wallet-client/wallet"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// prompter asks the user for input on stdin. Prompts are written to stderr to keep stdout parsable.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	fd  int
+}
+
+func (c *walletCLI) prompt() *prompter {
+	if c.prompter == nil {
+		c.prompter = &prompter{
+			in:  bufio.NewReader(os.Stdin),
+			out: os.Stderr,
+			fd:  int(os.Stdin.Fd()),
+		}
+	}
+	return c.prompter
+}
+
+func (p *prompter) isTerminal() bool {
+	return terminal.IsTerminal(p.fd)
+}
+
+// line reads one line of input, without the trailing newline.
+func (p *prompter) line(label string) (string, error) {
+	fmt.Fprint(p.out, label)
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("Failed to read input: %v", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// password reads a line without echoing it, if stdin is a terminal.
+func (p *prompter) password(label string) (string, error) {
+	if !p.isTerminal() {
+		return p.line(label)
+	}
+	fmt.Fprint(p.out, label)
+	password, err := terminal.ReadPassword(p.fd)
+	fmt.Fprintln(p.out)
+	if err != nil {
+		return "", fmt.Errorf("Failed to read passphrase: %v", err)
+	}
+	return string(password), nil
+}
+
+// newPassphrase asks for a new wallet passphrase twice and checks the length limits.
+func (p *prompter) newPassphrase(label string) (string, error) {
+	passphrase, err := p.password(label + ": ")
+	if err != nil {
+		return "", err
+	}
+	if err := wallet.ValidatePassphrase(passphrase); err != nil {
+		return "", err
+	}
+	repeated, err := p.password(label + " (repeat): ")
+	if err != nil {
+		return "", err
+	}
+	if repeated != passphrase {
+		return "", fmt.Errorf("Passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+// confirm asks a yes/no question, the default answer is no.
+func (p *prompter) confirm(question string) (bool, error) {
+	answer, err := p.line(question + " [y/N] ")
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/godano/cardano-wallet-client/wallet"
+	"github.com/spf13/cobra"
+)
+
+func (c *walletCLI) votingRegisterCommand() *cobra.Command {
+	var (
+		votingKey string
+		amount    int
+		submit    bool
+	)
+	cmd := &cobra.Command{
+		Use:   "register <walletId>",
+		Short: "Create a Catalyst voting registration",
+		Long: `Create a Catalyst voting registration (CIP-15/CIP-36) for the given wallet.
+The registration is signed by the wallet, and the resulting transaction body is printed without
+the passphrase, so it can be posted later with Transaction post and --passphrase-from.
+With --submit, the transaction is posted right away.
+The wallet passphrase is read from --passphrase-from, or prompted if that is not set.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			key, err := wallet.ParseVotingKey(votingKey)
+			c.checkErr(err)
+			// Without --passphrase-from, the passphrase is prompted and part of the request bodies.
+			// In dry-run mode, the passphrase provider is not installed, but the registration is still signed.
+			passphrase := ""
+			if c.passphraseFrom == "" || c.dryRun {
+				passphrase, err = c.prompt().password("Wallet passphrase: ")
+				c.checkErr(err)
+			}
+			client, err := c.connectClientWithResponses()
+			c.checkErr(err)
+
+			body, registration, err := wallet.BuildVotingRegistration(c.ctx, client, &wallet.VotingRegistrationRequest{
+				WalletId:   args[0],
+				VotingKey:  key,
+				Passphrase: passphrase,
+				Amount:     amount,
+			})
+			c.checkErr(err)
+			c.log.Infof("Created voting registration with nonce %v", registration.Nonce)
+			if !submit {
+				delete(body.(map[string]interface{}), "passphrase")
+				c.outputObject(body)
+				return
+			}
+			c.submitTransaction(client, args[0], body)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVarP(&votingKey, "voting-key", "k", "", "Public voting key (hex or Bech32)")
+	flags.IntVar(&amount, "amount", wallet.DefaultVotingRegistrationAmount, "Lovelace sent to the wallet's own address")
+	flags.BoolVar(&submit, "submit", false, "Post the registration transaction instead of printing it")
+	cmd.MarkFlagRequired("voting-key")
+	return cmd
+}
+
+// submitTransaction posts the given transaction body, respecting the --dry-run flag.
+func (c *walletCLI) submitTransaction(client *wallet.ClientWithResponses, walletId string, body wallet.PostTransactionJSONRequestBody) {
+	resp, err := client.PostTransaction(c.ctx, walletId, body, c.dryRunEditors()...)
+	if c.dryRun && err == dryRunErr {
+		return
+	}
+	c.checkErr(err)
+	if resp.StatusCode != http.StatusAccepted {
+		c.log.Errorf("Failed to submit transaction")
+	} else {
+		c.log.Infof("Submitted transaction for wallet %v", walletId)
+	}
+	c.outputResponse(resp)
+}
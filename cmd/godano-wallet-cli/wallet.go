@@ -0,0 +1,373 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/godano/cardano-wallet-client/mnemonic"
+	"github.com/godano/cardano-wallet-client/wallet"
+	"github.com/spf13/cobra"
+)
+
+// mnemonicConfirmationWords is the number of randomly chosen words the user must repeat to confirm the mnemonic sentence.
+const mnemonicConfirmationWords = 3
+
+func (c *walletCLI) walletCreateCommand() *cobra.Command {
+	var (
+		name         string
+		words        int
+		secondFactor bool
+		noConfirm    bool
+	)
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a new wallet with a generated mnemonic sentence",
+		Long: `Generate a new BIP-39 mnemonic sentence, display it, and create a Shelley wallet from it with PostWallet.
+Before the wallet is created, some words of the mnemonic sentence must be repeated to confirm that it was written down.
+The passphrase is read from --new-passphrase-from, or prompted twice if that is not set.
+The mnemonic sentence and prompts are written to stderr, the response of PostWallet is written to stdout.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			prompt := c.prompt()
+			sentence, err := mnemonic.Generate(words)
+			c.checkErr(err)
+			var secondFactorSentence []string
+			if secondFactor {
+				secondFactorSentence, err = mnemonic.Generate(mnemonic.SecondFactorWords)
+				c.checkErr(err)
+			}
+
+			c.showMnemonic(prompt, "Mnemonic sentence", sentence)
+			if secondFactor {
+				c.showMnemonic(prompt, "Mnemonic second factor", secondFactorSentence)
+			}
+			if !noConfirm {
+				c.checkErr(confirmMnemonic(prompt, sentence))
+				if secondFactor {
+					c.checkErr(confirmMnemonic(prompt, secondFactorSentence))
+				}
+			}
+
+			if name == "" {
+				name, err = prompt.line("Wallet name: ")
+				c.checkErr(err)
+			}
+			passphrase, err := c.newWalletPassphrase()
+			c.checkErr(err)
+			body, err := wallet.NewWalletPostData(name, passphrase, sentence, secondFactorSentence)
+			c.checkErr(err)
+			c.postWallet(body)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&name, "name", "", "Name of the new wallet (prompted if not set)")
+	flags.IntVarP(&words, "words", "w", mnemonic.DefaultWords, "Number of mnemonic words (15, 18, 21 or 24)")
+	flags.BoolVar(&secondFactor, "second-factor", false, fmt.Sprintf("Also generate a %v-word mnemonic second factor", mnemonic.SecondFactorWords))
+	flags.BoolVar(&noConfirm, "no-confirm", false, "Do not ask to repeat words of the mnemonic sentence")
+	return cmd
+}
+
+// newWalletPassphrase reads the passphrase of a new wallet from --new-passphrase-from, or prompts for it twice.
+// Passphrases on the command line would end up in the shell history.
+func (c *walletCLI) newWalletPassphrase() (string, error) {
+	if c.newPassphraseFrom == "" {
+		return c.prompt().newPassphrase("Wallet passphrase")
+	}
+	provider, err := c.passphraseSource(c.newPassphraseFrom, "Wallet passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	data, err := provider.Passphrase(c.ctx)
+	if err != nil {
+		return "", err
+	}
+	defer wallet.ZeroPassphrase(data)
+	passphrase := string(data)
+	if err := wallet.ValidatePassphrase(passphrase); err != nil {
+		return "", err
+	}
+	return passphrase, nil
+}
+
+func (c *walletCLI) showMnemonic(prompt *prompter, title string, words []string) {
+	fmt.Fprintf(prompt.out, "%v (%v words), write it down and keep it safe:\n\n", title, len(words))
+	for i, word := range words {
+		fmt.Fprintf(prompt.out, "%4d. %-10s", i+1, word)
+		if i%4 == 3 || i == len(words)-1 {
+			fmt.Fprintln(prompt.out)
+		}
+	}
+	fmt.Fprintln(prompt.out)
+}
+
+// confirmMnemonic asks for randomly chosen words of the mnemonic sentence.
+func confirmMnemonic(prompt *prompter, words []string) error {
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for _, i := range random.Perm(len(words))[:mnemonicConfirmationWords] {
+		answer, err := prompt.line(fmt.Sprintf("Confirm word %v: ", i+1))
+		if err != nil {
+			return err
+		}
+		if strings.ToLower(strings.TrimSpace(answer)) != words[i] {
+			return fmt.Errorf("Word %v does not match the mnemonic sentence", i+1)
+		}
+	}
+	return nil
+}
+
+// postWallet creates a Shelley wallet, respecting the --dry-run flag.
+func (c *walletCLI) postWallet(body interface{}) {
+	client, err := c.connectClient()
+	c.checkErr(err)
+	resp, err := client.PostWallet(c.ctx, body, c.dryRunEditors()...)
+	if c.dryRun && err == dryRunErr {
+		return
+	}
+	c.checkErr(err)
+	if resp.StatusCode != http.StatusCreated {
+		c.log.Errorf("Failed to create wallet")
+	}
+	c.outputResponse(resp)
+}
+
+func (c *walletCLI) walletRestoreCommand() *cobra.Command {
+	return c.restoreCommand(false)
+}
+
+func (c *walletCLI) byronWalletRestoreCommand() *cobra.Command {
+	return c.restoreCommand(true)
+}
+
+func (c *walletCLI) restoreCommand(isByron bool) *cobra.Command {
+	var (
+		name              string
+		style             string
+		words             int
+		secondFactor      bool
+		secondFactorWords int
+		addressPoolGap    int
+		noWait            bool
+		pollInterval      time.Duration
+	)
+	short := "Restore a Shelley wallet from a mnemonic sentence"
+	operation := "PostWallet"
+	if isByron {
+		short = "Restore a Byron wallet from a mnemonic sentence"
+		operation = "PostByronWallet"
+	}
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: short,
+		Long: short + `.
+The wallet name and mnemonic words are prompted, unless given as flags. The passphrase is read from
+--new-passphrase-from, or prompted twice if that is not set.
+Every mnemonic word is checked against the BIP-39 word list as it is entered, the whole sentence can also be pasted at once.
+After ` + operation + `, the restoration progress is shown until the wallet is ready.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !isByron && (secondFactorWords < wallet.MinMnemonicSecondFactorWords || secondFactorWords > wallet.MaxMnemonicSecondFactorWords) {
+				c.checkErr(fmt.Errorf("The second factor must have %v to %v words, got %v",
+					wallet.MinMnemonicSecondFactorWords, wallet.MaxMnemonicSecondFactorWords, secondFactorWords))
+			}
+			prompt := c.prompt()
+			var err error
+			if name == "" {
+				name, err = prompt.line("Wallet name: ")
+				c.checkErr(err)
+			}
+			if isByron && words == 0 {
+				words = 15
+				if style == wallet.ByronWalletStyleRandom {
+					words = wallet.MinByronMnemonicWords
+				}
+			}
+			sentence, err := promptMnemonic(prompt, "Mnemonic", words)
+			c.checkErr(err)
+
+			var secondFactorSentence []string
+			if cmd.Flags().Changed("second-factor-words") {
+				secondFactor = true
+			} else if !isByron && !cmd.Flags().Changed("second-factor") {
+				secondFactor, err = prompt.confirm("Does the wallet use a mnemonic second factor?")
+				c.checkErr(err)
+			}
+			if secondFactor {
+				secondFactorSentence, err = promptMnemonic(prompt, "Second factor", secondFactorWords)
+				c.checkErr(err)
+			}
+			passphrase, err := c.newWalletPassphrase()
+			c.checkErr(err)
+
+			var body interface{}
+			if isByron {
+				body, err = wallet.NewByronWalletPostData(style, name, passphrase, sentence)
+			} else {
+				if !cmd.Flags().Changed("address-pool-gap") {
+					addressPoolGap, err = promptInt(prompt, "Address pool gap", wallet.DefaultAddressPoolGap)
+					c.checkErr(err)
+				}
+				var data *wallet.WalletPostData
+				data, err = wallet.NewWalletPostData(name, passphrase, sentence, secondFactorSentence)
+				if err == nil {
+					data.AddressPoolGap = &addressPoolGap
+					err = data.Validate()
+				}
+				body = data
+			}
+			c.checkErr(err)
+			c.restoreWallet(isByron, body, !noWait, pollInterval)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&name, "name", "", "Name of the wallet (prompted if not set)")
+	flags.BoolVar(&noWait, "no-wait", false, "Do not wait until the wallet is restored")
+	flags.DurationVar(&pollInterval, "poll-interval", wallet.DefaultWalletPollInterval, "Interval for querying the restoration progress")
+	if isByron {
+		flags.StringVar(&style, "style", wallet.ByronWalletStyleIcarus, fmt.Sprintf("Wallet style, one of %v", wallet.ByronWalletStyles))
+		flags.IntVarP(&words, "words", "w", 0, "Number of mnemonic words, 15 if not set, except 12 for the random style")
+	} else {
+		flags.IntVarP(&words, "words", "w", mnemonic.DefaultWords, "Number of mnemonic words")
+		flags.BoolVar(&secondFactor, "second-factor", false, "Prompt for a mnemonic second factor (asked if not set)")
+		flags.IntVar(&secondFactorWords, "second-factor-words", mnemonic.SecondFactorWords, fmt.Sprintf("Number of second factor words, %v to %v (implies --second-factor)",
+			wallet.MinMnemonicSecondFactorWords, wallet.MaxMnemonicSecondFactorWords))
+		flags.IntVar(&addressPoolGap, "address-pool-gap", wallet.DefaultAddressPoolGap, "Number of consecutive unused addresses (prompted if not set)")
+	}
+	return cmd
+}
+
+// promptMnemonic reads the given number of mnemonic words. Each word is checked against the word list right away,
+// and unknown words are prompted again. Multiple words can be entered at once.
+func promptMnemonic(prompt *prompter, label string, count int) ([]string, error) {
+	var words []string
+	for len(words) < count {
+		line, err := prompt.password(fmt.Sprintf("%v word %v/%v: ", label, len(words)+1, count))
+		if err != nil {
+			return nil, err
+		}
+		for _, word := range strings.Fields(strings.ToLower(line)) {
+			if !mnemonic.IsWord(word) {
+				fmt.Fprintf(prompt.out, "Word %v is not in the BIP-39 word list, please enter it again\n", len(words)+1)
+				break
+			}
+			words = append(words, word)
+		}
+	}
+	if len(words) > count {
+		return nil, fmt.Errorf("Expected %v words, but got %v", count, len(words))
+	}
+	if err := mnemonic.Validate(words); err != nil {
+		return nil, fmt.Errorf("%v: %v", label, err)
+	}
+	return words, nil
+}
+
+// promptInt reads an integer, an empty input selects the default value.
+func promptInt(prompt *prompter, label string, defaultValue int) (int, error) {
+	for {
+		line, err := prompt.line(fmt.Sprintf("%v [%v]: ", label, defaultValue))
+		if err != nil {
+			return 0, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			return defaultValue, nil
+		}
+		if val, err := strconv.Atoi(line); err == nil {
+			return val, nil
+		}
+		fmt.Fprintf(prompt.out, "Not a number: %v\n", line)
+	}
+}
+
+// restoreWallet posts the wallet body, respecting the --dry-run flag, and optionally waits for the restoration.
+func (c *walletCLI) restoreWallet(isByron bool, body interface{}, wait bool, pollInterval time.Duration) {
+	client, err := c.connectClientWithResponses()
+	c.checkErr(err)
+	var resp *http.Response
+	if isByron {
+		resp, err = client.PostByronWallet(c.ctx, body, c.dryRunEditors()...)
+	} else {
+		resp, err = client.PostWallet(c.ctx, body, c.dryRunEditors()...)
+	}
+	if c.dryRun && err == dryRunErr {
+		return
+	}
+	c.checkErr(err)
+	content, err := ioutil.ReadAll(resp.Body)
+	c.checkErr(err)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		c.log.Errorf("Failed to restore wallet")
+		c.outputResponse(respWithContent(resp, content))
+		return
+	}
+
+	if wait {
+		var created struct {
+			Id string `json:"id"`
+		}
+		c.checkErr(json.Unmarshal(content, &created))
+		out := c.prompt().out
+		err = wallet.WaitForWallet(c.ctx, client, created.Id, isByron, pollInterval, func(state *wallet.WalletSyncState) {
+			if state.Status == wallet.WalletStatusSyncing {
+				fmt.Fprintf(out, "\rRestoring wallet %v: %5.1f%%", created.Id, state.Progress)
+			} else {
+				fmt.Fprintf(out, "\rRestoring wallet %v: %-10v", created.Id, state.Status)
+			}
+		})
+		fmt.Fprintln(out)
+		c.checkErr(err)
+	}
+	c.outputResponse(respWithContent(resp, content))
+}
+
+// respWithContent replaces the already consumed body of the response.
+func respWithContent(resp *http.Response, content []byte) *http.Response {
+	resp.Body = ioutil.NopCloser(bytes.NewReader(content))
+	return resp
+}
+
+func (c *walletCLI) walletImportXPubCommand() *cobra.Command {
+	var (
+		name           string
+		addressPoolGap int
+		noWait         bool
+		pollInterval   time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "import-xpub <account-public-key>",
+		Short: "Restore a watch-only wallet from an extended account public key",
+		Long: `Restore a watch-only Shelley wallet with PostWallet, from an extended account public key encoded as Bech32 (acct_xvk...) or hex.
+The key is validated before it is sent to the server. Watch-only wallets track addresses, balances and transactions,
+but operations that require the wallet passphrase (e.g. Transaction post, StakePool join) are rejected for them.
+After PostWallet, the restoration progress is shown until the wallet is ready.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			if name == "" {
+				name, err = c.prompt().line("Wallet name: ")
+				c.checkErr(err)
+			}
+			body, err := wallet.NewAccountPostData(name, args[0])
+			c.checkErr(err)
+			if cmd.Flags().Changed("address-pool-gap") {
+				body.AddressPoolGap = &addressPoolGap
+				c.checkErr(body.Validate())
+			}
+			c.restoreWallet(false, body, !noWait, pollInterval)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&name, "name", "", "Name of the wallet (prompted if not set)")
+	flags.IntVar(&addressPoolGap, "address-pool-gap", wallet.DefaultAddressPoolGap, "Number of consecutive unused addresses")
+	flags.BoolVar(&noWait, "no-wait", false, "Do not wait until the wallet is restored")
+	flags.DurationVar(&pollInterval, "poll-interval", wallet.DefaultWalletPollInterval, "Interval for querying the restoration progress")
+	return cmd
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/godano/cardano-wallet-client/exporter"
+	"github.com/godano/cardano-wallet-client/internal/prometheus"
+	"github.com/godano/cardano-wallet-client/wallet"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+type walletExporter struct {
+	log *logrus.Logger
+
+	serverAddress string
+	configFile    string
+	profileName   string
+	daedalus      string
+	listen        string
+	interval      time.Duration
+	wallets       []string
+	noTxs         bool
+	noUTxOs       bool
+	logQuiet      bool
+	logVerbose    bool
+}
+
+func main() {
+	e := walletExporter{
+		log:      logrus.StandardLogger(),
+		listen:   "127.0.0.1:9812",
+		interval: time.Minute,
+	}
+	cmd := &cobra.Command{
+		Use:   "godano-wallet-exporter",
+		Short: "Prometheus exporter for cardano-wallet",
+		Long: `godano-wallet-exporter periodically queries a cardano-wallet process and serves
+the state of the node and of all wallets as Prometheus gauges under /metrics`,
+		Args: cobra.NoArgs,
+		RunE: func(*cobra.Command, []string) error {
+			return e.run(context.Background())
+		},
+		SilenceUsage: true,
+	}
+	flags := cmd.Flags()
+	flags.StringVarP(&e.serverAddress, "server", "s", e.serverAddress,
+		"Endpoint of the cardano-wallet process, an http://, https:// or unix:// URL (default from the profile or "+wallet.EnvVarWalletServerAddress+")")
+	flags.StringVar(&e.configFile, "config", e.configFile, "Profiles file (default "+wallet.EnvConfigFile+" or ~/.config/"+wallet.ConfigFileName+")")
+	flags.StringVar(&e.profileName, "profile", e.profileName, "Profile to use from the profiles file (default "+wallet.EnvProfile+" or the default_profile of the file)")
+	flags.StringVar(&e.daedalus, "daedalus", e.daedalus, fmt.Sprintf("Connect to the cardano-wallet started by Daedalus for the given network, one of %v", wallet.DaedalusNetworks))
+	flags.StringVarP(&e.listen, "listen", "l", e.listen, "Local address to serve the metrics on")
+	flags.DurationVarP(&e.interval, "interval", "i", e.interval, "Interval between two scrapes of the cardano-wallet process")
+	flags.StringSliceVarP(&e.wallets, "wallet", "w", e.wallets, "Only export the wallets with the given ids (default all wallets)")
+	flags.BoolVar(&e.noTxs, "no-transactions", e.noTxs, "Do not count the pending transactions of the wallets")
+	flags.BoolVar(&e.noUTxOs, "no-utxos", e.noUTxOs, "Do not query the UTxO statistics of the wallets")
+	flags.BoolVarP(&e.logQuiet, "quiet", "q", e.logQuiet, "Set the log level to Warning")
+	flags.BoolVarP(&e.logVerbose, "verbose", "v", e.logVerbose, "Set the log level to Debug")
+
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func (e *walletExporter) run(ctx context.Context) error {
+	if e.logVerbose {
+		e.log.SetLevel(logrus.DebugLevel)
+	} else if e.logQuiet {
+		e.log.SetLevel(logrus.WarnLevel)
+	}
+	if e.interval <= 0 {
+		return fmt.Errorf("The interval must be positive, got %v", e.interval)
+	}
+
+	profile, err := wallet.LoadProfileFromFile(e.configFile, e.profileName)
+	if err != nil {
+		return err
+	}
+	if e.daedalus != "" {
+		instance, err := wallet.DiscoverDaedalus(e.daedalus)
+		if err != nil {
+			return err
+		}
+		e.log.Debugf("Found Daedalus cardano-wallet process %v at %v", instance.Pid, instance.Server)
+		profile = instance.MergeProfile(profile)
+	}
+	if e.serverAddress != "" {
+		profile.Server = e.serverAddress
+	}
+
+	// The requests of the exporter itself are exported as well
+	metrics := wallet.NewMetricsCollector()
+	// No probe: the server may be started after the exporter, which reports it with up=0 until then
+	client, err := profile.Dial(ctx, wallet.WithoutProbe(), wallet.WithClientOptions(wallet.WithObserver(metrics)))
+	if err != nil {
+		return err
+	}
+	var opts []exporter.Option
+	if len(e.wallets) > 0 {
+		opts = append(opts, exporter.WithWallets(e.wallets...))
+	}
+	if e.noTxs {
+		opts = append(opts, exporter.WithoutTransactions())
+	}
+	if e.noUTxOs {
+		opts = append(opts, exporter.WithoutUTxOStatistics())
+	}
+	exp := exporter.New(client, opts...)
+	go exp.Run(ctx, e.interval, func(err error) {
+		e.log.Warnln(err)
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", prometheus.ContentType)
+		if err := exp.WritePrometheus(w); err == nil {
+			metrics.WritePrometheus(w)
+		}
+	})
+	e.log.Infof("Serving metrics of %v on http://%v/metrics", profile.Server, e.listen)
+	return http.ListenAndServe(e.listen, mux)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/godano/cardano-wallet-client/gateway"
+	"github.com/godano/cardano-wallet-client/wallet"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+type walletGateway struct {
+	log *logrus.Logger
+
+	serverAddress string
+	configFile    string
+	profileName   string
+	gatewayConfig string
+	listen        string
+	tlsCert       string
+	tlsKey        string
+	auditLog      string
+	auditLogKey   string
+	logQuiet      bool
+	logVerbose    bool
+}
+
+func main() {
+	g := walletGateway{
+		log:    logrus.StandardLogger(),
+		listen: "127.0.0.1:8190",
+	}
+	cmd := &cobra.Command{
+		Use:   "godano-wallet-gateway",
+		Short: "Multi-tenant gateway for the cardano-wallet REST API",
+		Long: `godano-wallet-gateway serves the cardano-wallet REST API and forwards all requests
+to an upstream cardano-wallet process. Callers authenticate with API tokens, which restrict
+the allowed operations and wallets, and limit the rate of requests`,
+		Args: cobra.NoArgs,
+		RunE: func(*cobra.Command, []string) error {
+			return g.run(context.Background())
+		},
+		SilenceUsage: true,
+	}
+	flags := cmd.Flags()
+	flags.StringVarP(&g.serverAddress, "server", "s", g.serverAddress,
+		"Endpoint of the upstream cardano-wallet process, an http://, https:// or unix:// URL (default from the profile or "+wallet.EnvVarWalletServerAddress+")")
+	flags.StringVar(&g.configFile, "config", g.configFile, "Profiles file (default "+wallet.EnvConfigFile+" or ~/.config/"+wallet.ConfigFileName+")")
+	flags.StringVar(&g.profileName, "profile", g.profileName, "Profile to use from the profiles file (default "+wallet.EnvProfile+" or the default_profile of the file)")
+	flags.StringVarP(&g.gatewayConfig, "tokens", "t", g.gatewayConfig, "Gateway configuration file with the API tokens (required)")
+	flags.StringVarP(&g.listen, "listen", "l", g.listen, "Address to serve the API on")
+	flags.StringVar(&g.tlsCert, "tls-cert", g.tlsCert, "Serve HTTPS with the given certificate file")
+	flags.StringVar(&g.tlsKey, "tls-key", g.tlsKey, "Private key file of --tls-cert")
+	flags.StringVar(&g.auditLog, "audit-log", g.auditLog, "Record all mutating operations in this hash-chained audit log, with the token name as caller")
+	flags.StringVar(&g.auditLogKey, "audit-log-key-from", g.auditLogKey,
+		"Chain the audit log with an HMAC key, read from: file:<path>, env:<variable>, fd:<number> or cmd:<command>")
+	flags.BoolVarP(&g.logQuiet, "quiet", "q", g.logQuiet, "Set the log level to Warning, which does not log successful requests")
+	flags.BoolVarP(&g.logVerbose, "verbose", "v", g.logVerbose, "Set the log level to Debug")
+
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func (g *walletGateway) run(ctx context.Context) error {
+	if g.logVerbose {
+		g.log.SetLevel(logrus.DebugLevel)
+	} else if g.logQuiet {
+		g.log.SetLevel(logrus.WarnLevel)
+	}
+	if g.gatewayConfig == "" {
+		return fmt.Errorf("No gateway configuration, use --tokens")
+	}
+	if (g.tlsCert == "") != (g.tlsKey == "") {
+		return fmt.Errorf("--tls-cert and --tls-key must be used together")
+	}
+	config, err := gateway.LoadConfigFile(g.gatewayConfig)
+	if err != nil {
+		return err
+	}
+	profile, err := wallet.LoadProfileFromFile(g.configFile, g.profileName)
+	if err != nil {
+		return err
+	}
+	if g.serverAddress != "" {
+		profile.Server = g.serverAddress
+	}
+	if profile.Server == "" {
+		return fmt.Errorf("No server address configured, use --server, %v or a profile", wallet.EnvVarWalletServerAddress)
+	}
+
+	opts := []gateway.Option{gateway.WithRequestLog(g.logRequest)}
+	if g.auditLog != "" {
+		key, err := wallet.ReadAuditLogKey(ctx, g.auditLogKey)
+		if err != nil {
+			return err
+		}
+		log, err := wallet.OpenAuditLog(g.auditLog, key)
+		if err != nil {
+			return err
+		}
+		defer log.Close()
+		opts = append(opts, gateway.WithAuditLog(log))
+	}
+	handler, err := gateway.New(config, profile.NewClientWithResponses, opts...)
+	if err != nil {
+		return err
+	}
+	g.log.Infof("Forwarding requests for %v tokens on %v to %v", len(config.Tokens), g.listen, profile.Server)
+	if g.tlsCert != "" {
+		return http.ListenAndServeTLS(g.listen, g.tlsCert, g.tlsKey, handler)
+	}
+	return http.ListenAndServe(g.listen, handler)
+}
+
+func (g *walletGateway) logRequest(entry *gateway.RequestLogEntry) {
+	fields := logrus.Fields{
+		"caller":   entry.Caller,
+		"remote":   entry.RemoteAddr,
+		"status":   entry.Status,
+		"duration": entry.Duration,
+	}
+	if entry.WalletId != "" {
+		fields["wallet"] = entry.WalletId
+	}
+	if entry.Error != "" {
+		fields["error"] = entry.Error
+	}
+	operation := entry.Operation
+	if operation == "" {
+		operation = entry.Method + " " + entry.Path
+	}
+	log := g.log.WithFields(fields)
+	if entry.Status >= http.StatusBadRequest {
+		log.Warnln(operation)
+	} else {
+		log.Infoln(operation)
+	}
+}
@@ -0,0 +1,344 @@
+// Package exporter periodically collects the state of a cardano-wallet server and its wallets, and serves it as
+// gauges in the Prometheus text exposition format.
+//
+// Each scrape calls GetNetworkInformation, GetNetworkClock, GetCurrentSmashHealth, ListWallets and ListByronWallets,
+// and for every wallet ListTransactions (to count pending transactions) and GetUTxOsStatistics.
+// Failed calls are counted in the scrape_errors gauge, the other gauges of a scrape are still updated.
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/godano/cardano-wallet-client/internal/prometheus"
+	"github.com/godano/cardano-wallet-client/wallet"
+)
+
+// Exporter collects the gauges of a cardano-wallet server. The gauges of the last scrape are served by ServeHTTP().
+type Exporter struct {
+	client wallet.ClientInterface
+
+	wallets          map[string]bool
+	skipTransactions bool
+	skipUTxOs        bool
+
+	lock sync.Mutex
+	last *gauges
+}
+
+// Option configures New.
+type Option func(*Exporter)
+
+// WithWallets restricts the per-wallet gauges to the wallets with the given ids. By default, all wallets are exported.
+func WithWallets(walletIds ...string) Option {
+	return func(e *Exporter) {
+		if e.wallets == nil {
+			e.wallets = make(map[string]bool)
+		}
+		for _, id := range walletIds {
+			e.wallets[id] = true
+		}
+	}
+}
+
+// WithoutTransactions disables counting pending transactions, which lists all transactions of every wallet.
+func WithoutTransactions() Option {
+	return func(e *Exporter) {
+		e.skipTransactions = true
+	}
+}
+
+// WithoutUTxOStatistics disables the GetUTxOsStatistics calls for every wallet.
+func WithoutUTxOStatistics() Option {
+	return func(e *Exporter) {
+		e.skipUTxOs = true
+	}
+}
+
+// New returns an Exporter for the server of the given client. No request is made before the first Scrape().
+func New(client wallet.ClientInterface, opts ...Option) *Exporter {
+	e := &Exporter{client: client, last: newGauges()}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+type quantity struct {
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+}
+
+// walletState contains the fields of Shelley and Byron wallets, which are exported
+type walletState struct {
+	Id      string `json:"id"`
+	Name    string `json:"name"`
+	Balance struct {
+		Available quantity  `json:"available"`
+		Reward    *quantity `json:"reward"` // Only Shelley wallets
+		Total     quantity  `json:"total"`
+	} `json:"balance"`
+	Delegation *struct { // Only Shelley wallets
+		Active struct {
+			Status string  `json:"status"`
+			Target *string `json:"target"`
+		} `json:"active"`
+	} `json:"delegation"`
+	State struct {
+		Status   string    `json:"status"`
+		Progress *quantity `json:"progress"`
+	} `json:"state"`
+	Tip struct {
+		AbsoluteSlotNumber int `json:"absolute_slot_number"`
+	} `json:"tip"`
+}
+
+// Scrape collects all gauges. The new gauges replace the previous ones, even if some calls failed.
+// The result contains the errors of all failed calls.
+func (e *Exporter) Scrape(ctx context.Context) error {
+	start := time.Now()
+	g := newGauges()
+	var errs []string
+	// The wallet operations are called once per wallet, so the failures are counted for the whole scrape
+	var operations []string
+	failures := make(map[string]int)
+	call := func(operation string, result interface{}, send func() (*http.Response, error)) bool {
+		err := getJSON(operation, result, send)
+		if _, ok := failures[operation]; !ok {
+			operations = append(operations, operation)
+			failures[operation] = 0
+		}
+		if err != nil {
+			failures[operation]++
+			errs = append(errs, err.Error())
+		}
+		return err == nil
+	}
+
+	var network struct {
+		SyncProgress struct {
+			Status   string    `json:"status"`
+			Progress *quantity `json:"progress"`
+		} `json:"sync_progress"`
+		NodeTip struct {
+			AbsoluteSlotNumber int      `json:"absolute_slot_number"`
+			Height             quantity `json:"height"`
+		} `json:"node_tip"`
+		NetworkTip *struct {
+			AbsoluteSlotNumber int `json:"absolute_slot_number"`
+		} `json:"network_tip"`
+	}
+	up := call("GetNetworkInformation", &network, func() (*http.Response, error) {
+		return e.client.GetNetworkInformation(ctx)
+	})
+	g.set("up", "Whether the last GetNetworkInformation call succeeded.", boolValue(up))
+	nodeTip := network.NodeTip.AbsoluteSlotNumber
+	if up {
+		ready := network.SyncProgress.Status == wallet.WalletStatusReady
+		g.set("network_sync_ready", "Whether the node is synchronized with the network.", boolValue(ready))
+		g.set("network_sync_progress_percent", "Synchronization progress of the node.", progress(ready, network.SyncProgress.Progress))
+		g.set("node_tip_slot", "Absolute slot number of the node tip.", float64(nodeTip))
+		g.set("node_tip_height", "Block height of the node tip.", network.NodeTip.Height.Quantity)
+		if network.NetworkTip != nil {
+			g.set("network_tip_slot", "Absolute slot number of the network tip, according to the current time.",
+				float64(network.NetworkTip.AbsoluteSlotNumber))
+			g.set("node_slot_lag", "Slots between the node tip and the network tip.",
+				float64(network.NetworkTip.AbsoluteSlotNumber-nodeTip))
+		}
+	}
+
+	var clock struct {
+		Status string    `json:"status"`
+		Offset *quantity `json:"offset"`
+	}
+	if call("GetNetworkClock", &clock, func() (*http.Response, error) {
+		return e.client.GetNetworkClock(ctx, new(wallet.GetNetworkClockParams))
+	}) {
+		g.set("clock_available", "Whether the NTP clock check is available.", boolValue(clock.Status == "available"))
+		if clock.Offset != nil {
+			g.set("clock_offset_seconds", "Offset of the local clock to NTP servers.", clock.Offset.Quantity/1e6) // microseconds
+		}
+	}
+
+	var smash struct {
+		Health string `json:"health"`
+	}
+	if call("GetCurrentSmashHealth", &smash, func() (*http.Response, error) {
+		return e.client.GetCurrentSmashHealth(ctx, new(wallet.GetCurrentSmashHealthParams))
+	}) {
+		g.set("smash_health", "Health of the configured SMASH server, the value is always 1.", 1, "health", smash.Health)
+	}
+
+	for _, isByron := range []bool{false, true} {
+		era, operation := "shelley", "ListWallets"
+		send := func() (*http.Response, error) { return e.client.ListWallets(ctx) }
+		if isByron {
+			era, operation = "byron", "ListByronWallets"
+			send = func() (*http.Response, error) { return e.client.ListByronWallets(ctx) }
+		}
+		var wallets []*walletState
+		if !call(operation, &wallets, send) {
+			continue
+		}
+		for _, w := range wallets {
+			if e.wallets == nil || e.wallets[w.Id] {
+				e.scrapeWallet(ctx, g, call, w, era, nodeTip)
+			}
+		}
+	}
+
+	for _, operation := range operations {
+		g.set("scrape_errors", "Failed calls of the last scrape by operation.", float64(failures[operation]), "operation", operation)
+	}
+	g.set("scrape_duration_seconds", "Duration of the last scrape.", time.Since(start).Seconds())
+	g.set("scrape_timestamp_seconds", "Unix time of the last scrape.", float64(start.Unix()))
+	e.lock.Lock()
+	e.last = g
+	e.lock.Unlock()
+	if len(errs) > 0 {
+		return fmt.Errorf("Scrape failed partially: %v", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (e *Exporter) scrapeWallet(ctx context.Context, g *gauges, call func(string, interface{}, func() (*http.Response, error)) bool,
+	w *walletState, era string, nodeTip int) {
+	labels := []string{"wallet_id", w.Id, "wallet_name", w.Name, "era", era}
+	withLabels := func(extra ...string) []string {
+		return append(append([]string{}, labels...), extra...)
+	}
+	help := "Balance of the wallet by kind: available, reward or total."
+	g.set("wallet_balance_lovelace", help, w.Balance.Available.Quantity, withLabels("kind", "available")...)
+	if w.Balance.Reward != nil {
+		g.set("wallet_balance_lovelace", help, w.Balance.Reward.Quantity, withLabels("kind", "reward")...)
+	}
+	g.set("wallet_balance_lovelace", help, w.Balance.Total.Quantity, withLabels("kind", "total")...)
+
+	ready := w.State.Status == wallet.WalletStatusReady
+	g.set("wallet_sync_ready", "Whether the wallet is synchronized with the node.", boolValue(ready), labels...)
+	g.set("wallet_sync_progress_percent", "Synchronization progress of the wallet.", progress(ready, w.State.Progress), labels...)
+	g.set("wallet_tip_slot", "Absolute slot number of the wallet tip.", float64(w.Tip.AbsoluteSlotNumber), labels...)
+	if nodeTip > 0 {
+		g.set("wallet_slot_lag", "Slots between the wallet tip and the node tip.", float64(nodeTip-w.Tip.AbsoluteSlotNumber), labels...)
+	}
+	if w.Delegation != nil {
+		pool := ""
+		if w.Delegation.Active.Target != nil {
+			pool = *w.Delegation.Active.Target
+		}
+		g.set("wallet_delegating", "Whether the wallet delegates to a stake pool, and to which one.",
+			boolValue(w.Delegation.Active.Status == "delegating"), withLabels("pool", pool)...)
+	}
+
+	if !e.skipTransactions {
+		var transactions []struct {
+			Status string `json:"status"`
+		}
+		operation := "ListTransactions"
+		send := func() (*http.Response, error) {
+			return e.client.ListTransactions(ctx, w.Id, new(wallet.ListTransactionsParams))
+		}
+		if era == "byron" {
+			operation = "ListByronTransactions"
+			send = func() (*http.Response, error) {
+				return e.client.ListByronTransactions(ctx, w.Id, new(wallet.ListByronTransactionsParams))
+			}
+		}
+		if call(operation, &transactions, send) {
+			pending := 0
+			for _, tx := range transactions {
+				if tx.Status == "pending" {
+					pending++
+				}
+			}
+			g.set("wallet_pending_transactions", "Transactions of the wallet, which are not yet in the ledger.", float64(pending), labels...)
+		}
+	}
+
+	if !e.skipUTxOs {
+		var utxos wallet.Distribution
+		operation := "GetUTxOsStatistics"
+		send := func() (*http.Response, error) { return e.client.GetUTxOsStatistics(ctx, w.Id) }
+		if era == "byron" {
+			operation = "GetByronUTxOsStatistics"
+			send = func() (*http.Response, error) { return e.client.GetByronUTxOsStatistics(ctx, w.Id) }
+		}
+		if call(operation, &utxos, send) {
+			count := uint(0)
+			for _, n := range utxos.Distribution {
+				count += n
+			}
+			g.set("wallet_utxos", "Number of UTxOs of the wallet.", float64(count), labels...)
+			g.set("wallet_utxo_lovelace", "Total value of the UTxOs of the wallet.", float64(utxos.Total.Quantity), labels...)
+		}
+	}
+}
+
+// progress returns the progress in percent, which is only reported by the server while syncing.
+func progress(ready bool, p *quantity) float64 {
+	switch {
+	case p != nil:
+		return p.Quantity
+	case ready:
+		return 100
+	}
+	return 0
+}
+
+// getJSON sends a request and parses the JSON response, which must have status 200.
+func getJSON(operation string, result interface{}, send func() (*http.Response, error)) error {
+	resp, err := send()
+	if err != nil {
+		return fmt.Errorf("%v: %v", operation, err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%v: failed to read response: %v", operation, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%v: unexpected response status %v", operation, resp.Status)
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("%v: failed to parse response: %v", operation, err)
+	}
+	return nil
+}
+
+// Run scrapes immediately and then in the given interval, until the context is done.
+// Errors of scrapes are passed to onError, which may be nil.
+func (e *Exporter) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := e.Scrape(ctx); err != nil && onError != nil && ctx.Err() == nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// WritePrometheus writes the gauges of the last scrape in the Prometheus text exposition format.
+func (e *Exporter) WritePrometheus(w io.Writer) error {
+	e.lock.Lock()
+	last := e.last
+	e.lock.Unlock()
+	return last.writePrometheus(w)
+}
+
+// ServeHTTP serves the gauges of the last scrape in the Prometheus text exposition format.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", prometheus.ContentType)
+	e.WritePrometheus(w)
+}
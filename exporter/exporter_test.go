@@ -0,0 +1,151 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/godano/cardano-wallet-client/wallet"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+var fakeResponses = map[string]string{
+	"/v2/network/information": `{"sync_progress": {"status": "ready"},
+		"node_tip": {"absolute_slot_number": 1000, "height": {"quantity": 500, "unit": "block"}},
+		"network_tip": {"absolute_slot_number": 1003}}`,
+	"/v2/network/clock": `{"status": "available", "offset": {"quantity": -2500, "unit": "microsecond"}}`,
+	"/v2/wallets": `[{"id": "w1", "name": "Main \"wallet\"",
+		"balance": {"available": {"quantity": 10}, "reward": {"quantity": 2}, "total": {"quantity": 12}},
+		"delegation": {"active": {"status": "delegating", "target": "pool1"}},
+		"state": {"status": "syncing", "progress": {"quantity": 42.5, "unit": "percent"}},
+		"tip": {"absolute_slot_number": 990}},
+		{"id": "w2", "name": "Second",
+		"balance": {"available": {"quantity": 1}, "total": {"quantity": 1}},
+		"state": {"status": "ready"}, "tip": {"absolute_slot_number": 1000}}]`,
+	"/v2/byron-wallets": `[{"id": "b1", "name": "Legacy",
+		"balance": {"available": {"quantity": 5}, "total": {"quantity": 5}},
+		"state": {"status": "ready"}, "tip": {"absolute_slot_number": 1000}}]`,
+	"/v2/wallets/w1/transactions":           `[{"status": "pending"}, {"status": "in_ledger"}, {"status": "pending"}]`,
+	"/v2/wallets/w1/statistics/utxos":       `{"total": {"quantity": 12, "unit": "lovelace"}, "scale": "log10", "distribution": {"10": 2, "100": 1}}`,
+	"/v2/wallets/w2/statistics/utxos":       `{"total": {"quantity": 1, "unit": "lovelace"}, "scale": "log10", "distribution": {"10": 1}}`,
+	"/v2/byron-wallets/b1/transactions":     `[]`,
+	"/v2/byron-wallets/b1/statistics/utxos": `{"total": {"quantity": 5, "unit": "lovelace"}, "scale": "log10", "distribution": {"10": 1}}`,
+}
+
+type ExporterTestSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	server *httptest.Server
+	client *wallet.ClientWithResponses
+	ctx    context.Context
+}
+
+func TestExporter(t *testing.T) {
+	testSuite := new(ExporterTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *ExporterTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+	s.ctx = context.Background()
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := fakeResponses[r.URL.Path]
+		if !ok {
+			// The SMASH health is not configured in the fake server
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	var err error
+	s.client, err = wallet.NewClientWithResponses(s.server.URL + "/v2")
+	s.NoError(err)
+}
+
+func (s *ExporterTestSuite) TearDownSuite() {
+	s.server.Close()
+}
+
+func (s *ExporterTestSuite) scrape(e *Exporter) (string, error) {
+	err := e.Scrape(s.ctx)
+	out := new(bytes.Buffer)
+	s.NoError(e.WritePrometheus(out))
+	return out.String(), err
+}
+
+func (s *ExporterTestSuite) TestScrape() {
+	text, err := s.scrape(New(s.client))
+	s.Error(err)
+	s.Contains(err.Error(), "GetCurrentSmashHealth")
+
+	shelley := `wallet_id="w1",wallet_name="Main \"wallet\"",era="shelley"`
+	byron := `wallet_id="b1",wallet_name="Legacy",era="byron"`
+	for _, line := range []string{
+		"# TYPE cardano_wallet_up gauge",
+		"cardano_wallet_up 1",
+		`cardano_wallet_scrape_errors{operation="GetNetworkInformation"} 0`,
+		`cardano_wallet_scrape_errors{operation="GetCurrentSmashHealth"} 1`,
+		`cardano_wallet_scrape_errors{operation="GetUTxOsStatistics"} 0`,
+		"cardano_wallet_network_sync_ready 1",
+		"cardano_wallet_network_sync_progress_percent 100",
+		"cardano_wallet_node_tip_slot 1000",
+		"cardano_wallet_node_tip_height 500",
+		"cardano_wallet_node_slot_lag 3",
+		"cardano_wallet_clock_available 1",
+		"cardano_wallet_clock_offset_seconds -0.0025",
+		`cardano_wallet_wallet_balance_lovelace{` + shelley + `,kind="available"} 10`,
+		`cardano_wallet_wallet_balance_lovelace{` + shelley + `,kind="reward"} 2`,
+		`cardano_wallet_wallet_balance_lovelace{` + shelley + `,kind="total"} 12`,
+		`cardano_wallet_wallet_balance_lovelace{` + byron + `,kind="total"} 5`,
+		`cardano_wallet_wallet_sync_ready{` + shelley + `} 0`,
+		`cardano_wallet_wallet_sync_progress_percent{` + shelley + `} 42.5`,
+		`cardano_wallet_wallet_sync_progress_percent{` + byron + `} 100`,
+		`cardano_wallet_wallet_slot_lag{` + shelley + `} 10`,
+		`cardano_wallet_wallet_delegating{` + shelley + `,pool="pool1"} 1`,
+		`cardano_wallet_wallet_pending_transactions{` + shelley + `} 2`,
+		`cardano_wallet_wallet_pending_transactions{` + byron + `} 0`,
+		`cardano_wallet_wallet_utxos{` + shelley + `} 3`,
+		`cardano_wallet_wallet_utxo_lovelace{` + byron + `} 5`,
+	} {
+		s.Contains(text, line+"\n")
+	}
+	s.NotContains(text, `kind="reward"} 0`)
+
+	// The transactions of w2 are missing, the successful call for w1 must not hide the failure
+	s.Contains(err.Error(), "ListTransactions")
+	s.Equal(1, strings.Count(text, `cardano_wallet_scrape_errors{operation="ListTransactions"}`))
+	s.Contains(text, `cardano_wallet_scrape_errors{operation="ListTransactions"} 1`+"\n")
+	s.Contains(text, `cardano_wallet_wallet_utxos{wallet_id="w2",wallet_name="Second",era="shelley"} 1`+"\n")
+	s.NotContains(text, `cardano_wallet_wallet_pending_transactions{wallet_id="w2"`)
+	s.NotContains(text, "cardano_wallet_smash_health{")
+	s.NotContains(text, `cardano_wallet_wallet_delegating{`+byron)
+
+	recorder := httptest.NewRecorder()
+	New(s.client).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	s.Empty(recorder.Body.String())
+	s.Contains(recorder.Header().Get("Content-Type"), "text/plain")
+}
+
+func (s *ExporterTestSuite) TestOptions() {
+	text, _ := s.scrape(New(s.client, WithWallets("b1"), WithoutTransactions(), WithoutUTxOStatistics()))
+	s.Contains(text, `wallet_id="b1"`)
+	s.NotContains(text, `wallet_id="w1"`)
+	s.NotContains(text, "ListByronTransactions")
+	s.NotContains(text, "cardano_wallet_wallet_utxos")
+}
+
+func (s *ExporterTestSuite) TestServerDown() {
+	client, err := wallet.NewClient("http://localhost:1/v2")
+	s.NoError(err)
+	text, err := s.scrape(New(client))
+	s.Error(err)
+	s.Contains(text, "cardano_wallet_up 0\n")
+	s.Contains(text, `cardano_wallet_scrape_errors{operation="ListWallets"} 1`+"\n")
+	s.NotContains(text, "cardano_wallet_wallet_")
+}
@@ -0,0 +1,62 @@
+package exporter
+
+import (
+	"io"
+
+	"github.com/godano/cardano-wallet-client/internal/prometheus"
+)
+
+// Prefix is the prefix of all metrics of the Exporter.
+const Prefix = "cardano_wallet_"
+
+// gaugeFamily is a gauge with all its samples of one scrape.
+type gaugeFamily struct {
+	name    string
+	help    string
+	samples []sample
+}
+
+type sample struct {
+	labels []string // Alternating names and values
+	value  float64
+}
+
+// gauges collects the gauge families of one scrape, in the order of their first use.
+type gauges struct {
+	families []*gaugeFamily
+	byName   map[string]*gaugeFamily
+}
+
+func newGauges() *gauges {
+	return &gauges{byName: make(map[string]*gaugeFamily)}
+}
+
+// set adds a sample. All samples of a family must use the same label names.
+func (g *gauges) set(name string, help string, value float64, labels ...string) {
+	family := g.byName[name]
+	if family == nil {
+		family = &gaugeFamily{name: name, help: help}
+		g.families = append(g.families, family)
+		g.byName[name] = family
+	}
+	family.samples = append(family.samples, sample{labels: labels, value: value})
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// writePrometheus writes the gauges in the Prometheus text exposition format.
+func (g *gauges) writePrometheus(w io.Writer) error {
+	out := prometheus.NewWriter(w, Prefix)
+	for _, family := range g.families {
+		out.Family(family.name, "gauge", family.help)
+		for _, sample := range family.samples {
+			out.Sample(family.name, sample.value, sample.labels...)
+		}
+	}
+	return out.Flush()
+}
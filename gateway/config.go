@@ -0,0 +1,140 @@
+package gateway
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/ghodss/yaml"
+	"github.com/godano/cardano-wallet-client/wallet"
+)
+
+// Token grants a caller access to the gateway. Callers send the token in the header "Authorization: Bearer <token>".
+// Empty allowlists allow all operations or all wallets.
+type Token struct {
+	// Name identifies the caller in logs, it must be unique
+	Name string `json:"name"`
+
+	// The secret token is configured in plain text, as environment variable, or as hex-encoded SHA-256 hash
+	Token       string `json:"token,omitempty"`
+	TokenEnv    string `json:"token_env,omitempty"`
+	TokenSHA256 string `json:"token_sha256,omitempty"`
+
+	// ReadOnly tokens may only call operations, for which wallet.IsReadOnlyOperation() is true
+	ReadOnly bool `json:"read_only,omitempty"`
+	// Operations are the allowed operation Ids, like "ListWallets"
+	Operations []string `json:"operations,omitempty"`
+	// Wallets are the allowed wallet ids. Operations on other wallets are rejected, and other wallets are removed
+	// from the results of ListWallets and ListByronWallets. Mutating operations, which do not refer to a wallet,
+	// like PostWallet, PutSettings or PostExternalTransaction, are rejected, unless they are listed in Operations.
+	Wallets []string `json:"wallets,omitempty"`
+
+	// RateLimit limits the requests of the token per second, see wallet.WithRateLimit(). 0 disables the limit.
+	RateLimit float64 `json:"rate_limit,omitempty"`
+	Burst     int     `json:"burst,omitempty"`
+	// ConcurrencyLimit limits the requests of the token in flight, see wallet.WithConcurrencyLimit(). 0 disables the limit.
+	ConcurrencyLimit int `json:"concurrency_limit,omitempty"`
+	// MaxWait limits how long requests wait for the limits of the token, before they are rejected with status 429.
+	// 0 waits as long as the caller is connected.
+	MaxWait wallet.Duration `json:"max_wait,omitempty"`
+}
+
+// Config is the content of the gateway configuration file.
+type Config struct {
+	Tokens []*Token `json:"tokens"`
+}
+
+// LoadConfigFile reads and validates a gateway configuration file.
+func LoadConfigFile(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := new(Config)
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("Failed to parse gateway configuration %v: %v", path, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("Invalid gateway configuration %v: %v", path, err)
+	}
+	return config, nil
+}
+
+// Validate checks that all tokens are named uniquely, have exactly one secret, and only allow known operations.
+func (c *Config) Validate() error {
+	if len(c.Tokens) == 0 {
+		return fmt.Errorf("No tokens configured")
+	}
+	names := make(map[string]bool)
+	hashes := make(map[[sha256.Size]byte]string)
+	for i, token := range c.Tokens {
+		if token == nil || token.Name == "" {
+			return fmt.Errorf("Token %v has no name", i+1)
+		}
+		if names[token.Name] {
+			return fmt.Errorf("Token name %v is used more than once", token.Name)
+		}
+		names[token.Name] = true
+		hash, err := token.hash()
+		if err != nil {
+			return fmt.Errorf("Token %v: %v", token.Name, err)
+		}
+		if other, ok := hashes[hash]; ok {
+			return fmt.Errorf("Tokens %v and %v have the same secret", other, token.Name)
+		}
+		hashes[hash] = token.Name
+		for _, id := range token.Operations {
+			if wallet.OperationById(id) == nil {
+				return fmt.Errorf("Token %v: unknown operation %v", token.Name, id)
+			}
+		}
+		if token.RateLimit < 0 || token.Burst < 0 || token.ConcurrencyLimit < 0 || token.MaxWait < 0 {
+			return fmt.Errorf("Token %v: limits must not be negative", token.Name)
+		}
+	}
+	return nil
+}
+
+// hash returns the SHA-256 hash of the secret token.
+func (t *Token) hash() (result [sha256.Size]byte, err error) {
+	secrets := 0
+	for _, secret := range []string{t.Token, t.TokenEnv, t.TokenSHA256} {
+		if secret != "" {
+			secrets++
+		}
+	}
+	if secrets != 1 {
+		return result, fmt.Errorf("Exactly one of token, token_env and token_sha256 must be set")
+	}
+	switch {
+	case t.Token != "":
+		return sha256.Sum256([]byte(t.Token)), nil
+	case t.TokenEnv != "":
+		secret := os.Getenv(t.TokenEnv)
+		if secret == "" {
+			return result, fmt.Errorf("Environment variable %v is not set", t.TokenEnv)
+		}
+		return sha256.Sum256([]byte(secret)), nil
+	}
+	decoded, err := hex.DecodeString(t.TokenSHA256)
+	if err != nil || len(decoded) != sha256.Size {
+		return result, fmt.Errorf("token_sha256 must be %v hex-encoded bytes", sha256.Size)
+	}
+	copy(result[:], decoded)
+	return result, nil
+}
+
+// clientOptions returns the limits of the token as options for its upstream client.
+func (t *Token) clientOptions() []wallet.ClientOption {
+	// The limits wrap the HTTP client, so the end of the wait is reached once the request passed all limits
+	opts := []wallet.ClientOption{withEndOfWait()}
+	if t.RateLimit > 0 {
+		opts = append(opts, wallet.WithRateLimit(t.RateLimit, t.Burst))
+	}
+	if t.ConcurrencyLimit > 0 {
+		opts = append(opts, wallet.WithConcurrencyLimit(t.ConcurrencyLimit))
+	}
+	return opts
+}
@@ -0,0 +1,588 @@
+package gateway
+
+import (
+	"github.com/godano/cardano-wallet-client/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+// The methods of wallet.ServerInterface forward the requests to the upstream client of the authenticated token.
+// Path parameters and query parameters have been parsed by the generated wallet.ServerInterfaceWrapper,
+// request bodies are forwarded unchanged.
+
+var _ wallet.ServerInterface = (*Gateway)(nil)
+
+// PostAnyAddress implements wallet.ServerInterface.
+func (g *Gateway) PostAnyAddress(ctx echo.Context) error {
+	resp, err := g.upstream(ctx).PostAnyAddressWithBodyWithResponse(ctx.Request().Context(), contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// InspectAddress implements wallet.ServerInterface.
+func (g *Gateway) InspectAddress(ctx echo.Context, addressId string) error {
+	resp, err := g.upstream(ctx).InspectAddressWithResponse(ctx.Request().Context(), addressId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// ListByronWallets implements wallet.ServerInterface.
+func (g *Gateway) ListByronWallets(ctx echo.Context) error {
+	resp, err := g.upstream(ctx).ListByronWalletsWithResponse(ctx.Request().Context())
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respondWallets(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// PostByronWallet implements wallet.ServerInterface.
+func (g *Gateway) PostByronWallet(ctx echo.Context) error {
+	resp, err := g.upstream(ctx).PostByronWalletWithBodyWithResponse(ctx.Request().Context(), contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// DeleteByronWallet implements wallet.ServerInterface.
+func (g *Gateway) DeleteByronWallet(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).DeleteByronWalletWithResponse(ctx.Request().Context(), walletId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetByronWallet implements wallet.ServerInterface.
+func (g *Gateway) GetByronWallet(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).GetByronWalletWithResponse(ctx.Request().Context(), walletId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// PutByronWallet implements wallet.ServerInterface.
+func (g *Gateway) PutByronWallet(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).PutByronWalletWithBodyWithResponse(ctx.Request().Context(), walletId, contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// ListByronAddresses implements wallet.ServerInterface.
+func (g *Gateway) ListByronAddresses(ctx echo.Context, walletId string, params wallet.ListByronAddressesParams) error {
+	resp, err := g.upstream(ctx).ListByronAddressesWithResponse(ctx.Request().Context(), walletId, &params)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// CreateAddress implements wallet.ServerInterface.
+func (g *Gateway) CreateAddress(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).CreateAddressWithBodyWithResponse(ctx.Request().Context(), walletId, contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// ImportAddresses implements wallet.ServerInterface.
+func (g *Gateway) ImportAddresses(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).ImportAddressesWithBodyWithResponse(ctx.Request().Context(), walletId, contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// ImportAddress implements wallet.ServerInterface.
+func (g *Gateway) ImportAddress(ctx echo.Context, walletId string, addressId string) error {
+	resp, err := g.upstream(ctx).ImportAddressWithResponse(ctx.Request().Context(), walletId, addressId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// ListByronAssets implements wallet.ServerInterface.
+func (g *Gateway) ListByronAssets(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).ListByronAssetsWithResponse(ctx.Request().Context(), walletId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetByronAssetDefault implements wallet.ServerInterface.
+func (g *Gateway) GetByronAssetDefault(ctx echo.Context, walletId string, policyId string) error {
+	resp, err := g.upstream(ctx).GetByronAssetDefaultWithResponse(ctx.Request().Context(), walletId, policyId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetByronAsset implements wallet.ServerInterface.
+func (g *Gateway) GetByronAsset(ctx echo.Context, walletId string, policyId string, assetName string) error {
+	resp, err := g.upstream(ctx).GetByronAssetWithResponse(ctx.Request().Context(), walletId, policyId, assetName)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// ByronSelectCoins implements wallet.ServerInterface.
+func (g *Gateway) ByronSelectCoins(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).ByronSelectCoinsWithBodyWithResponse(ctx.Request().Context(), walletId, contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetByronWalletMigrationInfo implements wallet.ServerInterface.
+func (g *Gateway) GetByronWalletMigrationInfo(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).GetByronWalletMigrationInfoWithResponse(ctx.Request().Context(), walletId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// MigrateByronWallet implements wallet.ServerInterface.
+func (g *Gateway) MigrateByronWallet(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).MigrateByronWalletWithBodyWithResponse(ctx.Request().Context(), walletId, contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// PutByronWalletPassphrase implements wallet.ServerInterface.
+func (g *Gateway) PutByronWalletPassphrase(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).PutByronWalletPassphraseWithBodyWithResponse(ctx.Request().Context(), walletId, contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// PostByronTransactionFee implements wallet.ServerInterface.
+func (g *Gateway) PostByronTransactionFee(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).PostByronTransactionFeeWithBodyWithResponse(ctx.Request().Context(), walletId, contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetByronUTxOsStatistics implements wallet.ServerInterface.
+func (g *Gateway) GetByronUTxOsStatistics(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).GetByronUTxOsStatisticsWithResponse(ctx.Request().Context(), walletId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// ListByronTransactions implements wallet.ServerInterface.
+func (g *Gateway) ListByronTransactions(ctx echo.Context, walletId string, params wallet.ListByronTransactionsParams) error {
+	resp, err := g.upstream(ctx).ListByronTransactionsWithResponse(ctx.Request().Context(), walletId, &params)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// PostByronTransaction implements wallet.ServerInterface.
+func (g *Gateway) PostByronTransaction(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).PostByronTransactionWithBodyWithResponse(ctx.Request().Context(), walletId, contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// DeleteByronTransaction implements wallet.ServerInterface.
+func (g *Gateway) DeleteByronTransaction(ctx echo.Context, walletId string, transactionId string) error {
+	resp, err := g.upstream(ctx).DeleteByronTransactionWithResponse(ctx.Request().Context(), walletId, transactionId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetByronTransaction implements wallet.ServerInterface.
+func (g *Gateway) GetByronTransaction(ctx echo.Context, walletId string, transactionId string) error {
+	resp, err := g.upstream(ctx).GetByronTransactionWithResponse(ctx.Request().Context(), walletId, transactionId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetNetworkClock implements wallet.ServerInterface.
+func (g *Gateway) GetNetworkClock(ctx echo.Context, params wallet.GetNetworkClockParams) error {
+	resp, err := g.upstream(ctx).GetNetworkClockWithResponse(ctx.Request().Context(), &params)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetNetworkInformation implements wallet.ServerInterface.
+func (g *Gateway) GetNetworkInformation(ctx echo.Context) error {
+	resp, err := g.upstream(ctx).GetNetworkInformationWithResponse(ctx.Request().Context())
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetNetworkParameters implements wallet.ServerInterface.
+func (g *Gateway) GetNetworkParameters(ctx echo.Context) error {
+	resp, err := g.upstream(ctx).GetNetworkParametersWithResponse(ctx.Request().Context())
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// PostExternalTransaction implements wallet.ServerInterface.
+func (g *Gateway) PostExternalTransaction(ctx echo.Context) error {
+	resp, err := g.upstream(ctx).PostExternalTransactionWithBodyWithResponse(ctx.Request().Context(), contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetSettings implements wallet.ServerInterface.
+func (g *Gateway) GetSettings(ctx echo.Context) error {
+	resp, err := g.upstream(ctx).GetSettingsWithResponse(ctx.Request().Context())
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// PutSettings implements wallet.ServerInterface.
+func (g *Gateway) PutSettings(ctx echo.Context) error {
+	resp, err := g.upstream(ctx).PutSettingsWithBodyWithResponse(ctx.Request().Context(), contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// PostSharedWallet implements wallet.ServerInterface.
+func (g *Gateway) PostSharedWallet(ctx echo.Context) error {
+	resp, err := g.upstream(ctx).PostSharedWalletWithBodyWithResponse(ctx.Request().Context(), contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// DeleteSharedWallet implements wallet.ServerInterface.
+func (g *Gateway) DeleteSharedWallet(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).DeleteSharedWalletWithResponse(ctx.Request().Context(), walletId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetSharedWallet implements wallet.ServerInterface.
+func (g *Gateway) GetSharedWallet(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).GetSharedWalletWithResponse(ctx.Request().Context(), walletId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// PatchSharedWalletInDelegation implements wallet.ServerInterface.
+func (g *Gateway) PatchSharedWalletInDelegation(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).PatchSharedWalletInDelegationWithBodyWithResponse(ctx.Request().Context(), walletId, contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// PatchSharedWalletInPayment implements wallet.ServerInterface.
+func (g *Gateway) PatchSharedWalletInPayment(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).PatchSharedWalletInPaymentWithBodyWithResponse(ctx.Request().Context(), walletId, contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetCurrentSmashHealth implements wallet.ServerInterface.
+func (g *Gateway) GetCurrentSmashHealth(ctx echo.Context, params wallet.GetCurrentSmashHealthParams) error {
+	resp, err := g.upstream(ctx).GetCurrentSmashHealthWithResponse(ctx.Request().Context(), &params)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// ListStakePools implements wallet.ServerInterface.
+func (g *Gateway) ListStakePools(ctx echo.Context, params wallet.ListStakePoolsParams) error {
+	resp, err := g.upstream(ctx).ListStakePoolsWithResponse(ctx.Request().Context(), &params)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// QuitStakePool implements wallet.ServerInterface.
+func (g *Gateway) QuitStakePool(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).QuitStakePoolWithBodyWithResponse(ctx.Request().Context(), walletId, contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetMaintenanceActions implements wallet.ServerInterface.
+func (g *Gateway) GetMaintenanceActions(ctx echo.Context) error {
+	resp, err := g.upstream(ctx).GetMaintenanceActionsWithResponse(ctx.Request().Context())
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// PostMaintenanceAction implements wallet.ServerInterface.
+func (g *Gateway) PostMaintenanceAction(ctx echo.Context) error {
+	resp, err := g.upstream(ctx).PostMaintenanceActionWithBodyWithResponse(ctx.Request().Context(), contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// JoinStakePool implements wallet.ServerInterface.
+func (g *Gateway) JoinStakePool(ctx echo.Context, stakePoolId string, walletId string) error {
+	resp, err := g.upstream(ctx).JoinStakePoolWithBodyWithResponse(ctx.Request().Context(), stakePoolId, walletId, contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// ListWallets implements wallet.ServerInterface.
+func (g *Gateway) ListWallets(ctx echo.Context) error {
+	resp, err := g.upstream(ctx).ListWalletsWithResponse(ctx.Request().Context())
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respondWallets(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// PostWallet implements wallet.ServerInterface.
+func (g *Gateway) PostWallet(ctx echo.Context) error {
+	resp, err := g.upstream(ctx).PostWalletWithBodyWithResponse(ctx.Request().Context(), contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// DeleteWallet implements wallet.ServerInterface.
+func (g *Gateway) DeleteWallet(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).DeleteWalletWithResponse(ctx.Request().Context(), walletId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetWallet implements wallet.ServerInterface.
+func (g *Gateway) GetWallet(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).GetWalletWithResponse(ctx.Request().Context(), walletId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// PutWallet implements wallet.ServerInterface.
+func (g *Gateway) PutWallet(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).PutWalletWithBodyWithResponse(ctx.Request().Context(), walletId, contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// ListAddresses implements wallet.ServerInterface.
+func (g *Gateway) ListAddresses(ctx echo.Context, walletId string, params wallet.ListAddressesParams) error {
+	resp, err := g.upstream(ctx).ListAddressesWithResponse(ctx.Request().Context(), walletId, &params)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// ListAssets implements wallet.ServerInterface.
+func (g *Gateway) ListAssets(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).ListAssetsWithResponse(ctx.Request().Context(), walletId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetAssetDefault implements wallet.ServerInterface.
+func (g *Gateway) GetAssetDefault(ctx echo.Context, walletId string, policyId string) error {
+	resp, err := g.upstream(ctx).GetAssetDefaultWithResponse(ctx.Request().Context(), walletId, policyId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetAsset implements wallet.ServerInterface.
+func (g *Gateway) GetAsset(ctx echo.Context, walletId string, policyId string, assetName string) error {
+	resp, err := g.upstream(ctx).GetAssetWithResponse(ctx.Request().Context(), walletId, policyId, assetName)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// SelectCoins implements wallet.ServerInterface.
+func (g *Gateway) SelectCoins(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).SelectCoinsWithBodyWithResponse(ctx.Request().Context(), walletId, contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetDelegationFee implements wallet.ServerInterface.
+func (g *Gateway) GetDelegationFee(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).GetDelegationFeeWithResponse(ctx.Request().Context(), walletId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// PostAccountKey implements wallet.ServerInterface.
+func (g *Gateway) PostAccountKey(ctx echo.Context, walletId string, index string) error {
+	resp, err := g.upstream(ctx).PostAccountKeyWithBodyWithResponse(ctx.Request().Context(), walletId, index, contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetWalletKey implements wallet.ServerInterface.
+func (g *Gateway) GetWalletKey(ctx echo.Context, walletId string, role string, index string) error {
+	resp, err := g.upstream(ctx).GetWalletKeyWithResponse(ctx.Request().Context(), walletId, role, index)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetShelleyWalletMigrationInfo implements wallet.ServerInterface.
+func (g *Gateway) GetShelleyWalletMigrationInfo(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).GetShelleyWalletMigrationInfoWithResponse(ctx.Request().Context(), walletId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// MigrateShelleyWallet implements wallet.ServerInterface.
+func (g *Gateway) MigrateShelleyWallet(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).MigrateShelleyWalletWithBodyWithResponse(ctx.Request().Context(), walletId, contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// PutWalletPassphrase implements wallet.ServerInterface.
+func (g *Gateway) PutWalletPassphrase(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).PutWalletPassphraseWithBodyWithResponse(ctx.Request().Context(), walletId, contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// PostTransactionFee implements wallet.ServerInterface.
+func (g *Gateway) PostTransactionFee(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).PostTransactionFeeWithBodyWithResponse(ctx.Request().Context(), walletId, contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// SignMetadata implements wallet.ServerInterface.
+func (g *Gateway) SignMetadata(ctx echo.Context, walletId string, role string, index string) error {
+	resp, err := g.upstream(ctx).SignMetadataWithBodyWithResponse(ctx.Request().Context(), walletId, role, index, contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetUTxOsStatistics implements wallet.ServerInterface.
+func (g *Gateway) GetUTxOsStatistics(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).GetUTxOsStatisticsWithResponse(ctx.Request().Context(), walletId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// ListTransactions implements wallet.ServerInterface.
+func (g *Gateway) ListTransactions(ctx echo.Context, walletId string, params wallet.ListTransactionsParams) error {
+	resp, err := g.upstream(ctx).ListTransactionsWithResponse(ctx.Request().Context(), walletId, &params)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// PostTransaction implements wallet.ServerInterface.
+func (g *Gateway) PostTransaction(ctx echo.Context, walletId string) error {
+	resp, err := g.upstream(ctx).PostTransactionWithBodyWithResponse(ctx.Request().Context(), walletId, contentType(ctx), ctx.Request().Body)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// DeleteTransaction implements wallet.ServerInterface.
+func (g *Gateway) DeleteTransaction(ctx echo.Context, walletId string, transactionId string) error {
+	resp, err := g.upstream(ctx).DeleteTransactionWithResponse(ctx.Request().Context(), walletId, transactionId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
+
+// GetTransaction implements wallet.ServerInterface.
+func (g *Gateway) GetTransaction(ctx echo.Context, walletId string, transactionId string) error {
+	resp, err := g.upstream(ctx).GetTransactionWithResponse(ctx.Request().Context(), walletId, transactionId)
+	if err != nil {
+		return g.upstreamError(ctx, err)
+	}
+	return g.respond(ctx, resp.HTTPResponse, resp.Body)
+}
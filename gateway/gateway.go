@@ -0,0 +1,345 @@
+// Package gateway implements a multi-tenant HTTP gateway for the cardano-wallet API.
+//
+// The Gateway implements wallet.ServerInterface by forwarding all requests to an upstream cardano-wallet server.
+// Callers authenticate with API tokens, and each token restricts the allowed operations and wallets,
+// and limits the rate and concurrency of its requests. This way, teams can share a wallet backend without sharing
+// full control over it.
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/godano/cardano-wallet-client/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+// DefaultBaseURL is the path prefix of the API, the same as for cardano-wallet.
+const DefaultBaseURL = "/v2"
+
+// Error codes of responses of the gateway itself, in addition to the codes returned by cardano-wallet
+const (
+	ErrorMissingToken        = "missing_token"
+	ErrorInvalidToken        = "invalid_token"
+	ErrorForbiddenOperation  = "forbidden_operation"
+	ErrorForbiddenWallet     = "forbidden_wallet"
+	ErrorRateLimited         = "rate_limited"
+	ErrorUpstreamTimeout     = "upstream_timeout"
+	ErrorUpstreamUnavailable = "upstream_unavailable"
+)
+
+// Upstream returns a new client for the upstream cardano-wallet server, with the given options applied after the
+// options of the connection. Every token gets its own client, so that its limits are independent of other tokens.
+// Profile.NewClientWithResponses is an Upstream.
+type Upstream func(opts ...wallet.ClientOption) (*wallet.ClientWithResponses, error)
+
+// RequestLogEntry describes a request handled by the gateway, see WithRequestLog().
+type RequestLogEntry struct {
+	Time       time.Time
+	RemoteAddr string
+	// Caller is the name of the authenticated token, empty if the authentication failed
+	Caller string
+	// Operation is the operation Id, empty if the request does not match any operation
+	Operation string
+	WalletId  string
+	Method    string
+	Path      string
+	Status    int
+	Duration  time.Duration
+	// Error is the error code of responses rejected by the gateway, like ErrorForbiddenWallet
+	Error string
+}
+
+// Option configures New.
+type Option func(*Gateway)
+
+// WithBaseURL sets the path prefix of the API, the default is DefaultBaseURL.
+func WithBaseURL(baseURL string) Option {
+	return func(g *Gateway) {
+		g.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithRequestLog sets a callback, which is called after every request. The callback is called synchronously.
+func WithRequestLog(callback func(*RequestLogEntry)) Option {
+	return func(g *Gateway) {
+		g.requestLog = callback
+	}
+}
+
+// WithAuditLog records all mutating requests in the given audit log, with the token name as caller,
+// see wallet.WithAuditLog(). Requests rejected by the gateway or by the limits of a token are not recorded.
+func WithAuditLog(log *wallet.AuditLog) Option {
+	return func(g *Gateway) {
+		g.upstreamOptions = append(g.upstreamOptions, wallet.WithAuditLog(log))
+	}
+}
+
+// Gateway forwards requests of authenticated callers to the upstream cardano-wallet server. It is an http.Handler.
+type Gateway struct {
+	tokens     []*tokenState
+	baseURL    string
+	requestLog func(*RequestLogEntry)
+	echo       *echo.Echo
+
+	// upstreamOptions are applied before the options of the tokens
+	upstreamOptions []wallet.ClientOption
+}
+
+type tokenState struct {
+	*Token
+	hash       [sha256.Size]byte
+	operations map[string]bool
+	wallets    map[string]bool
+	client     *wallet.ClientWithResponses
+}
+
+// Keys of values stored in the echo.Context
+const (
+	tokenKey      = "gateway.token"
+	errorCodeKey  = "gateway.error"
+	operationKey  = "gateway.operation"
+	walletIdParam = "walletId"
+)
+
+// New returns a Gateway for the tokens of the validated config. The upstream clients of all tokens are created
+// immediately, but they do not connect before the first request.
+func New(config *Config, upstream Upstream, opts ...Option) (*Gateway, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	g := &Gateway{baseURL: DefaultBaseURL}
+	for _, opt := range opts {
+		opt(g)
+	}
+	for _, token := range config.Tokens {
+		state := &tokenState{Token: token, operations: stringSet(token.Operations), wallets: stringSet(token.Wallets)}
+		state.hash, _ = token.hash() // Checked by Validate()
+		clientOpts := append(append([]wallet.ClientOption{}, g.upstreamOptions...), token.clientOptions()...)
+		client, err := upstream(clientOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to create upstream client for token %v: %v", token.Name, err)
+		}
+		state.client = client
+		g.tokens = append(g.tokens, state)
+	}
+
+	g.echo = echo.New()
+	g.echo.HideBanner, g.echo.HidePort = true, true
+	g.echo.HTTPErrorHandler = g.handleError
+	g.echo.Use(g.logRequests, g.authenticate, g.authorize)
+	wallet.RegisterHandlersWithBaseURL(g.echo, g, g.baseURL)
+	return g, nil
+}
+
+func stringSet(values []string) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+	result := make(map[string]bool, len(values))
+	for _, value := range values {
+		result[value] = true
+	}
+	return result
+}
+
+// ServeHTTP implements http.Handler.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	g.echo.ServeHTTP(w, req)
+}
+
+// apiError has the format of the error responses of cardano-wallet.
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (g *Gateway) reject(ctx echo.Context, status int, code string, format string, args ...interface{}) error {
+	ctx.Set(errorCodeKey, code)
+	return ctx.JSON(status, &apiError{Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// handleError responds to errors of the echo router and of the generated parameter parsing.
+func (g *Gateway) handleError(err error, ctx echo.Context) {
+	if ctx.Response().Committed {
+		return
+	}
+	status := http.StatusInternalServerError
+	message := err.Error()
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		status = httpErr.Code
+		message = fmt.Sprint(httpErr.Message)
+	}
+	code := strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+	g.reject(ctx, status, code, "%v", message)
+}
+
+func (g *Gateway) logRequests(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		start := time.Now()
+		if err := next(ctx); err != nil {
+			ctx.Error(err)
+		}
+		if g.requestLog == nil {
+			return nil
+		}
+		req := ctx.Request()
+		entry := &RequestLogEntry{
+			Time:       start,
+			RemoteAddr: req.RemoteAddr,
+			WalletId:   ctx.Param(walletIdParam),
+			Method:     req.Method,
+			Path:       req.URL.Path,
+			Status:     ctx.Response().Status,
+			Duration:   time.Since(start),
+		}
+		if token, ok := ctx.Get(tokenKey).(*tokenState); ok {
+			entry.Caller = token.Name
+		}
+		if op, ok := ctx.Get(operationKey).(*wallet.Operation); ok {
+			entry.Operation = op.Id
+		}
+		if code, ok := ctx.Get(errorCodeKey).(string); ok {
+			entry.Error = code
+		}
+		g.requestLog(entry)
+		return nil
+	}
+}
+
+// authenticate finds the token of the "Authorization: Bearer <token>" header. All tokens are compared in constant time.
+func (g *Gateway) authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		header := ctx.Request().Header.Get(echo.HeaderAuthorization)
+		const prefix = "bearer "
+		if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+			ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+			return g.reject(ctx, http.StatusUnauthorized, ErrorMissingToken, "Missing header Authorization: Bearer <token>")
+		}
+		hash := sha256.Sum256([]byte(strings.TrimSpace(header[len(prefix):])))
+		var found *tokenState
+		for _, token := range g.tokens {
+			if subtle.ConstantTimeCompare(hash[:], token.hash[:]) == 1 {
+				found = token
+			}
+		}
+		if found == nil {
+			ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+			return g.reject(ctx, http.StatusUnauthorized, ErrorInvalidToken, "Invalid token")
+		}
+		ctx.Set(tokenKey, found)
+		req := ctx.Request()
+		ctx.SetRequest(req.WithContext(wallet.ContextWithCaller(req.Context(), found.Name)))
+		return next(ctx)
+	}
+}
+
+// authorize checks the allowlists of the token. Requests, which do not match any operation, are passed on to the
+// router, which rejects them.
+func (g *Gateway) authorize(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		token := ctx.Get(tokenKey).(*tokenState)
+		op := wallet.OperationForRequest(ctx.Request())
+		if op == nil {
+			return next(ctx)
+		}
+		ctx.Set(operationKey, op)
+		if token.ReadOnly && !wallet.IsReadOnlyOperation(op.Id) {
+			return g.reject(ctx, http.StatusForbidden, ErrorForbiddenOperation, "Token %v is read-only, %v is not allowed", token.Name, op.Id)
+		}
+		if token.operations != nil && !token.operations[op.Id] {
+			return g.reject(ctx, http.StatusForbidden, ErrorForbiddenOperation, "Token %v is not allowed to call %v", token.Name, op.Id)
+		}
+		walletId := ctx.Param(walletIdParam)
+		if token.wallets != nil && walletId != "" && !token.wallets[walletId] {
+			return g.reject(ctx, http.StatusForbidden, ErrorForbiddenWallet, "Token %v is not allowed to access wallet %v", token.Name, walletId)
+		}
+		// Mutating operations, which do not refer to a wallet, could affect or create other wallets
+		if token.wallets != nil && walletId == "" && !wallet.IsReadOnlyOperation(op.Id) && !token.operations[op.Id] {
+			return g.reject(ctx, http.StatusForbidden, ErrorForbiddenOperation, "Token %v is restricted to wallets, %v must be allowed explicitly", token.Name, op.Id)
+		}
+		if token.MaxWait > 0 {
+			// The context is canceled after MaxWait, unless the upstream request passed the limits before
+			req := ctx.Request()
+			waitCtx, cancel := context.WithCancel(req.Context())
+			defer cancel()
+			timer := time.AfterFunc(time.Duration(token.MaxWait), cancel)
+			ctx.SetRequest(req.WithContext(context.WithValue(waitCtx, waitTimerKey{}, timer)))
+		}
+		return next(ctx)
+	}
+}
+
+type waitTimerKey struct{}
+
+// withEndOfWait returns a ClientOption, which stops the MaxWait timer of requests, see Token.MaxWait.
+func withEndOfWait() wallet.ClientOption {
+	return wallet.WithMiddleware(wallet.MiddlewareFunc(func(req *http.Request, next wallet.HttpRequestDoer) (*http.Response, error) {
+		if timer, ok := req.Context().Value(waitTimerKey{}).(*time.Timer); ok {
+			timer.Stop()
+		}
+		return next.Do(req)
+	}))
+}
+
+// upstream returns the client of the authenticated token.
+func (g *Gateway) upstream(ctx echo.Context) *wallet.ClientWithResponses {
+	return ctx.Get(tokenKey).(*tokenState).client
+}
+
+func contentType(ctx echo.Context) string {
+	return ctx.Request().Header.Get(echo.HeaderContentType)
+}
+
+// respond copies the upstream response to the caller.
+func (g *Gateway) respond(ctx echo.Context, resp *http.Response, body []byte) error {
+	if len(body) == 0 {
+		return ctx.NoContent(resp.StatusCode)
+	}
+	return ctx.Blob(resp.StatusCode, resp.Header.Get(echo.HeaderContentType), body)
+}
+
+// respondWallets removes the wallets, which are not allowed for the token, from the result of ListWallets or
+// ListByronWallets.
+func (g *Gateway) respondWallets(ctx echo.Context, resp *http.Response, body []byte) error {
+	token := ctx.Get(tokenKey).(*tokenState)
+	if token.wallets == nil || resp.StatusCode != http.StatusOK {
+		return g.respond(ctx, resp, body)
+	}
+	var wallets []json.RawMessage
+	if err := json.Unmarshal(body, &wallets); err != nil {
+		return g.upstreamError(ctx, fmt.Errorf("Failed to parse wallets: %v", err))
+	}
+	allowed := make([]json.RawMessage, 0, len(wallets))
+	for _, w := range wallets {
+		var id struct {
+			Id string `json:"id"`
+		}
+		if err := json.Unmarshal(w, &id); err == nil && token.wallets[id.Id] {
+			allowed = append(allowed, w)
+		}
+	}
+	return ctx.JSON(http.StatusOK, allowed)
+}
+
+// upstreamError responds to failed upstream requests.
+func (g *Gateway) upstreamError(ctx echo.Context, err error) error {
+	var limitErr *wallet.RequestLimitError
+	var timeoutErr *wallet.OperationTimeoutError
+	switch {
+	case errors.As(err, &limitErr):
+		ctx.Response().Header().Set("Retry-After", "1")
+		return g.reject(ctx, http.StatusTooManyRequests, ErrorRateLimited, "%v", err)
+	case errors.As(err, &timeoutErr), errors.Is(err, context.DeadlineExceeded):
+		return g.reject(ctx, http.StatusGatewayTimeout, ErrorUpstreamTimeout, "%v", err)
+	}
+	return g.reject(ctx, http.StatusBadGateway, ErrorUpstreamUnavailable, "%v", err)
+}
@@ -0,0 +1,285 @@
+package gateway
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godano/cardano-wallet-client/wallet"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type GatewayTestSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	upstream *httptest.Server
+	gateway  *httptest.Server
+	url      string // The gateway used by call()
+
+	lock     sync.Mutex
+	received []*http.Request
+	bodies   []string
+	log      []*RequestLogEntry
+}
+
+func TestGateway(t *testing.T) {
+	testSuite := new(GatewayTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *GatewayTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+	s.upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		s.lock.Lock()
+		s.received = append(s.received, r)
+		s.bodies = append(s.bodies, string(body))
+		s.lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v2/wallets":
+			w.Write([]byte(`[{"id": "w1", "name": "One"}, {"id": "w2", "name": "Two"}]`))
+		case "/v2/wallets/w1":
+			w.Write([]byte(`{"id": "w1", "name": "One"}`))
+		case "/v2/wallets/w1/transactions":
+			w.Write([]byte(`[]`))
+		case "/v2/wallets/w1/payment-fees":
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"estimated_min": {"quantity": 1, "unit": "lovelace"}}`))
+		case "/v2/network/information":
+			w.Write([]byte(`{}`))
+		case "/v2/settings":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code": "no_such_wallet", "message": "Not found"}`))
+		}
+	}))
+
+	config := &Config{Tokens: []*Token{
+		{Name: "admin", Token: "admin-secret"},
+		{Name: "reader", Token: "reader-secret", ReadOnly: true, Wallets: []string{"w1"}},
+		{Name: "network", TokenSHA256: "bae19ad03c8b830d8fc25a7231bd09e747db8f906944b5c407a1a10d466ad064",
+			Operations: []string{"GetNetworkInformation"}},
+		{Name: "payments", Token: "payments-secret", Wallets: []string{"w1"}},
+		{Name: "settings", Token: "settings-secret", Wallets: []string{"w1"}, Operations: []string{"GetWallet", "PutSettings"}},
+		{Name: "limited", Token: "limited-secret", RateLimit: 0.1, Burst: 1, MaxWait: wallet.Duration(10 * time.Millisecond)},
+	}}
+	gateway, err := New(config, func(opts ...wallet.ClientOption) (*wallet.ClientWithResponses, error) {
+		return wallet.NewClientWithResponses(s.upstream.URL+"/v2", opts...)
+	}, WithRequestLog(func(entry *RequestLogEntry) {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		s.log = append(s.log, entry)
+	}))
+	s.NoError(err)
+	s.gateway = httptest.NewServer(gateway)
+	s.url = s.gateway.URL
+}
+
+func (s *GatewayTestSuite) TearDownSuite() {
+	s.gateway.Close()
+	s.upstream.Close()
+}
+
+func (s *GatewayTestSuite) SetupTest() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.received, s.bodies, s.log = nil, nil, nil
+	s.url = s.gateway.URL
+}
+
+// call sends a request to the gateway and returns the status and the response body.
+func (s *GatewayTestSuite) call(token, method, path, body string) (int, string) {
+	req, err := http.NewRequest(method, s.url+path, strings.NewReader(body))
+	s.NoError(err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	s.NoError(err)
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	s.NoError(err)
+	return resp.StatusCode, string(respBody)
+}
+
+func (s *GatewayTestSuite) errorCode(body string) string {
+	var apiErr apiError
+	s.NoError(json.Unmarshal([]byte(body), &apiErr))
+	return apiErr.Code
+}
+
+func (s *GatewayTestSuite) TestAuthentication() {
+	status, body := s.call("", http.MethodGet, "/v2/wallets", "")
+	s.Equal(http.StatusUnauthorized, status)
+	s.Equal(ErrorMissingToken, s.errorCode(body))
+	status, body = s.call("wrong", http.MethodGet, "/v2/wallets", "")
+	s.Equal(http.StatusUnauthorized, status)
+	s.Equal(ErrorInvalidToken, s.errorCode(body))
+	// Unknown paths are only revealed to authenticated callers
+	status, body = s.call("", http.MethodGet, "/v2/unknown", "")
+	s.Equal(http.StatusUnauthorized, status)
+	status, body = s.call("admin-secret", http.MethodGet, "/v2/unknown", "")
+	s.Equal(http.StatusNotFound, status)
+	s.Equal("not_found", s.errorCode(body))
+	s.Empty(s.received)
+}
+
+func (s *GatewayTestSuite) TestForwarding() {
+	status, body := s.call("admin-secret", http.MethodGet, "/v2/wallets", "")
+	s.Equal(http.StatusOK, status)
+	s.JSONEq(`[{"id": "w1", "name": "One"}, {"id": "w2", "name": "Two"}]`, body)
+
+	status, body = s.call("admin-secret", http.MethodGet, "/v2/wallets/w1/transactions?order=ascending", "")
+	s.Equal(http.StatusOK, status)
+	s.Equal("ascending", s.received[1].URL.Query().Get("order"))
+
+	// Error responses of the upstream server are forwarded
+	status, body = s.call("admin-secret", http.MethodDelete, "/v2/wallets/w2", "")
+	s.Equal(http.StatusNotFound, status)
+	s.Equal("no_such_wallet", s.errorCode(body))
+
+	s.Len(s.log, 3)
+	s.Equal(RequestLogEntry{Time: s.log[2].Time, RemoteAddr: s.log[2].RemoteAddr, Caller: "admin", Operation: "DeleteWallet",
+		WalletId: "w2", Method: http.MethodDelete, Path: "/v2/wallets/w2", Status: 404, Duration: s.log[2].Duration}, *s.log[2])
+}
+
+func (s *GatewayTestSuite) TestAllowlists() {
+	status, body := s.call("reader-secret", http.MethodGet, "/v2/wallets", "")
+	s.Equal(http.StatusOK, status)
+	s.JSONEq(`[{"id": "w1", "name": "One"}]`, body)
+
+	status, body = s.call("reader-secret", http.MethodGet, "/v2/wallets/w2", "")
+	s.Equal(http.StatusForbidden, status)
+	s.Equal(ErrorForbiddenWallet, s.errorCode(body))
+	status, body = s.call("reader-secret", http.MethodDelete, "/v2/wallets/w1", "")
+	s.Equal(http.StatusForbidden, status)
+	s.Equal(ErrorForbiddenOperation, s.errorCode(body))
+	s.Equal(ErrorForbiddenOperation, s.log[2].Error)
+
+	// Read-only tokens may estimate fees, the body is forwarded
+	status, body = s.call("reader-secret", http.MethodPost, "/v2/wallets/w1/payment-fees", `{"payments": []}`)
+	s.Equal(http.StatusAccepted, status)
+	s.Equal(`{"payments": []}`, s.bodies[1])
+
+	// The SHA-256 hash of "network-secret"
+	status, _ = s.call("network-secret", http.MethodGet, "/v2/network/information", "")
+	s.Equal(http.StatusOK, status)
+	status, body = s.call("network-secret", http.MethodGet, "/v2/wallets", "")
+	s.Equal(http.StatusForbidden, status)
+	s.Equal(ErrorForbiddenOperation, s.errorCode(body))
+	s.Len(s.received, 3)
+}
+
+func (s *GatewayTestSuite) TestWalletAllowlist() {
+	status, _ := s.call("payments-secret", http.MethodGet, "/v2/wallets/w1", "")
+	s.Equal(http.StatusOK, status)
+	status, _ = s.call("payments-secret", http.MethodGet, "/v2/network/information", "")
+	s.Equal(http.StatusOK, status)
+
+	// Mutating operations without a wallet id could affect other wallets
+	status, body := s.call("payments-secret", http.MethodPut, "/v2/settings", `{"settings": {}}`)
+	s.Equal(http.StatusForbidden, status)
+	s.Equal(ErrorForbiddenOperation, s.errorCode(body))
+	status, body = s.call("payments-secret", http.MethodPost, "/v2/wallets", `{"name": "Three"}`)
+	s.Equal(http.StatusForbidden, status)
+	s.Equal(ErrorForbiddenOperation, s.errorCode(body))
+	s.Len(s.received, 2)
+
+	// unless they are allowed explicitly
+	status, _ = s.call("settings-secret", http.MethodPut, "/v2/settings", `{"settings": {}}`)
+	s.Equal(http.StatusNoContent, status)
+	s.Len(s.received, 3)
+}
+
+func (s *GatewayTestSuite) TestRateLimit() {
+	status, _ := s.call("limited-secret", http.MethodGet, "/v2/wallets/w1", "")
+	s.Equal(http.StatusOK, status)
+	status, body := s.call("limited-secret", http.MethodGet, "/v2/wallets/w1", "")
+	s.Equal(http.StatusTooManyRequests, status)
+	s.Equal(ErrorRateLimited, s.errorCode(body))
+	// The limits of other tokens are independent
+	status, _ = s.call("admin-secret", http.MethodGet, "/v2/wallets/w1", "")
+	s.Equal(http.StatusOK, status)
+	s.Len(s.received, 2)
+}
+
+func (s *GatewayTestSuite) TestConfig() {
+	dir, err := ioutil.TempDir("", "gateway")
+	s.NoError(err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "gateway.yaml")
+	s.NoError(os.Setenv("GATEWAY_TEST_TOKEN", "secret"))
+	defer os.Unsetenv("GATEWAY_TEST_TOKEN")
+	s.NoError(ioutil.WriteFile(file, []byte(`
+tokens:
+  - name: ops
+    token_env: GATEWAY_TEST_TOKEN
+    read_only: true
+    wallets: [w1]
+    rate_limit: 2.5
+    max_wait: 1s
+`), 0600))
+	config, err := LoadConfigFile(file)
+	s.NoError(err)
+	s.Equal(&Token{Name: "ops", TokenEnv: "GATEWAY_TEST_TOKEN", ReadOnly: true, Wallets: []string{"w1"},
+		RateLimit: 2.5, MaxWait: wallet.Duration(time.Second)}, config.Tokens[0])
+
+	for _, invalid := range []*Config{
+		{},
+		{Tokens: []*Token{{Token: "a"}}},
+		{Tokens: []*Token{{Name: "a"}}},
+		{Tokens: []*Token{{Name: "a", Token: "a", TokenEnv: "GATEWAY_TEST_TOKEN"}}},
+		{Tokens: []*Token{{Name: "a", TokenEnv: "GATEWAY_TEST_UNSET"}}},
+		{Tokens: []*Token{{Name: "a", TokenSHA256: "abc"}}},
+		{Tokens: []*Token{{Name: "a", Token: "a"}, {Name: "a", Token: "b"}}},
+		{Tokens: []*Token{{Name: "a", Token: "secret"}, {Name: "b", TokenEnv: "GATEWAY_TEST_TOKEN"}}},
+		{Tokens: []*Token{{Name: "a", Token: "a", Operations: []string{"NoSuchOperation"}}}},
+		{Tokens: []*Token{{Name: "a", Token: "a", RateLimit: -1}}},
+	} {
+		s.Error(invalid.Validate())
+	}
+}
+
+func (s *GatewayTestSuite) TestAuditLog() {
+	dir, err := ioutil.TempDir("", "gateway")
+	s.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.jsonl")
+	log, err := wallet.OpenAuditLog(path, nil)
+	s.NoError(err)
+	defer log.Close()
+	config := &Config{Tokens: []*Token{{Name: "admin", Token: "admin-secret"}, {Name: "reader", Token: "reader-secret", ReadOnly: true}}}
+	gateway, err := New(config, func(opts ...wallet.ClientOption) (*wallet.ClientWithResponses, error) {
+		return wallet.NewClientWithResponses(s.upstream.URL+"/v2", opts...)
+	}, WithAuditLog(log))
+	s.NoError(err)
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+	s.url = server.URL
+
+	status, _ := s.call("admin-secret", http.MethodDelete, "/v2/wallets/w2", "")
+	s.Equal(http.StatusNotFound, status)
+	status, _ = s.call("reader-secret", http.MethodDelete, "/v2/wallets/w2", "")
+	s.Equal(http.StatusForbidden, status)
+	status, _ = s.call("admin-secret", http.MethodGet, "/v2/wallets/w1", "")
+	s.Equal(http.StatusOK, status)
+
+	summary, err := wallet.VerifyAuditLog(path, nil, nil)
+	s.NoError(err)
+	s.Equal(1, summary.Records)
+	data, err := ioutil.ReadFile(path)
+	s.NoError(err)
+	s.Contains(string(data), `"caller":"admin","operation":"DeleteWallet","wallet_id":"w2","status":404`)
+}
@@ -24,6 +24,9 @@ generate server # Server not strictly necessary, but included for completeness
 # Fix errors in the generated code - remove invalid type name prefixes
 sed -i -e 's/200_//g' -e 's/202_//g' wallet/*.go
 
+# Keep the middlewares of the client, when WithHTTPClient() is given after them (see wallet/middleware.go)
+sed -i -e 's/^\(\s*\)c\.Client = doer$/\1setHTTPClient(c, doer)/' wallet/generated-client.go
+
 # Format code and fix imports
 goimports -w wallet/*.go
 gofumpt -w wallet/*.go
@@ -3,6 +3,7 @@ module github.com/godano/cardano-wallet-client
 go 1.14
 
 require (
+	filippo.io/edwards25519 v1.0.0-beta.3
 	github.com/deepmap/oapi-codegen v1.6.1
 	github.com/getkin/kin-openapi v0.55.0
 	github.com/ghodss/yaml v1.0.0
@@ -12,4 +13,5 @@ require (
 	github.com/spf13/cobra v1.1.3
 	github.com/spf13/pflag v1.0.5
 	github.com/stretchr/testify v1.7.0
+	golang.org/x/crypto v0.0.0-20201221181555-eec23a3978ad
 )
@@ -0,0 +1,44 @@
+package hdkey
+
+import (
+	"github.com/godano/cardano-wallet-client/address"
+)
+
+// Credential returns the key hash credential of the key, for use in addresses.
+func (x *XPub) Credential() *address.Credential {
+	return &address.Credential{Kind: address.KeyHash, Hash: x.KeyHash()}
+}
+
+// BaseAddress derives the payment key for the role and index from an account key, and returns the base address
+// with the stake key of the account (mutable_account/0). This matches the addresses listed by the cardano-wallet
+// for Shelley wallets, and the format returned by PostAnyAddress.
+func (x *XPub) BaseAddress(networkId uint32, role Role, index uint32) (*address.Address, error) {
+	payment, err := x.Derive(role, index)
+	if err != nil {
+		return nil, err
+	}
+	stake, err := x.Derive(RoleMutableAccount, 0)
+	if err != nil {
+		return nil, err
+	}
+	return address.NewBaseAddress(networkId, payment.Credential(), stake.Credential())
+}
+
+// EnterpriseAddress derives the payment key for the role and index from an account key, and returns
+// the enterprise address, which has no stake rights.
+func (x *XPub) EnterpriseAddress(networkId uint32, role Role, index uint32) (*address.Address, error) {
+	payment, err := x.Derive(role, index)
+	if err != nil {
+		return nil, err
+	}
+	return address.NewEnterpriseAddress(networkId, payment.Credential())
+}
+
+// RewardAddress returns the reward address for the stake key of an account key (mutable_account/0).
+func (x *XPub) RewardAddress(networkId uint32) (*address.Address, error) {
+	stake, err := x.Derive(RoleMutableAccount, 0)
+	if err != nil {
+		return nil, err
+	}
+	return address.NewRewardAddress(networkId, stake.Credential())
+}
@@ -0,0 +1,199 @@
+// Package hdkey derives public keys and addresses from an extended account public key, without access to private keys.
+//
+// The derivation follows BIP32-Ed25519 as used by Icarus and Shelley wallets (CIP-1852). Only non-hardened
+// child keys can be derived from a public key, which covers the role and index levels below the account level:
+// m/1852'/1815'/account'/role/index.
+package hdkey
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"filippo.io/edwards25519"
+	"github.com/godano/cardano-wallet-client/bech32"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// HardenedOffset is added to indices of hardened keys, which cannot be derived from public keys.
+	HardenedOffset = 0x80000000
+
+	// KeyHashSize is the size of the key hashes used in addresses (blake2b-224).
+	KeyHashSize = 28
+
+	keySize       = 32
+	chainCodeSize = 32
+	zlSize        = 28
+
+	// Bech32 prefixes of account public keys, see CIP-5
+	AccountXPubPrefix = "acct_xvk"
+	AccountPubPrefix  = "acct_vk"
+)
+
+// Role is the derivation level below the account, see CIP-1852.
+type Role uint32
+
+const (
+	RoleUTxOExternal   Role = 0 // Receiving addresses
+	RoleUTxOInternal   Role = 1 // Change addresses
+	RoleMutableAccount Role = 2 // Stake key
+)
+
+// roleNames are the role names used by the GetWalletKey operation.
+var roleNames = map[Role]string{
+	RoleUTxOExternal:   "utxo_external",
+	RoleUTxOInternal:   "utxo_internal",
+	RoleMutableAccount: "mutable_account",
+}
+
+func (r Role) String() string {
+	if name, ok := roleNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("role(%d)", uint32(r))
+}
+
+// ParseRole parses the role names of the GetWalletKey operation (utxo_external, utxo_internal, mutable_account).
+func ParseRole(name string) (Role, error) {
+	for role, roleName := range roleNames {
+		if roleName == name {
+			return role, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown role: %v", name)
+}
+
+// XPub is an extended Ed25519 public key, consisting of the public key and the chain code.
+type XPub struct {
+	Key       [keySize]byte
+	ChainCode [chainCodeSize]byte
+}
+
+// ParseXPub parses an extended public key, encoded as Bech32 (e.g. acct_xvk...) or as hex string of 64 bytes.
+func ParseXPub(key string) (*XPub, error) {
+	var data []byte
+	if decoded, err := hex.DecodeString(key); err == nil {
+		data = decoded
+	} else {
+		hrp, decoded, err := bech32.Decode(key)
+		if err != nil {
+			return nil, fmt.Errorf("Extended public key is neither hex nor Bech32: %v", err)
+		}
+		if !strings.HasSuffix(hrp, "_xvk") {
+			return nil, fmt.Errorf("Unexpected Bech32 prefix %v for extended public key", hrp)
+		}
+		data = decoded
+	}
+	return NewXPub(data)
+}
+
+// NewXPub returns an extended public key from 64 bytes: the public key followed by the chain code.
+func NewXPub(data []byte) (*XPub, error) {
+	if len(data) != keySize+chainCodeSize {
+		return nil, fmt.Errorf("Unexpected extended public key length %v, expected %v", len(data), keySize+chainCodeSize)
+	}
+	if _, err := new(edwards25519.Point).SetBytes(data[:keySize]); err != nil {
+		return nil, fmt.Errorf("Invalid public key: %v", err)
+	}
+	result := new(XPub)
+	copy(result.Key[:], data[:keySize])
+	copy(result.ChainCode[:], data[keySize:])
+	return result, nil
+}
+
+// Bytes returns the public key followed by the chain code.
+func (x *XPub) Bytes() []byte {
+	return append(append([]byte{}, x.Key[:]...), x.ChainCode[:]...)
+}
+
+// Bech32 returns the extended key with the given Bech32 prefix, for example AccountXPubPrefix.
+func (x *XPub) Bech32(prefix string) string {
+	encoded, err := bech32.Encode(prefix, x.Bytes())
+	if err != nil {
+		panic(err) // Only fails for strings exceeding the maximum length
+	}
+	return encoded
+}
+
+// PublicKey returns the Ed25519 public key without chain code.
+func (x *XPub) PublicKey() ed25519.PublicKey {
+	return append(ed25519.PublicKey{}, x.Key[:]...)
+}
+
+// KeyHash returns the blake2b-224 hash of the public key, as used for credentials in addresses.
+func (x *XPub) KeyHash() []byte {
+	hash, _ := blake2b.New(KeyHashSize, nil) // Only fails for invalid sizes
+	hash.Write(x.Key[:])
+	return hash.Sum(nil)
+}
+
+// Child derives the non-hardened child key with the given index.
+func (x *XPub) Child(index uint32) (*XPub, error) {
+	if index >= HardenedOffset {
+		return nil, fmt.Errorf("Cannot derive hardened index %v from a public key", index)
+	}
+	var indexBytes [4]byte
+	binary.LittleEndian.PutUint32(indexBytes[:], index)
+
+	z := hmacSHA512(x.ChainCode[:], []byte{0x02}, x.Key[:], indexBytes[:])
+	scalar, err := edwards25519.NewScalar().SetCanonicalBytes(mul8(z[:zlSize]))
+	if err != nil {
+		return nil, err
+	}
+	parent, err := new(edwards25519.Point).SetBytes(x.Key[:])
+	if err != nil {
+		return nil, err
+	}
+	childPoint := new(edwards25519.Point).ScalarBaseMult(scalar)
+	childPoint.Add(childPoint, parent)
+
+	result := new(XPub)
+	copy(result.Key[:], childPoint.Bytes())
+	chainCode := hmacSHA512(x.ChainCode[:], []byte{0x03}, x.Key[:], indexBytes[:])
+	copy(result.ChainCode[:], chainCode[chainCodeSize:])
+	return result, nil
+}
+
+// DerivePath derives a child key through multiple levels of non-hardened indices.
+func (x *XPub) DerivePath(indices ...uint32) (*XPub, error) {
+	result := x
+	for _, index := range indices {
+		var err error
+		result, err = result.Child(index)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Derive derives the key for the given role and index from an account key. The result is the same key
+// that the GetWalletKey operation returns for the role and index.
+func (x *XPub) Derive(role Role, index uint32) (*XPub, error) {
+	return x.DerivePath(uint32(role), index)
+}
+
+// mul8 returns the 28 byte little-endian number multiplied by 8, as 32 byte little-endian number.
+func mul8(zl []byte) []byte {
+	result := make([]byte, 32)
+	var carry byte
+	for i, b := range zl {
+		result[i] = b<<3 | carry
+		carry = b >> 5
+	}
+	result[len(zl)] = carry
+	return result
+}
+
+func hmacSHA512(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(sha512.New, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
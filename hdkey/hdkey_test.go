@@ -0,0 +1,126 @@
+package hdkey
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"filippo.io/edwards25519"
+	"github.com/godano/cardano-wallet-client/address"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// testAccountXPub is the account key m/1852'/1815'/0' of the mnemonic used for the CIP-19 test vectors:
+// "test walk nut penalty hip pave soap entry language right filter choice"
+const testAccountXPub = "acct_xvk1eame4ge0x5yrwpuqs5eyw89kfmjpgfkfh02xzdx6c2k9k2swcr5clf0u634tm82x6nv2j750x3j7938g70ya4k0lv6pr59s7etw2vpqgfmule"
+
+type HDKeyTestSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestHDKey(t *testing.T) {
+	testSuite := new(HDKeyTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *HDKeyTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+}
+
+func (s *HDKeyTestSuite) TestCIP19PaymentKey() {
+	account, err := ParseXPub(testAccountXPub)
+	s.NoError(err)
+	s.Equal(testAccountXPub, account.Bech32(AccountXPubPrefix))
+
+	addr, err := account.BaseAddress(address.NetworkMainnet, RoleUTxOExternal, 0)
+	s.NoError(err)
+	s.Equal("9493315cd92eb5d8c4304e67b7e16ae36d61d34502694657811a2c8e", hex.EncodeToString(addr.Payment.Hash))
+	s.True(strings.HasPrefix(addr.String(), "addr1qx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3"))
+
+	fromHex, err := ParseXPub(hex.EncodeToString(account.Bytes()))
+	s.NoError(err)
+	s.Equal(account, fromHex)
+}
+
+func (s *HDKeyTestSuite) TestHardenedIndex() {
+	account, err := ParseXPub(testAccountXPub)
+	s.NoError(err)
+	_, err = account.Child(HardenedOffset)
+	s.Error(err)
+}
+
+func (s *HDKeyTestSuite) TestParseRole() {
+	for _, name := range []string{"utxo_external", "utxo_internal", "mutable_account"} {
+		role, err := ParseRole(name)
+		s.NoError(err)
+		s.Equal(name, role.String())
+	}
+	_, err := ParseRole("unknown")
+	s.Error(err)
+}
+
+// TestPrivateDerivation checks that public derivation results in the public keys of privately derived keys.
+func (s *HDKeyTestSuite) TestPrivateDerivation() {
+	seed := pbkdf2.Key(nil, []byte("godano test seed"), 4096, 96, sha512.New)
+	seed[0] &= 0xf8
+	seed[31] &= 0x1f
+	seed[31] |= 0x40
+	root := &testXPrv{kl: seed[:32], kr: seed[32:64], cc: seed[64:]}
+	account := root.child(HardenedOffset + 1852).child(HardenedOffset + 1815).child(HardenedOffset)
+
+	accountPub, err := NewXPub(account.xpub())
+	s.NoError(err)
+	for _, path := range [][]uint32{{0, 0}, {0, 1}, {1, 0}, {2, 0}, {0, 1000000}} {
+		derived, err := accountPub.DerivePath(path...)
+		s.NoError(err)
+		expected := account.child(path[0]).child(path[1])
+		s.Equal(expected.xpub(), derived.Bytes(), "path %v", path)
+	}
+}
+
+// testXPrv implements private BIP32-Ed25519 derivation for testing.
+type testXPrv struct {
+	kl, kr, cc []byte
+}
+
+func (k *testXPrv) publicKey() []byte {
+	wide := make([]byte, 64)
+	copy(wide, k.kl)
+	scalar := edwards25519.NewScalar().SetUniformBytes(wide)
+	return new(edwards25519.Point).ScalarBaseMult(scalar).Bytes()
+}
+
+func (k *testXPrv) xpub() []byte {
+	return append(k.publicKey(), k.cc...)
+}
+
+func (k *testXPrv) child(index uint32) *testXPrv {
+	var indexBytes [4]byte
+	binary.LittleEndian.PutUint32(indexBytes[:], index)
+	var z, c []byte
+	if index >= HardenedOffset {
+		z = hmacSHA512(k.cc, []byte{0x00}, k.kl, k.kr, indexBytes[:])
+		c = hmacSHA512(k.cc, []byte{0x01}, k.kl, k.kr, indexBytes[:])
+	} else {
+		z = hmacSHA512(k.cc, []byte{0x02}, k.publicKey(), indexBytes[:])
+		c = hmacSHA512(k.cc, []byte{0x03}, k.publicKey(), indexBytes[:])
+	}
+	return &testXPrv{kl: add256(k.kl, mul8(z[:zlSize])), kr: add256(k.kr, z[32:]), cc: c[32:]}
+}
+
+// add256 adds two little-endian 256 bit numbers, modulo 2^256.
+func add256(a, b []byte) []byte {
+	result := make([]byte, 32)
+	var carry uint16
+	for i := range result {
+		sum := uint16(a[i]) + uint16(b[i]) + carry
+		result[i] = byte(sum)
+		carry = sum >> 8
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+// The tests against a cardano-wallet server are in the external test package,
+// since the wallet package imports hdkey to validate account public keys.
+package hdkey_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/godano/cardano-wallet-client/address"
+	"github.com/godano/cardano-wallet-client/bech32"
+	"github.com/godano/cardano-wallet-client/hdkey"
+	"github.com/godano/cardano-wallet-client/wallet"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// EnvTestPassphrase enables the tests against a cardano-wallet server, together with wallet.EnvVarWalletServerAddress.
+// The passphrase is required to query the account public key of the first wallet.
+const EnvTestPassphrase = "GODANO_WALLET_CLIENT_TEST_PASSPHRASE"
+
+type WalletServerTestSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestWalletServer(t *testing.T) {
+	testSuite := new(WalletServerTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *WalletServerTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+}
+
+// TestWalletAddresses compares derived keys and addresses with the ones reported by a cardano-wallet server.
+func (s *WalletServerTestSuite) TestWalletAddresses() {
+	serverAddress := os.Getenv(wallet.EnvVarWalletServerAddress)
+	passphrase := os.Getenv(EnvTestPassphrase)
+	if serverAddress == "" || passphrase == "" {
+		s.T().Skipf("Set %v and %v to test against a cardano-wallet server", wallet.EnvVarWalletServerAddress, EnvTestPassphrase)
+	}
+	tlsConfig, err := wallet.MakeTLSConfig()
+	s.NoError(err)
+	client, err := wallet.NewHTTPSClientWithResponses(serverAddress, tlsConfig)
+	s.NoError(err)
+	ctx := context.Background()
+
+	wallets, err := client.ListWalletsWithResponse(ctx)
+	s.NoError(err)
+	s.NotNil(wallets.JSON200, "ListWallets: %s", wallets.Body)
+	if len(*wallets.JSON200) == 0 {
+		s.T().Skip("No Shelley wallet available")
+	}
+	walletId := (*wallets.JSON200)[0].Id
+
+	keyResp, err := client.PostAccountKeyWithResponse(ctx, walletId, "0H", wallet.PostAccountKeyJSONRequestBody{
+		Extended:   true,
+		Passphrase: passphrase,
+	})
+	s.NoError(err)
+	s.Equal(http.StatusAccepted, keyResp.StatusCode(), "PostAccountKey: %s", keyResp.Body)
+	var accountKey string
+	s.NoError(json.Unmarshal(keyResp.Body, &accountKey))
+	account, err := hdkey.ParseXPub(accountKey)
+	s.NoError(err)
+
+	// Compare the keys returned by GetWalletKey
+	for _, role := range []hdkey.Role{hdkey.RoleUTxOExternal, hdkey.RoleMutableAccount} {
+		resp, err := client.GetWalletKeyWithResponse(ctx, walletId, role.String(), "0")
+		s.NoError(err)
+		s.Equal(http.StatusOK, resp.StatusCode(), "GetWalletKey: %s", resp.Body)
+		var walletKey string
+		s.NoError(json.Unmarshal(resp.Body, &walletKey))
+		_, walletKeyBytes, err := bech32.Decode(walletKey)
+		s.NoError(err)
+		derived, err := account.Derive(role, 0)
+		s.NoError(err)
+		s.Equal(walletKeyBytes, []byte(derived.PublicKey()), "role %v", role)
+	}
+
+	// Compare the addresses returned by ListAddresses
+	addresses, err := client.ListAddressesWithResponse(ctx, walletId, new(wallet.ListAddressesParams))
+	s.NoError(err)
+	s.NotNil(addresses.JSON200, "ListAddresses: %s", addresses.Body)
+	for _, listed := range *addresses.JSON200 {
+		path := listed.DerivationPath
+		s.Len(path, 5, "derivation path of %v", listed.Id)
+		role, err := strconv.ParseUint(path[3], 10, 32)
+		s.NoError(err)
+		index, err := strconv.ParseUint(path[4], 10, 32)
+		s.NoError(err)
+		decoded, err := address.Decode(listed.Id)
+		s.NoError(err)
+
+		derived, err := account.BaseAddress(*decoded.Network, hdkey.Role(role), uint32(index))
+		s.NoError(err)
+		s.Equal(listed.Id, derived.String(), fmt.Sprintf("address %v", strings.Join(path, "/")))
+	}
+}
@@ -0,0 +1,158 @@
+package cbor
+
+import (
+	"fmt"
+)
+
+// Tag is a decoded tagged value.
+type Tag struct {
+	Number  uint64
+	Content interface{}
+}
+
+// Pair is a key-value pair of a decoded map.
+type Pair struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// Map is a decoded map. The pairs are kept in their encoded order, and keys may be of unhashable types.
+type Map []Pair
+
+// Get returns the value of the first pair with the given unsigned integer key.
+func (m Map) Get(key uint64) (interface{}, bool) {
+	for _, pair := range m {
+		if k, ok := pair.Key.(uint64); ok && k == key {
+			return pair.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Negative is a decoded negative integer, representing the value -1-Negative.
+type Negative uint64
+
+// Decode decodes a single value from the given data and returns it along with the remaining data.
+// The decoded types are uint64, Negative, []byte, string, []interface{}, Map, Tag, bool and nil.
+// Indefinite-length values and floating point numbers are not supported.
+func Decode(data []byte) (interface{}, []byte, error) {
+	return decodeValue(data, 0)
+}
+
+// DecodeAll decodes a single value and fails if there is remaining data.
+func DecodeAll(data []byte) (interface{}, error) {
+	val, rest, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("Unexpected %v bytes after CBOR value", len(rest))
+	}
+	return val, nil
+}
+
+const maxDepth = 64
+
+func decodeValue(data []byte, depth int) (interface{}, []byte, error) {
+	if depth > maxDepth {
+		return nil, nil, fmt.Errorf("CBOR value nested too deeply")
+	}
+	major, arg, data, err := decodeHeader(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch major {
+	case MajorUnsigned:
+		return arg, data, nil
+	case MajorNegative:
+		return Negative(arg), data, nil
+	case MajorBytes, MajorText:
+		if uint64(len(data)) < arg {
+			return nil, nil, fmt.Errorf("CBOR string length %v exceeds remaining %v bytes", arg, len(data))
+		}
+		content := data[:arg]
+		if major == MajorText {
+			return string(content), data[arg:], nil
+		}
+		return append([]byte{}, content...), data[arg:], nil
+	case MajorArray:
+		if arg > uint64(len(data)) {
+			return nil, nil, fmt.Errorf("CBOR array length %v exceeds remaining %v bytes", arg, len(data))
+		}
+		result := make([]interface{}, arg)
+		for i := range result {
+			result[i], data, err = decodeValue(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		return result, data, nil
+	case MajorMap:
+		if arg > uint64(len(data)) {
+			return nil, nil, fmt.Errorf("CBOR map length %v exceeds remaining %v bytes", arg, len(data))
+		}
+		result := make(Map, arg)
+		for i := range result {
+			result[i].Key, data, err = decodeValue(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			result[i].Value, data, err = decodeValue(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		return result, data, nil
+	case MajorTag:
+		content, data, err := decodeValue(data, depth+1)
+		if err != nil {
+			return nil, nil, err
+		}
+		return Tag{Number: arg, Content: content}, data, nil
+	default: // MajorSimple
+		switch arg {
+		case 20:
+			return false, data, nil
+		case 21:
+			return true, data, nil
+		case 22:
+			return nil, data, nil
+		}
+		return nil, nil, fmt.Errorf("Unsupported CBOR simple value or float: %v", arg)
+	}
+}
+
+func decodeHeader(data []byte) (byte, uint64, []byte, error) {
+	if len(data) == 0 {
+		return 0, 0, nil, fmt.Errorf("Unexpected end of CBOR data")
+	}
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+	data = data[1:]
+	var size int
+	switch {
+	case info < 24:
+		return major, uint64(info), data, nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, 0, nil, fmt.Errorf("Unsupported CBOR additional information %v (indefinite lengths are not supported)", info)
+	}
+	if major == MajorSimple && size > 1 {
+		return 0, 0, nil, fmt.Errorf("CBOR floating point values are not supported")
+	}
+	if len(data) < size {
+		return 0, 0, nil, fmt.Errorf("Unexpected end of CBOR data")
+	}
+	var arg uint64
+	for _, b := range data[:size] {
+		arg = arg<<8 | uint64(b)
+	}
+	return major, arg, data[size:], nil
+}
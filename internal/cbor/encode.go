@@ -0,0 +1,102 @@
+// Package cbor implements the small subset of CBOR (RFC 7049) that is needed for Cardano metadata,
+// addresses and native scripts. All values are written with definite lengths and minimal-length headers.
+package cbor
+
+import (
+	"bytes"
+)
+
+// Major types as defined in RFC 7049
+const (
+	MajorUnsigned = 0
+	MajorNegative = 1
+	MajorBytes    = 2
+	MajorText     = 3
+	MajorArray    = 4
+	MajorMap      = 5
+	MajorTag      = 6
+	MajorSimple   = 7
+)
+
+// Encoder appends CBOR-encoded values to an internal buffer.
+type Encoder struct {
+	buf bytes.Buffer
+}
+
+// Bytes returns the encoded data.
+func (e *Encoder) Bytes() []byte {
+	return e.buf.Bytes()
+}
+
+func (e *Encoder) writeHeader(major byte, val uint64) {
+	major <<= 5
+	switch {
+	case val < 24:
+		e.buf.WriteByte(major | byte(val))
+	case val <= 0xff:
+		e.buf.WriteByte(major | 24)
+		e.buf.WriteByte(byte(val))
+	case val <= 0xffff:
+		e.buf.WriteByte(major | 25)
+		e.buf.Write([]byte{byte(val >> 8), byte(val)})
+	case val <= 0xffffffff:
+		e.buf.WriteByte(major | 26)
+		e.buf.Write([]byte{byte(val >> 24), byte(val >> 16), byte(val >> 8), byte(val)})
+	default:
+		e.buf.WriteByte(major | 27)
+		for shift := 56; shift >= 0; shift -= 8 {
+			e.buf.WriteByte(byte(val >> uint(shift)))
+		}
+	}
+}
+
+// WriteUint writes an unsigned integer.
+func (e *Encoder) WriteUint(val uint64) {
+	e.writeHeader(MajorUnsigned, val)
+}
+
+// WriteInt writes a signed integer.
+func (e *Encoder) WriteInt(val int64) {
+	if val < 0 {
+		e.WriteNegative(uint64(-(val + 1)))
+	} else {
+		e.WriteUint(uint64(val))
+	}
+}
+
+// WriteNegative writes the negative integer -1-val. This covers the full range of negative CBOR integers.
+func (e *Encoder) WriteNegative(val uint64) {
+	e.writeHeader(MajorNegative, val)
+}
+
+// WriteBytes writes a bytestring.
+func (e *Encoder) WriteBytes(val []byte) {
+	e.writeHeader(MajorBytes, uint64(len(val)))
+	e.buf.Write(val)
+}
+
+// WriteText writes a UTF-8 text string.
+func (e *Encoder) WriteText(val string) {
+	e.writeHeader(MajorText, uint64(len(val)))
+	e.buf.WriteString(val)
+}
+
+// WriteArrayHeader starts an array with the given number of elements, which must be written afterwards.
+func (e *Encoder) WriteArrayHeader(length int) {
+	e.writeHeader(MajorArray, uint64(length))
+}
+
+// WriteMapHeader starts a map with the given number of key-value pairs, which must be written afterwards.
+func (e *Encoder) WriteMapHeader(length int) {
+	e.writeHeader(MajorMap, uint64(length))
+}
+
+// WriteTag writes a tag, which applies to the value written afterwards.
+func (e *Encoder) WriteTag(tag uint64) {
+	e.writeHeader(MajorTag, tag)
+}
+
+// WriteRaw appends already encoded CBOR data.
+func (e *Encoder) WriteRaw(data []byte) {
+	e.buf.Write(data)
+}
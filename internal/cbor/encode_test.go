@@ -0,0 +1,103 @@
+package cbor
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type EncodeTestSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestEncode(t *testing.T) {
+	testSuite := new(EncodeTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *EncodeTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+}
+
+func (s *EncodeTestSuite) encoded(write func(e *Encoder)) string {
+	e := new(Encoder)
+	write(e)
+	return hex.EncodeToString(e.Bytes())
+}
+
+// Examples taken from appendix A of RFC 7049
+func (s *EncodeTestSuite) TestIntegers() {
+	for val, expected := range map[int64]string{
+		0:             "00",
+		23:            "17",
+		24:            "1818",
+		100:           "1864",
+		1000:          "1903e8",
+		1000000:       "1a000f4240",
+		1000000000000: "1b000000e8d4a51000",
+		-1:            "20",
+		-100:          "3863",
+		-1000:         "3903e7",
+	} {
+		s.Equal(expected, s.encoded(func(e *Encoder) { e.WriteInt(val) }), "value %v", val)
+	}
+	s.Equal("1bffffffffffffffff", s.encoded(func(e *Encoder) { e.WriteUint(18446744073709551615) }))
+	s.Equal("3bffffffffffffffff", s.encoded(func(e *Encoder) { e.WriteNegative(18446744073709551615) }))
+}
+
+func (s *EncodeTestSuite) TestStringsAndContainers() {
+	s.Equal("4401020304", s.encoded(func(e *Encoder) { e.WriteBytes([]byte{1, 2, 3, 4}) }))
+	s.Equal("6449455446", s.encoded(func(e *Encoder) { e.WriteText("IETF") }))
+	s.Equal("a201020304", s.encoded(func(e *Encoder) {
+		e.WriteMapHeader(2)
+		e.WriteUint(1)
+		e.WriteUint(2)
+		e.WriteUint(3)
+		e.WriteUint(4)
+	}))
+	s.Equal("8301820203820405", s.encoded(func(e *Encoder) {
+		e.WriteArrayHeader(3)
+		e.WriteUint(1)
+		e.WriteArrayHeader(2)
+		e.WriteUint(2)
+		e.WriteUint(3)
+		e.WriteArrayHeader(2)
+		e.WriteUint(4)
+		e.WriteUint(5)
+	}))
+	s.Equal("d818456449455446", s.encoded(func(e *Encoder) {
+		e.WriteTag(24)
+		e.WriteBytes([]byte{0x64, 0x49, 0x45, 0x54, 0x46})
+	}))
+}
+
+func (s *EncodeTestSuite) TestDecodeRoundTrip() {
+	e := new(Encoder)
+	e.WriteArrayHeader(5)
+	e.WriteUint(1000000)
+	e.WriteInt(-100)
+	e.WriteBytes([]byte{1, 2})
+	e.WriteMapHeader(1)
+	e.WriteUint(2)
+	e.WriteText("IETF")
+	e.WriteTag(24)
+	e.WriteBytes([]byte{0x01})
+
+	val, err := DecodeAll(e.Bytes())
+	s.NoError(err)
+	s.Equal([]interface{}{
+		uint64(1000000),
+		Negative(99),
+		[]byte{1, 2},
+		Map{{Key: uint64(2), Value: "IETF"}},
+		Tag{Number: 24, Content: []byte{0x01}},
+	}, val)
+
+	_, err = DecodeAll(append(e.Bytes(), 0x00))
+	s.Error(err)
+	_, err = DecodeAll(e.Bytes()[:len(e.Bytes())-1])
+	s.Error(err)
+}
@@ -0,0 +1,55 @@
+// Package prometheus writes metrics in the Prometheus text exposition format (version 0.0.4).
+package prometheus
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the content type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Writer writes metric families and their samples. All metric names are prefixed with the prefix of the Writer.
+type Writer struct {
+	out    *bufio.Writer
+	prefix string
+}
+
+// NewWriter returns a Writer, which buffers its output until Flush() is called.
+func NewWriter(w io.Writer, prefix string) *Writer {
+	return &Writer{out: bufio.NewWriter(w), prefix: prefix}
+}
+
+// Family writes the HELP and TYPE lines of a metric family. Its samples must follow.
+func (w *Writer) Family(name, metricType, help string) {
+	fmt.Fprintf(w.out, "# HELP %v%v %v\n# TYPE %v%v %v\n", w.prefix, name, help, w.prefix, name, metricType)
+}
+
+// Sample writes a sample with the given labels, which are alternating names and values.
+func (w *Writer) Sample(name string, value interface{}, labels ...string) {
+	w.out.WriteString(w.prefix + name)
+	if len(labels) > 0 {
+		w.out.WriteString("{")
+		for i := 0; i < len(labels); i += 2 {
+			if i > 0 {
+				w.out.WriteString(",")
+			}
+			fmt.Fprintf(w.out, "%v=\"%v\"", labels[i], labelValueEscaper.Replace(labels[i+1]))
+		}
+		w.out.WriteString("}")
+	}
+	if floatValue, ok := value.(float64); ok {
+		value = strconv.FormatFloat(floatValue, 'g', -1, 64)
+	}
+	fmt.Fprintf(w.out, " %v\n", value)
+}
+
+// Flush writes the buffered output and returns the first error that occurred.
+func (w *Writer) Flush() error {
+	return w.out.Flush()
+}
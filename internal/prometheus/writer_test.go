@@ -0,0 +1,42 @@
+package prometheus
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type WriterTestSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestWriter(t *testing.T) {
+	testSuite := new(WriterTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *WriterTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+}
+
+func (s *WriterTestSuite) TestWrite() {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, "test_")
+	w.Family("up", "gauge", "Whether it is up.")
+	w.Sample("up", 1.0)
+	w.Sample("up", 0.25, "name", "a \"quoted\"\\\nname", "kind", "b")
+	w.Family("requests_total", "counter", "Requests.")
+	w.Sample("requests_total", uint64(42), "code", "200")
+	s.NoError(w.Flush())
+	s.Equal(`# HELP test_up Whether it is up.
+# TYPE test_up gauge
+test_up 1
+test_up{name="a \"quoted\"\\\nname",kind="b"} 0.25
+# HELP test_requests_total Requests.
+# TYPE test_requests_total counter
+test_requests_total{code="200"} 42
+`, buf.String())
+}
@@ -0,0 +1,302 @@
+// Package metaindex maintains an on-disk search index over the metadata of wallet transactions.
+//
+// The index is updated incrementally from ListTransactions. Each metadata value is flattened into
+// JSONPath-like selectors (e.g. `msg`, `msg[0]` or `order.id`), which can be queried with Index.Search.
+package metaindex
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/godano/cardano-wallet-client/wallet"
+)
+
+// Entry is the indexed metadata value of one label in one transaction.
+type Entry struct {
+	WalletId      string `json:"wallet_id"`
+	TransactionId string `json:"transaction_id"`
+	Label         uint   `json:"label"`
+	InsertedAt    string `json:"inserted_at"`
+
+	// Fields maps selectors to the values found at that selector. Elements of lists are indexed under
+	// their position (`list[0]`) and under the wildcard selector (`list[*]`). Primitive elements are
+	// additionally indexed under the selector of the list itself.
+	Fields map[string][]string `json:"fields"`
+}
+
+type walletState struct {
+	// Time of the most recent indexed transaction
+	LastInsertedAt string `json:"last_inserted_at"`
+
+	// IDs of the transactions inserted at LastInsertedAt. These are returned again
+	// by the next update, since the start time is inclusive.
+	BoundaryIds []string `json:"boundary_ids"`
+}
+
+// Index is a metadata index, which is stored in a JSON file.
+type Index struct {
+	path string
+
+	Wallets map[string]*walletState `json:"wallets"`
+	Labels  map[uint][]*Entry       `json:"labels"`
+
+	// values maps labels, selectors and values to the entries containing them. It is not stored, but rebuilt by Open.
+	values map[uint]map[string]map[string][]*Entry
+}
+
+// Open loads the index from the given file. If the file does not exist, an empty index is returned.
+func Open(path string) (*Index, error) {
+	idx := &Index{
+		path:    path,
+		Wallets: make(map[string]*walletState),
+		Labels:  make(map[uint][]*Entry),
+		values:  make(map[uint]map[string]map[string][]*Entry),
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return idx, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, fmt.Errorf("Failed to parse metadata index %v: %v", path, err)
+	}
+	for _, entries := range idx.Labels {
+		for _, entry := range entries {
+			idx.addValues(entry)
+		}
+	}
+	return idx, nil
+}
+
+// Save writes the index to its file. The file is replaced atomically.
+func (idx *Index) Save() error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(idx.path), 0700); err != nil {
+		return err
+	}
+	tmpFile := idx.path + ".tmp"
+	if err := ioutil.WriteFile(tmpFile, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, idx.path)
+}
+
+// Update indexes all new transactions of the given wallets, and returns the number of added entries.
+// Only transactions that are inserted in the ledger are indexed, pending transactions are picked up by later updates.
+func (idx *Index) Update(ctx context.Context, client wallet.ClientWithResponsesInterface, walletIds ...string) (int, error) {
+	added := 0
+	for _, walletId := range walletIds {
+		n, err := idx.updateWallet(ctx, client, walletId)
+		added += n
+		if err != nil {
+			return added, fmt.Errorf("Wallet %v: %v", walletId, err)
+		}
+	}
+	return added, nil
+}
+
+func (idx *Index) updateWallet(ctx context.Context, client wallet.ClientWithResponsesInterface, walletId string) (int, error) {
+	state, ok := idx.Wallets[walletId]
+	if !ok {
+		state = new(walletState)
+		idx.Wallets[walletId] = state
+	}
+	order := "ascending"
+	params := &wallet.ListTransactionsParams{Order: &order}
+	if state.LastInsertedAt != "" {
+		params.Start = &state.LastInsertedAt
+	}
+	resp, err := client.ListTransactionsWithResponse(ctx, walletId, params)
+	if err != nil {
+		return 0, err
+	}
+	if resp.JSON200 == nil {
+		return 0, fmt.Errorf("ListTransactions: unexpected response status %v: %s", resp.Status(), resp.Body)
+	}
+
+	boundary := make(map[string]bool, len(state.BoundaryIds))
+	for _, id := range state.BoundaryIds {
+		boundary[id] = true
+	}
+	lastTime, _ := parseTime(state.LastInsertedAt)
+
+	added := 0
+	for _, tx := range *resp.JSON200 {
+		if tx.InsertedAt == nil || boundary[tx.Id] {
+			continue
+		}
+		insertedAt := tx.InsertedAt.Time
+		txTime, err := parseTime(insertedAt)
+		if err != nil {
+			return added, fmt.Errorf("Transaction %v: %v", tx.Id, err)
+		}
+		entries, err := makeEntries(walletId, tx.Id, insertedAt, tx.Metadata)
+		if err != nil {
+			return added, fmt.Errorf("Transaction %v: %v", tx.Id, err)
+		}
+		for _, entry := range entries {
+			idx.Labels[entry.Label] = append(idx.Labels[entry.Label], entry)
+			idx.addValues(entry)
+			added++
+		}
+
+		// The state only advances past transactions, which were indexed
+		if txTime.After(lastTime) {
+			lastTime = txTime
+			state.LastInsertedAt = insertedAt
+			state.BoundaryIds = nil
+		}
+		if txTime.Equal(lastTime) {
+			state.BoundaryIds = append(state.BoundaryIds, tx.Id)
+		}
+	}
+	return added, nil
+}
+
+// addValues adds the entry to the values of its fields.
+func (idx *Index) addValues(entry *Entry) {
+	selectors, ok := idx.values[entry.Label]
+	if !ok {
+		selectors = make(map[string]map[string][]*Entry)
+		idx.values[entry.Label] = selectors
+	}
+	for selector, fieldValues := range entry.Fields {
+		values, ok := selectors[selector]
+		if !ok {
+			values = make(map[string][]*Entry)
+			selectors[selector] = values
+		}
+		for i, val := range fieldValues {
+			if !containsString(fieldValues[:i], val) {
+				values[val] = append(values[val], entry)
+			}
+		}
+	}
+}
+
+func containsString(values []string, s string) bool {
+	for _, val := range values {
+		if val == s {
+			return true
+		}
+	}
+	return false
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func makeEntries(walletId, txId, insertedAt string, meta *wallet.Metadata) ([]*Entry, error) {
+	parsed, err := meta.Parse()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*Entry, 0, len(parsed))
+	for label, val := range parsed {
+		entry := &Entry{
+			WalletId:      walletId,
+			TransactionId: txId,
+			Label:         label,
+			InsertedAt:    insertedAt,
+			Fields:        make(map[string][]string),
+		}
+		flatten(entry.Fields, "", val)
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Label < entries[j].Label })
+	return entries, nil
+}
+
+// flatten records all values contained in val under their selectors. The empty selector refers to the label's value.
+func flatten(fields map[string][]string, selector string, val interface{}) {
+	switch typedVal := val.(type) {
+	case []interface{}:
+		for i, item := range typedVal {
+			flatten(fields, fmt.Sprintf("%v[%v]", selector, i), item)
+			flatten(fields, selector+"[*]", item)
+			if _, isContainer := item.([]interface{}); !isContainer {
+				if _, isMap := item.(map[interface{}]interface{}); !isMap {
+					fields[selector] = append(fields[selector], formatValue(item))
+				}
+			}
+		}
+	case map[interface{}]interface{}:
+		for key, item := range typedVal {
+			childSelector := formatValue(key)
+			if selector != "" {
+				childSelector = selector + "." + childSelector
+			}
+			flatten(fields, childSelector, item)
+		}
+	default:
+		fields[selector] = append(fields[selector], formatValue(val))
+	}
+}
+
+// formatValue returns the string representation of a primitive metadata value, bytestrings are hex-encoded.
+func formatValue(val interface{}) string {
+	switch typedVal := val.(type) {
+	case string:
+		return typedVal
+	case []byte:
+		return hex.EncodeToString(typedVal)
+	case wallet.BytesKey:
+		return hex.EncodeToString([]byte(typedVal))
+	case int:
+		return strconv.Itoa(typedVal)
+	}
+	return fmt.Sprint(val)
+}
+
+// Search returns all entries that match the given query, ordered by insertion time. See ParseQuery for the syntax.
+// The entries of the first `=` condition are looked up by their value, other queries scan all entries of the label.
+func (idx *Index) Search(query string) ([]*Entry, error) {
+	q, err := ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []*Entry
+	for label, entries := range idx.Labels {
+		if q.Label != nil && *q.Label != label {
+			continue
+		}
+		if cond := q.firstEquals(); cond != nil {
+			entries = idx.values[label][cond.Selector][cond.Value]
+		}
+		candidates = append(candidates, entries...)
+	}
+	var result []*Entry
+	for _, entry := range candidates {
+		if q.Matches(entry) {
+			result = append(result, entry)
+		}
+	}
+	// The timestamps are compared as times, since they may or may not have fractional seconds
+	times := make(map[*Entry]time.Time, len(result))
+	for _, entry := range result {
+		times[entry], _ = parseTime(entry.InsertedAt)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if ti, tj := times[result[i]], times[result[j]]; !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return result[i].Label < result[j].Label
+	})
+	return result, nil
+}
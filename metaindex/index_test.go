@@ -0,0 +1,186 @@
+package metaindex
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/godano/cardano-wallet-client/wallet"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type IndexTestSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	transactions []map[string]interface{}
+	lastStart    string
+	client       *wallet.ClientWithResponses
+	server       *httptest.Server
+	dir          string
+}
+
+func TestIndex(t *testing.T) {
+	testSuite := new(IndexTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *IndexTestSuite) SetupTest() {
+	s.Assertions = s.Require()
+	s.transactions = nil
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.lastStart = r.URL.Query().Get("start")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.transactions)
+	}))
+	var err error
+	s.client, err = wallet.NewClientWithResponses(s.server.URL + "/v2")
+	s.NoError(err)
+	s.dir, err = ioutil.TempDir("", "metaindex")
+	s.NoError(err)
+}
+
+func (s *IndexTestSuite) TearDownTest() {
+	s.server.Close()
+	os.RemoveAll(s.dir)
+}
+
+func (s *IndexTestSuite) addTransaction(id, insertedAt string, meta wallet.Metadata) {
+	tx := map[string]interface{}{"id": id, "metadata": meta}
+	if insertedAt != "" {
+		tx["inserted_at"] = map[string]interface{}{"time": insertedAt}
+	}
+	s.transactions = append(s.transactions, tx)
+}
+
+func (s *IndexTestSuite) message(text string) wallet.Metadata {
+	meta, err := wallet.CIP20Metadata(text)
+	s.NoError(err)
+	return meta
+}
+
+func (s *IndexTestSuite) ids(entries []*Entry) []string {
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.TransactionId
+	}
+	return ids
+}
+
+func (s *IndexTestSuite) TestIncrementalUpdate() {
+	ctx := context.Background()
+	path := filepath.Join(s.dir, "index.json")
+	idx, err := Open(path)
+	s.NoError(err)
+
+	s.addTransaction("tx1", "2021-05-01T10:00:00Z", s.message("invoice-41"))
+	s.addTransaction("tx2", "2021-05-02T10:00:00Z", s.message("invoice-42"))
+	s.addTransaction("pending", "", s.message("invoice-43"))
+	added, err := idx.Update(ctx, s.client, "w1")
+	s.NoError(err)
+	s.Equal(2, added)
+	s.NoError(idx.Save())
+
+	// The next update starts at the last indexed transaction, which must not be indexed twice
+	idx, err = Open(path)
+	s.NoError(err)
+	s.transactions = s.transactions[1:]
+	s.transactions[1]["inserted_at"] = map[string]interface{}{"time": "2021-05-03T10:00:00Z"}
+	added, err = idx.Update(ctx, s.client, "w1")
+	s.NoError(err)
+	s.Equal("2021-05-02T10:00:00Z", s.lastStart)
+	s.Equal(1, added)
+
+	result, err := idx.Search(`label=674 msg contains "invoice-4"`)
+	s.NoError(err)
+	s.Equal([]string{"tx1", "tx2", "pending"}, s.ids(result))
+
+	result, err = idx.Search(`label=674 msg contains "invoice-42"`)
+	s.NoError(err)
+	s.Equal([]string{"tx2"}, s.ids(result))
+
+	// The values of loaded and of added entries are looked up
+	result, err = idx.Search(`msg = invoice-41`)
+	s.NoError(err)
+	s.Equal([]string{"tx1"}, s.ids(result))
+	result, err = idx.Search(`label=674 msg = invoice-43`)
+	s.NoError(err)
+	s.Equal([]string{"pending"}, s.ids(result))
+}
+
+func (s *IndexTestSuite) TestPartialUpdate() {
+	ctx := context.Background()
+	idx, err := Open(filepath.Join(s.dir, "index.json"))
+	s.NoError(err)
+	s.addTransaction("tx1", "2021-05-01T10:00:00Z", s.message("first"))
+	s.addTransaction("invalid", "2021-05-02T10:00:00Z", wallet.Metadata{674: map[string]interface{}{"unknown": 1}})
+	added, err := idx.Update(ctx, s.client, "w1")
+	s.Error(err)
+	s.Equal(1, added)
+
+	// The failed transaction is requested again by the next update
+	s.transactions[1]["metadata"] = s.message("second")
+	added, err = idx.Update(ctx, s.client, "w1")
+	s.NoError(err)
+	s.Equal("2021-05-01T10:00:00Z", s.lastStart)
+	s.Equal(1, added)
+}
+
+func (s *IndexTestSuite) TestFractionalSeconds() {
+	s.addTransaction("tx1", "2021-05-01T10:00:45Z", s.message("hello"))
+	s.addTransaction("tx2", "2021-05-01T10:00:45.123Z", s.message("hello"))
+	idx, err := Open(filepath.Join(s.dir, "index.json"))
+	s.NoError(err)
+	_, err = idx.Update(context.Background(), s.client, "w1")
+	s.NoError(err)
+	result, err := idx.Search("msg = hello")
+	s.NoError(err)
+	s.Equal([]string{"tx1", "tx2"}, s.ids(result))
+}
+
+func (s *IndexTestSuite) TestSelectors() {
+	meta, err := wallet.EncodeMetadata(map[uint]interface{}{
+		1337: map[interface{}]interface{}{
+			"order": map[interface{}]interface{}{"id": 1234, "customer": "acme"},
+			"items": []interface{}{
+				map[interface{}]interface{}{"sku": "A-1"},
+				map[interface{}]interface{}{"sku": "B-2"},
+			},
+		},
+	})
+	s.NoError(err)
+	s.addTransaction("tx1", "2021-05-01T10:00:00Z", meta)
+	s.addTransaction("tx2", "2021-05-01T11:00:00Z", s.message("hello"))
+	idx, err := Open(filepath.Join(s.dir, "index.json"))
+	s.NoError(err)
+	_, err = idx.Update(context.Background(), s.client, "w1")
+	s.NoError(err)
+
+	for query, expected := range map[string][]string{
+		"order.id = 1234":            {"tx1"},
+		"$.order.id=1234":            {"tx1"},
+		"label=1337 order.id != 1":   {"tx1"},
+		"items[1].sku = B-2":         {"tx1"},
+		"items[*].sku = B-2":         {"tx1"},
+		"msg[*] = hello":             {"tx2"},
+		"order.customer contains ac": {"tx1"},
+		"msg exists":                 {"tx2"},
+		"label=674":                  {"tx2"},
+		"label=1 msg exists":         {},
+	} {
+		result, err := idx.Search(query)
+		s.NoError(err, query)
+		s.Equal(expected, append([]string{}, s.ids(result)...), query)
+	}
+
+	for _, invalid := range []string{"msg", "msg contains", "label contains 1", "msg ~ x", `msg = "x`} {
+		_, err := idx.Search(invalid)
+		s.Error(err, invalid)
+	}
+}
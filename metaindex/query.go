@@ -0,0 +1,184 @@
+package metaindex
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Operators supported in query conditions
+const (
+	OpEquals    = "="
+	OpNotEquals = "!="
+	OpContains  = "contains"
+	OpExists    = "exists"
+)
+
+// Condition is a single `selector op value` term of a query.
+type Condition struct {
+	Selector string
+	Op       string
+	Value    string
+}
+
+// Query is a parsed search query. All conditions must match.
+type Query struct {
+	Label      *uint
+	Conditions []Condition
+}
+
+// ParseQuery parses a query, which consists of whitespace-separated terms, for example:
+//
+//	label=674 msg contains "invoice-42"
+//
+// The optional `label=<n>` term restricts the search to a metadata label. All other terms have the form
+// `<selector> <op> <value>`, where op is one of `=`, `!=` and `contains`, or `<selector> exists`.
+// Selectors are JSONPath-like, e.g. `msg`, `msg[0]`, `$.order.id` or `items[*].sku`. A selector of a list
+// of primitive values (like `msg`) matches all elements of that list. Values containing whitespace must be quoted.
+func ParseQuery(query string) (*Query, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	result := new(Query)
+	for len(tokens) > 0 {
+		if len(tokens) < 2 {
+			return nil, fmt.Errorf("Incomplete query term: %v", tokens[0].text)
+		}
+		selector, op := tokens[0], tokens[1]
+		if selector.quoted || op.quoted {
+			return nil, fmt.Errorf("Expected selector and operator, but got: %v %v", selector.text, op.text)
+		}
+		if op.text == OpExists {
+			result.Conditions = append(result.Conditions, Condition{Selector: normalizeSelector(selector.text), Op: OpExists})
+			tokens = tokens[2:]
+			continue
+		}
+		if op.text != OpEquals && op.text != OpNotEquals && op.text != OpContains {
+			return nil, fmt.Errorf("Unknown operator '%v'", op.text)
+		}
+		if len(tokens) < 3 {
+			return nil, fmt.Errorf("Missing value for '%v %v'", selector.text, op.text)
+		}
+		value := tokens[2].text
+		tokens = tokens[3:]
+
+		if selector.text == "label" {
+			if op.text != OpEquals {
+				return nil, fmt.Errorf("Only '=' is supported for the label")
+			}
+			label, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid label '%v': %v", value, err)
+			}
+			labelVal := uint(label)
+			result.Label = &labelVal
+			continue
+		}
+		result.Conditions = append(result.Conditions, Condition{
+			Selector: normalizeSelector(selector.text),
+			Op:       op.text,
+			Value:    value,
+		})
+	}
+	return result, nil
+}
+
+// normalizeSelector removes the optional `$` root.
+func normalizeSelector(selector string) string {
+	selector = strings.TrimPrefix(selector, "$")
+	return strings.TrimPrefix(selector, ".")
+}
+
+// firstEquals returns the first condition with OpEquals, or nil.
+func (q *Query) firstEquals() *Condition {
+	for i := range q.Conditions {
+		if q.Conditions[i].Op == OpEquals {
+			return &q.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the given entry satisfies the query.
+func (q *Query) Matches(entry *Entry) bool {
+	if q.Label != nil && *q.Label != entry.Label {
+		return false
+	}
+	for _, cond := range q.Conditions {
+		if !cond.Matches(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches returns true if any value at the condition's selector satisfies the condition.
+// For OpNotEquals, no value at the selector may be equal to the condition's value.
+func (c *Condition) Matches(entry *Entry) bool {
+	values, ok := entry.Fields[c.Selector]
+	switch c.Op {
+	case OpExists:
+		return ok
+	case OpNotEquals:
+		for _, val := range values {
+			if val == c.Value {
+				return false
+			}
+		}
+		return true
+	}
+	for _, val := range values {
+		if c.Op == OpEquals && val == c.Value {
+			return true
+		}
+		if c.Op == OpContains && strings.Contains(val, c.Value) {
+			return true
+		}
+	}
+	// For lists of strings (like CIP-20 messages), also search the concatenated value
+	return c.Op == OpContains && len(values) > 1 && strings.Contains(strings.Join(values, ""), c.Value)
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+// tokenize splits the query at whitespace and around the operators '=' and '!=', respecting quotes.
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, token{text: current.String()})
+			current.Reset()
+		}
+	}
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case ch == '"':
+			flush()
+			end := strings.IndexByte(query[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("Unterminated quote in query: %v", query)
+			}
+			tokens = append(tokens, token{text: query[i+1 : i+1+end], quoted: true})
+			i += end + 1
+		case ch == ' ' || ch == '\t' || ch == '\n':
+			flush()
+		case ch == '=':
+			flush()
+			tokens = append(tokens, token{text: OpEquals})
+		case ch == '!' && i+1 < len(query) && query[i+1] == '=':
+			flush()
+			tokens = append(tokens, token{text: OpNotEquals})
+			i++
+		default:
+			current.WriteByte(ch)
+		}
+	}
+	flush()
+	return tokens, nil
+}
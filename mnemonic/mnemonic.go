@@ -0,0 +1,133 @@
+// Package mnemonic generates and validates English BIP-39 mnemonic sentences, as used for creating
+// and restoring wallets with PostWallet and PostByronWallet.
+package mnemonic
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultWords is the recommended length of mnemonic sentences for Shelley wallets.
+	DefaultWords = 24
+	// SecondFactorWords is the default length of the optional second-factor mnemonic of Shelley wallets.
+	SecondFactorWords = 12
+
+	bitsPerWord = 11
+)
+
+var wordIndices = func() map[string]int {
+	indices := make(map[string]int, len(englishWords))
+	for i, word := range englishWords {
+		indices[word] = i
+	}
+	return indices
+}()
+
+// Generate returns a new mnemonic sentence with the given number of words (9, 12, 15, 18, 21 or 24),
+// based on entropy from crypto/rand.
+func Generate(words int) ([]string, error) {
+	entropySize, err := entropySize(words)
+	if err != nil {
+		return nil, err
+	}
+	entropy := make([]byte, entropySize)
+	if _, err := rand.Read(entropy); err != nil {
+		return nil, fmt.Errorf("Failed to read random entropy: %v", err)
+	}
+	return FromEntropy(entropy)
+}
+
+// entropySize returns the entropy size in bytes for the given number of words.
+// Every 3 words encode 32 bits of entropy plus 1 checksum bit.
+func entropySize(words int) (int, error) {
+	if words < 9 || words > 24 || words%3 != 0 {
+		return 0, fmt.Errorf("Unsupported number of mnemonic words: %v (expected 9, 12, 15, 18, 21 or 24)", words)
+	}
+	return words / 3 * 4, nil
+}
+
+// FromEntropy encodes the given entropy (12 to 32 bytes, a multiple of 4) as mnemonic sentence.
+func FromEntropy(entropy []byte) ([]string, error) {
+	if len(entropy) < 12 || len(entropy) > 32 || len(entropy)%4 != 0 {
+		return nil, fmt.Errorf("Unsupported entropy size %v bytes", len(entropy))
+	}
+	checksum := sha256.Sum256(entropy)
+	data := append(append([]byte{}, entropy...), checksum[0])
+	words := len(entropy) * 3 / 4
+	result := make([]string, words)
+	for i := range result {
+		result[i] = englishWords[readBits(data, i*bitsPerWord)]
+	}
+	return result, nil
+}
+
+// readBits returns the 11 bits of data starting at the given bit offset.
+func readBits(data []byte, offset int) int {
+	result := 0
+	for i := 0; i < bitsPerWord; i++ {
+		bit := offset + i
+		result = result<<1 | int(data[bit/8]>>(7-uint(bit%8))&1)
+	}
+	return result
+}
+
+// Entropy decodes the mnemonic sentence and returns the contained entropy. The checksum is verified.
+func Entropy(words []string) ([]byte, error) {
+	entropySize, err := entropySize(len(words))
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, entropySize+1)
+	for i, word := range words {
+		index, ok := wordIndices[word]
+		if !ok {
+			return nil, fmt.Errorf("Word %v is not in the BIP-39 English word list: %v", i+1, word)
+		}
+		for b := 0; b < bitsPerWord; b++ {
+			if index&(1<<uint(bitsPerWord-1-b)) != 0 {
+				bit := i*bitsPerWord + b
+				data[bit/8] |= 1 << (7 - uint(bit%8))
+			}
+		}
+	}
+	entropy := data[:entropySize]
+	checksumBits := uint(len(words) / 3)
+	checksum := sha256.Sum256(entropy)
+	mask := byte(0xff << (8 - checksumBits))
+	if checksum[0]&mask != data[entropySize]&mask {
+		return nil, fmt.Errorf("Invalid mnemonic checksum")
+	}
+	return entropy, nil
+}
+
+// IsWord returns true, if the given word is in the BIP-39 English word list.
+func IsWord(word string) bool {
+	_, ok := wordIndices[word]
+	return ok
+}
+
+// Validate checks the length, words and checksum of a mnemonic sentence.
+func Validate(words []string) error {
+	_, err := Entropy(words)
+	return err
+}
+
+// Parse splits a mnemonic sentence at whitespace, converts it to lower case, and validates it.
+func Parse(sentence string) ([]string, error) {
+	words := strings.Fields(strings.ToLower(sentence))
+	if err := Validate(words); err != nil {
+		return nil, err
+	}
+	return words, nil
+}
+
+// ValidateLength checks that the mnemonic sentence is valid and has between min and max words.
+func ValidateLength(words []string, min, max int) error {
+	if len(words) < min || len(words) > max {
+		return fmt.Errorf("Mnemonic sentence has %v words, expected %v to %v", len(words), min, max)
+	}
+	return Validate(words)
+}
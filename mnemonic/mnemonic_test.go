@@ -0,0 +1,96 @@
+package mnemonic
+
+import (
+	"encoding/hex"
+	"hash/crc32"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type MnemonicTestSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestMnemonic(t *testing.T) {
+	testSuite := new(MnemonicTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *MnemonicTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+}
+
+func (s *MnemonicTestSuite) TestWordList() {
+	s.Len(englishWords, 2048)
+	// Checksum of https://github.com/bitcoin/bips/blob/master/bip-0039/english.txt
+	s.Equal(uint32(0xc1dbd296), crc32.ChecksumIEEE([]byte(english)))
+}
+
+// Test vectors from https://github.com/trezor/python-mnemonic/blob/master/vectors.json
+func (s *MnemonicTestSuite) TestVectors() {
+	for entropyHex, sentence := range map[string]string{
+		"00000000000000000000000000000000":                                 "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f":                                 "legal winner thank year wave sausage worth useful legal winner thank yellow",
+		"ffffffffffffffffffffffffffffffff":                                 "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+		"0000000000000000000000000000000000000000000000000000000000000000": strings.Repeat("abandon ", 23) + "art",
+	} {
+		entropy, err := hex.DecodeString(entropyHex)
+		s.NoError(err)
+		words, err := FromEntropy(entropy)
+		s.NoError(err)
+		s.Equal(sentence, strings.Join(words, " "))
+
+		decoded, err := Entropy(words)
+		s.NoError(err)
+		s.Equal(entropy, decoded)
+	}
+}
+
+func (s *MnemonicTestSuite) TestGenerate() {
+	for _, count := range []int{9, 12, 15, 18, 21, 24} {
+		words, err := Generate(count)
+		s.NoError(err)
+		s.Len(words, count)
+		s.NoError(Validate(words))
+	}
+	a, err := Generate(DefaultWords)
+	s.NoError(err)
+	b, err := Generate(DefaultWords)
+	s.NoError(err)
+	s.NotEqual(a, b)
+
+	for _, count := range []int{0, 8, 13, 27} {
+		_, err := Generate(count)
+		s.Error(err)
+	}
+}
+
+func (s *MnemonicTestSuite) TestInvalid() {
+	words := strings.Fields("legal winner thank year wave sausage worth useful legal winner thank yellow")
+	s.NoError(Validate(words))
+
+	wrongChecksum := append(append([]string{}, words[:11]...), "year")
+	s.Error(Validate(wrongChecksum))
+	unknownWord := append(append([]string{}, words[:11]...), "cardano")
+	s.Error(Validate(unknownWord))
+	s.Error(Validate(words[:11]))
+	s.Error(ValidateLength(words, 15, 24))
+
+	s.True(IsWord("legal"))
+	s.False(IsWord("Legal"))
+
+	parsed, err := Parse("  Legal winner THANK year wave sausage worth useful legal winner thank yellow\n")
+	s.NoError(err)
+	s.Equal(words, parsed)
+}
+
+func (s *MnemonicTestSuite) TestReadBits() {
+	data := []byte{0xff, 0x00, 0xff}
+	s.Equal(0x7f8, readBits(data, 0))
+	s.Equal(0x7, readBits(data, 8))
+	s.Equal(0xff, readBits(data, 13))
+}
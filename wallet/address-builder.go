@@ -0,0 +1,245 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/godano/cardano-wallet-client/address"
+	"github.com/godano/cardano-wallet-client/bech32"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Validation levels for scripts in PostAnyAddress requests
+const (
+	ScriptValidationRequired    = "required"
+	ScriptValidationRecommended = "recommended"
+)
+
+// Bech32 prefixes of public keys in PostAnyAddress requests
+const (
+	paymentKeyPrefix = "addr_vk"
+	stakeKeyPrefix   = "stake_vk"
+)
+
+type credentialKind int
+
+const (
+	credentialKeyHash credentialKind = iota
+	credentialPublicKey
+	credentialScript
+)
+
+// AddressCredential is a payment or stake credential for AddressBuilder.
+// Create it with KeyHashCredential(), PublicKeyCredential() or ScriptCredential().
+type AddressCredential struct {
+	kind   credentialKind
+	hash   []byte
+	key    []byte
+	script ScriptValue
+}
+
+// KeyHashCredential returns a credential for a blake2b-224 key hash.
+// Key hashes are not accepted by PostAnyAddress, so they can only be used with AddressBuilder.Offline().
+func KeyHashCredential(hash []byte) *AddressCredential {
+	return &AddressCredential{kind: credentialKeyHash, hash: hash}
+}
+
+// PublicKeyCredential returns a credential for a public key, encoded as Bech32 (addr_vk, stake_vk, ...) or hex.
+// Extended keys are accepted, the chain code is ignored.
+func PublicKeyCredential(key string) (*AddressCredential, error) {
+	publicKey, err := ParsePublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &AddressCredential{kind: credentialPublicKey, key: publicKey}, nil
+}
+
+// ScriptCredential returns a credential for a native script, for example a *Script.
+func ScriptCredential(script ScriptValue) *AddressCredential {
+	return &AddressCredential{kind: credentialScript, script: script}
+}
+
+func (c *AddressCredential) validate(name string) error {
+	switch c.kind {
+	case credentialKeyHash:
+		if len(c.hash) != keyHashSize {
+			return fmt.Errorf("Unexpected %v key hash length %v, expected %v", name, len(c.hash), keyHashSize)
+		}
+	case credentialScript:
+		if c.script == nil {
+			return fmt.Errorf("Missing %v script", name)
+		}
+	}
+	return nil
+}
+
+// value returns the credential in the format of PostAnyAddress requests.
+func (c *AddressCredential) value(keyPrefix string) (interface{}, error) {
+	switch c.kind {
+	case credentialPublicKey:
+		return bech32.Encode(keyPrefix, c.key)
+	case credentialScript:
+		return c.script, nil
+	default:
+		return nil, fmt.Errorf("PostAnyAddress does not accept key hash credentials, only public keys and scripts")
+	}
+}
+
+// addressCredential returns the credential in the format of the address package.
+func (c *AddressCredential) addressCredential() (*address.Credential, error) {
+	switch c.kind {
+	case credentialKeyHash:
+		return &address.Credential{Kind: address.KeyHash, Hash: c.hash}, nil
+	case credentialPublicKey:
+		return &address.Credential{Kind: address.KeyHash, Hash: keyHash(c.key)}, nil
+	default:
+		script, err := ScriptFromValue(c.script)
+		if err != nil {
+			return nil, err
+		}
+		hash, err := script.Hash()
+		if err != nil {
+			return nil, err
+		}
+		return &address.Credential{Kind: address.ScriptHash, Hash: hash}, nil
+	}
+}
+
+// AddressBuilder composes the request for PostAnyAddress from typed payment and stake credentials.
+// Depending on the credentials, the result is an enterprise address (payment only), a base address
+// (payment and stake) or a reward address (stake only).
+type AddressBuilder struct {
+	payment    *AddressCredential
+	stake      *AddressCredential
+	validation string
+}
+
+// NewAddressBuilder returns an empty AddressBuilder. At least one credential must be set before building the address.
+func NewAddressBuilder() *AddressBuilder {
+	return new(AddressBuilder)
+}
+
+// Payment sets the payment credential.
+func (b *AddressBuilder) Payment(credential *AddressCredential) *AddressBuilder {
+	b.payment = credential
+	return b
+}
+
+// Stake sets the stake credential.
+func (b *AddressBuilder) Stake(credential *AddressCredential) *AddressBuilder {
+	b.stake = credential
+	return b
+}
+
+// Validation sets the script validation level (ScriptValidationRequired or ScriptValidationRecommended).
+// It is only allowed in combination with script credentials.
+func (b *AddressBuilder) Validation(level string) *AddressBuilder {
+	b.validation = level
+	return b
+}
+
+// Validate checks the combination of credentials and the validation level.
+func (b *AddressBuilder) Validate() error {
+	if b.payment == nil && b.stake == nil {
+		return fmt.Errorf("Address requires a payment credential, a stake credential, or both")
+	}
+	hasScript := false
+	for name, cred := range map[string]*AddressCredential{"payment": b.payment, "stake": b.stake} {
+		if cred == nil {
+			continue
+		}
+		if err := cred.validate(name); err != nil {
+			return err
+		}
+		hasScript = hasScript || cred.kind == credentialScript
+	}
+	switch b.validation {
+	case "":
+	case ScriptValidationRequired, ScriptValidationRecommended:
+		if !hasScript {
+			return fmt.Errorf("Script validation level requires a script credential")
+		}
+	default:
+		return fmt.Errorf("Unknown script validation level: %v", b.validation)
+	}
+	return nil
+}
+
+// Body returns the request body for PostAnyAddress.
+func (b *AddressBuilder) Body() (PostAnyAddressJSONRequestBody, error) {
+	var body PostAnyAddressJSONRequestBody
+	if err := b.Validate(); err != nil {
+		return body, err
+	}
+	if b.payment != nil {
+		payment, err := b.payment.value(paymentKeyPrefix)
+		if err != nil {
+			return body, err
+		}
+		body.Payment = &payment
+	}
+	if b.stake != nil {
+		stake, err := b.stake.value(stakeKeyPrefix)
+		if err != nil {
+			return body, err
+		}
+		body.Stake = &stake
+	}
+	if b.validation != "" {
+		validation := b.validation
+		body.Validation = &validation
+	}
+	return body, nil
+}
+
+// Post builds the address through PostAnyAddress, for the network of the wallet server.
+func (b *AddressBuilder) Post(ctx context.Context, client ClientWithResponsesInterface) (string, error) {
+	body, err := b.Body()
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.PostAnyAddressWithResponse(ctx, body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode() != http.StatusAccepted || resp.JSON202 == nil {
+		return "", unexpectedResponse("PostAnyAddress", resp.HTTPResponse, resp.Body)
+	}
+	return resp.JSON202.Address, nil
+}
+
+// Offline computes the address locally, without contacting the wallet server. The result is the same as the
+// address returned by Post() for a server on the given network (address.NetworkMainnet or address.NetworkTestnet).
+func (b *AddressBuilder) Offline(networkId uint32) (*address.Address, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	var payment, stake *address.Credential
+	var err error
+	if b.payment != nil {
+		if payment, err = b.payment.addressCredential(); err != nil {
+			return nil, err
+		}
+	}
+	if b.stake != nil {
+		if stake, err = b.stake.addressCredential(); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case payment != nil && stake != nil:
+		return address.NewBaseAddress(networkId, payment, stake)
+	case payment != nil:
+		return address.NewEnterpriseAddress(networkId, payment)
+	default:
+		return address.NewRewardAddress(networkId, stake)
+	}
+}
+
+// keyHash returns the blake2b-224 hash of a public key, as used in addresses.
+func keyHash(key []byte) []byte {
+	hash, _ := blake2b.New(keyHashSize, nil) // Only fails for invalid sizes
+	hash.Write(key)
+	return hash.Sum(nil)
+}
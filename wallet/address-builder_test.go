@@ -0,0 +1,98 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/godano/cardano-wallet-client/address"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type AddressBuilderTestSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestAddressBuilder(t *testing.T) {
+	testSuite := new(AddressBuilderTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *AddressBuilderTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+}
+
+// Keys and addresses of the CIP-19 test vectors
+const (
+	testPaymentKey  = "addr_vk1w0l2sr2zgfm26ztc6nl9xy8ghsk5sh6ldwemlpmp9xylzy4dtf7st80zhd"
+	testStakeKey    = "stake_vk1px4j0r2fk7ux5p23shz8f3y5y2qam7s954rgf3lg5merqcj6aetsft99wu"
+	testBaseAddress = "addr1qx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgse35a3x"
+)
+
+func (s *AddressBuilderTestSuite) credentials() (*AddressCredential, *AddressCredential) {
+	payment, err := PublicKeyCredential(testPaymentKey)
+	s.NoError(err)
+	stake, err := PublicKeyCredential(testStakeKey)
+	s.NoError(err)
+	return payment, stake
+}
+
+func (s *AddressBuilderTestSuite) TestOffline() {
+	payment, stake := s.credentials()
+	addr, err := NewAddressBuilder().Payment(payment).Stake(stake).Offline(address.NetworkMainnet)
+	s.NoError(err)
+	s.Equal(testBaseAddress, addr.String())
+
+	addr, err = NewAddressBuilder().Payment(payment).Offline(address.NetworkTestnet)
+	s.NoError(err)
+	s.Equal(address.TypeEnterprise, addr.Type)
+
+	decoded, err := address.Decode(testBaseAddress)
+	s.NoError(err)
+	addr, err = NewAddressBuilder().Stake(KeyHashCredential(decoded.Stake.Hash)).Offline(address.NetworkMainnet)
+	s.NoError(err)
+	s.Equal(address.TypeReward, addr.Type)
+	s.Equal(decoded.Stake.Hash, addr.Stake.Hash)
+
+	_, err = NewAddressBuilder().Payment(ScriptCredential(map[string]interface{}{"any": []string{}})).Offline(address.NetworkMainnet)
+	s.Error(err)
+}
+
+func (s *AddressBuilderTestSuite) TestValidate() {
+	payment, _ := s.credentials()
+	script := ScriptCredential(map[string]interface{}{"any": []string{"script_vkh18srsxr3khll7vl3w9mqfu55n6wzxxlxj7qzr2mhnyreluzt36ms"}})
+	s.Error(NewAddressBuilder().Validate())
+	s.Error(NewAddressBuilder().Payment(payment).Validation(ScriptValidationRequired).Validate())
+	s.Error(NewAddressBuilder().Payment(script).Validation("strict").Validate())
+	s.Error(NewAddressBuilder().Payment(KeyHashCredential([]byte{1, 2, 3})).Validate())
+	s.NoError(NewAddressBuilder().Payment(script).Stake(payment).Validation(ScriptValidationRecommended).Validate())
+
+	// Key hashes can only be used offline
+	_, err := NewAddressBuilder().Payment(KeyHashCredential(make([]byte, keyHashSize))).Body()
+	s.Error(err)
+}
+
+func (s *AddressBuilderTestSuite) TestPost() {
+	var received map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/addresses", func(w http.ResponseWriter, r *http.Request) {
+		s.NoError(json.NewDecoder(r.Body).Decode(&received))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{"address": testBaseAddress})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client, err := NewClientWithResponses(server.URL + "/v2")
+	s.NoError(err)
+
+	payment, stake := s.credentials()
+	addr, err := NewAddressBuilder().Payment(payment).Stake(stake).Post(context.Background(), client)
+	s.NoError(err)
+	s.Equal(testBaseAddress, addr)
+	s.Equal(map[string]interface{}{"payment": testPaymentKey, "stake": testStakeKey}, received)
+}
@@ -0,0 +1,433 @@
+package wallet
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// auditedPrefixes are the prefixes of the Ids of audited operations, see IsAuditedOperation().
+var auditedPrefixes = []string{"Post", "Put", "Patch", "Delete", "Join", "Quit", "Migrate", "Sign"}
+
+// transactionOperations are the operations, whose successful responses contain the created transaction,
+// or a list of transactions for migrations.
+var transactionOperations = map[string]bool{
+	"PostTransaction":         true,
+	"PostByronTransaction":    true,
+	"PostExternalTransaction": true,
+	"JoinStakePool":           true,
+	"QuitStakePool":           true,
+	"MigrateShelleyWallet":    true,
+	"MigrateByronWallet":      true,
+}
+
+// IsAuditedOperation returns true, if requests of the operation with the given Id are recorded by WithAuditLog().
+// These are all operations starting with Post, Put, Patch, Delete, Join, Quit, Migrate or Sign, and all other
+// operations that modify the state of the server (see IsReadOnlyOperation), like CreateAddress.
+func IsAuditedOperation(operationId string) bool {
+	for _, prefix := range auditedPrefixes {
+		if strings.HasPrefix(operationId, prefix) {
+			return true
+		}
+	}
+	return OperationById(operationId) != nil && !IsReadOnlyOperation(operationId)
+}
+
+type callerKey struct{}
+
+// ContextWithCaller returns a context, which identifies the caller of requests in the audit log, see WithAuditLog().
+func ContextWithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext returns the caller stored by ContextWithCaller(), or an empty string.
+func CallerFromContext(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	return caller
+}
+
+// AuditRecord is one line of an audit log. Each record contains the hash of the previous record,
+// so that modified, inserted or removed records are detected by VerifyAuditLog().
+type AuditRecord struct {
+	// Seq numbers the records of a log, starting at 1
+	Seq       uint64    `json:"seq"`
+	Time      time.Time `json:"time"`
+	Caller    string    `json:"caller"`
+	Operation string    `json:"operation"`
+	WalletId  string    `json:"wallet_id,omitempty"`
+	// Body is the request body with passphrases and mnemonic sentences redacted, see Redact().
+	// Bodies that are not JSON, like the binary transactions of PostExternalTransaction, are not recorded.
+	Body json.RawMessage `json:"body,omitempty"`
+	// Status is the HTTP status of the response, 0 if no response was received
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// TxIds are the ids of the transactions created by successful requests, like PostTransaction or JoinStakePool
+	TxIds []string `json:"tx_ids,omitempty"`
+
+	// PrevHash is the Hash of the previous record, empty for the first record
+	PrevHash string `json:"prev_hash"`
+	// Hash is the hex-encoded SHA-256 hash of the JSON encoding of the record without the Hash field.
+	// For logs with a key, it is the HMAC-SHA256 with that key.
+	Hash string `json:"hash,omitempty"`
+}
+
+func (r *AuditRecord) computeHash(key []byte) (string, error) {
+	withoutHash := *r
+	withoutHash.Hash = ""
+	data, err := json.Marshal(&withoutHash)
+	if err != nil {
+		return "", err
+	}
+	if len(key) == 0 {
+		hash := sha256.Sum256(data)
+		return hex.EncodeToString(hash[:]), nil
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// auditLogBlockSize is the size of the blocks read from the end of the log to find the last record.
+const auditLogBlockSize = 4096
+
+// AuditLog is an append-only file of hash-chained AuditRecords, one JSON object per line.
+// Several AuditLogs, also in different processes, can append to the same file: each Append() takes an exclusive
+// file lock and continues the chain after the last record of the file. File locks are only supported on Unix systems,
+// on other systems only one process at a time must write to a file.
+type AuditLog struct {
+	path string
+	key  []byte
+
+	lock sync.Mutex
+	file *os.File
+}
+
+// OpenAuditLog opens the audit log at the given path for appending, or creates it.
+// With a key, the records are chained with HMAC-SHA256 instead of SHA-256, so that only holders of the key can
+// write valid records. The same key must be used for all records of a file, and for VerifyAuditLog().
+// The last record of an existing file must match the key, use VerifyAuditLog() to check all existing records.
+func OpenAuditLog(path string, key []byte) (*AuditLog, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	log := &AuditLog{path: path, key: key, file: file}
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("Failed to lock audit log %v: %v", path, err)
+	}
+	_, err = log.lastRecord()
+	unlockFile(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return log, nil
+}
+
+// ReadAuditLogKey reads the key of an audit log from a source as accepted by ParsePassphraseSource(),
+// like "env:AUDIT_LOG_KEY" or "file:/etc/cardano/audit.key". For an empty source, there is no key.
+func ReadAuditLogKey(ctx context.Context, source string) ([]byte, error) {
+	if source == "" {
+		return nil, nil
+	}
+	provider, err := ParsePassphraseSource(source, "Audit log key: ")
+	if err != nil {
+		return nil, err
+	}
+	if closer, ok := provider.(io.Closer); ok {
+		defer closer.Close()
+	}
+	key, err := provider.Passphrase(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read audit log key: %v", err)
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("The audit log key from %v is empty", source)
+	}
+	return key, nil
+}
+
+// lastRecord reads the last record of the file, or returns nil for an empty file. The file must be locked.
+func (l *AuditLog) lastRecord() (*AuditRecord, error) {
+	info, err := l.file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	// Read blocks from the end of the file, until the last line is complete
+	var tail []byte
+	for offset := info.Size(); offset > 0; {
+		size := int64(auditLogBlockSize)
+		if size > offset {
+			size = offset
+		}
+		offset -= size
+		block := make([]byte, size)
+		if _, err := l.file.ReadAt(block, offset); err != nil && err != io.EOF {
+			return nil, err
+		}
+		tail = append(block, tail...)
+		trimmed := bytes.TrimSpace(tail)
+		start := bytes.LastIndexByte(trimmed, '\n')
+		if start < 0 && offset > 0 {
+			continue
+		}
+		if len(trimmed) == 0 {
+			return nil, nil
+		}
+		record := new(AuditRecord)
+		if err := json.Unmarshal(trimmed[start+1:], record); err != nil || record.Hash == "" {
+			return nil, fmt.Errorf("Audit log %v is corrupt, the last record cannot be parsed", l.path)
+		}
+		if hash, err := record.computeHash(l.key); err != nil || hash != record.Hash {
+			return nil, fmt.Errorf("Audit log %v: the hash of the last record is invalid, the record was modified or the key is wrong", l.path)
+		}
+		return record, nil
+	}
+	return nil, nil
+}
+
+// Path returns the file name of the audit log.
+func (l *AuditLog) Path() string {
+	return l.path
+}
+
+// Append sets the Seq, PrevHash and Hash of the record, and writes it to the log.
+// The file is synced, before Append returns.
+func (l *AuditLog) Append(record *AuditRecord) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.file == nil {
+		return fmt.Errorf("Audit log %v is closed", l.path)
+	}
+	// Other processes may have appended records since the last call, so the chain is continued after the last record
+	// of the file, while holding the file lock
+	if err := lockFile(l.file); err != nil {
+		return fmt.Errorf("Failed to lock audit log %v: %v", l.path, err)
+	}
+	defer unlockFile(l.file)
+	last, err := l.lastRecord()
+	if err != nil {
+		return err
+	}
+	record.Seq, record.PrevHash = 1, ""
+	if last != nil {
+		record.Seq, record.PrevHash = last.Seq+1, last.Hash
+	}
+	hash, err := record.computeHash(l.key)
+	if err != nil {
+		return err
+	}
+	record.Hash = hash
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("Failed to write audit log %v: %v", l.path, err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("Failed to write audit log %v: %v", l.path, err)
+	}
+	return nil
+}
+
+// Close closes the file of the audit log.
+func (l *AuditLog) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// AuditLogError describes the first invalid record found by VerifyAuditLog().
+type AuditLogError struct {
+	Path   string
+	Line   int
+	Reason string
+}
+
+func (e *AuditLogError) Error() string {
+	return fmt.Sprintf("Audit log %v, line %v: %v", e.Path, e.Line, e.Reason)
+}
+
+// AuditLogSummary is the result of a successful VerifyAuditLog().
+type AuditLogSummary struct {
+	Records int
+	// LastSeq and LastHash identify the last record. Storing them elsewhere allows detecting removed records
+	// at the end of the log, which the hash chain cannot detect by itself, see AuditLogAnchor.
+	LastSeq  uint64
+	LastHash string
+}
+
+// AuditLogAnchor is a record of a previous verification, usually its LastSeq and LastHash, stored outside of the log.
+type AuditLogAnchor struct {
+	Seq  uint64
+	Hash string
+}
+
+// VerifyAuditLog checks that all records of the audit log are unmodified, and that no records were inserted or
+// removed, except at the end of the log. The result is an *AuditLogError for invalid records.
+// The key must be the key given to OpenAuditLog(). Without a key, anybody who can write the file can also recompute
+// the hashes of all records after modifying them.
+//
+// If an anchor is given, the log must contain the anchored record unchanged. This detects records removed at the
+// end of the log, and, without a key, recomputed hashes of all records up to the anchor.
+func VerifyAuditLog(path string, key []byte, anchor *AuditLogAnchor) (*AuditLogSummary, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	summary := new(AuditLogSummary)
+	reader := bufio.NewReader(file)
+	for lineNumber := 1; ; lineNumber++ {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return nil, readErr
+		}
+		invalid := func(format string, args ...interface{}) error {
+			return &AuditLogError{Path: path, Line: lineNumber, Reason: fmt.Sprintf(format, args...)}
+		}
+		if readErr == io.EOF && len(line) == 0 {
+			if anchor != nil && summary.LastSeq < anchor.Seq {
+				return nil, invalid("expected the anchored record %v, but the log ends with record %v", anchor.Seq, summary.LastSeq)
+			}
+			return summary, nil
+		}
+		if readErr == io.EOF {
+			return nil, invalid("incomplete record without line break")
+		}
+		line = bytes.TrimSuffix(line, []byte("\n"))
+
+		record := new(AuditRecord)
+		if err := json.Unmarshal(line, record); err != nil {
+			return nil, invalid("failed to parse record: %v", err)
+		}
+		// Re-encoding must reproduce the line exactly, so that added fields or changed formatting are detected
+		encoded, err := json.Marshal(record)
+		if err != nil || !bytes.Equal(encoded, line) {
+			return nil, invalid("record was modified")
+		}
+		hash, err := record.computeHash(key)
+		if err != nil || hash != record.Hash {
+			return nil, invalid("hash mismatch, record %v was modified or the key is wrong", record.Seq)
+		}
+		if record.Seq != summary.LastSeq+1 {
+			return nil, invalid("expected record %v, found record %v", summary.LastSeq+1, record.Seq)
+		}
+		if record.PrevHash != summary.LastHash {
+			return nil, invalid("record %v does not follow the previous record", record.Seq)
+		}
+		if anchor != nil && record.Seq == anchor.Seq && record.Hash != anchor.Hash {
+			return nil, invalid("record %v does not match the anchored hash %v", record.Seq, anchor.Hash)
+		}
+		summary.Records++
+		summary.LastSeq, summary.LastHash = record.Seq, record.Hash
+	}
+}
+
+// WithAuditLog returns a ClientOption that records all audited operations (see IsAuditedOperation) in the given log,
+// after their response was received or the request failed. The caller is taken from the request context,
+// see ContextWithCaller(). If the record cannot be written, the request fails with an error, even if it succeeded.
+//
+// The log is a Middleware (see WithMiddleware()). When given before request limits, only requests that passed
+// the limits are recorded.
+func WithAuditLog(log *AuditLog) ClientOption {
+	return WithMiddleware(&auditMiddleware{log: log})
+}
+
+type auditMiddleware struct {
+	log *AuditLog
+}
+
+func (m *auditMiddleware) Do(req *http.Request, next HttpRequestDoer) (*http.Response, error) {
+	op := OperationForRequest(req)
+	if op == nil || !IsAuditedOperation(op.Id) {
+		return next.Do(req)
+	}
+	record := &AuditRecord{
+		Time:      time.Now().UTC(),
+		Caller:    CallerFromContext(req.Context()),
+		Operation: op.Id,
+		WalletId:  op.PathParameters(req.URL.Path)["walletId"],
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			ZeroPassphrase(body)
+			return nil, err
+		}
+		// The body may contain passphrases, so the copy is zeroed like the original, see WithPassphraseProvider()
+		req.Body = &zeroingBody{Reader: bytes.NewReader(body), data: body}
+		req.GetBody = nil
+		compacted := new(bytes.Buffer)
+		if json.Compact(compacted, Redact(body)) == nil {
+			record.Body = compacted.Bytes()
+		}
+	}
+
+	resp, err := next.Do(req)
+	if err != nil {
+		record.Error = err.Error()
+		if logErr := m.log.Append(record); logErr != nil {
+			return nil, fmt.Errorf("%v (%v)", err, logErr)
+		}
+		return nil, err
+	}
+	record.Status = resp.StatusCode
+	success := resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices
+	if success && transactionOperations[op.Id] {
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			record.Error = fmt.Sprintf("Failed to read response: %v", err)
+		}
+		record.TxIds = transactionIds(data)
+		resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+	}
+	if err := m.log.Append(record); err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%v returned status %v, but the audit log failed: %v", op.Id, resp.Status, err)
+	}
+	return resp, nil
+}
+
+// transactionIds returns the ids of a transaction object, or of a list of transaction objects.
+func transactionIds(data []byte) []string {
+	type transaction struct {
+		Id string `json:"id"`
+	}
+	var single transaction
+	if json.Unmarshal(data, &single) == nil && single.Id != "" {
+		return []string{single.Id}
+	}
+	var list []transaction
+	if json.Unmarshal(data, &list) != nil {
+		return nil
+	}
+	var ids []string
+	for _, tx := range list {
+		if tx.Id != "" {
+			ids = append(ids, tx.Id)
+		}
+	}
+	return ids
+}
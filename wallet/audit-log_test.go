@@ -0,0 +1,320 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditLogTestSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	server *httptest.Server
+	dir    string
+	ctx    context.Context
+}
+
+func TestAuditLog(t *testing.T) {
+	testSuite := new(AuditLogTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *AuditLogTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+	s.ctx = ContextWithCaller(context.Background(), "alice")
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/wallets/w1/transactions":
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"id": "tx1", "status": "pending"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/wallets/w1/migrations":
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`[{"id": "tx2"}, {"id": "tx3"}]`))
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+}
+
+func (s *AuditLogTestSuite) TearDownSuite() {
+	s.server.Close()
+}
+
+func (s *AuditLogTestSuite) SetupTest() {
+	var err error
+	s.dir, err = ioutil.TempDir("", "audit-log")
+	s.NoError(err)
+}
+
+func (s *AuditLogTestSuite) TearDownTest() {
+	os.RemoveAll(s.dir)
+}
+
+func (s *AuditLogTestSuite) readRecords(path string) []string {
+	data, err := ioutil.ReadFile(path)
+	s.NoError(err)
+	lines := strings.SplitAfter(string(data), "\n")
+	return lines[:len(lines)-1] // The last line ends with a line break
+}
+
+// writeLog records some requests in a new audit log and returns its path.
+func (s *AuditLogTestSuite) writeLog() string {
+	path := filepath.Join(s.dir, "audit.jsonl")
+	log, err := OpenAuditLog(path, nil)
+	s.NoError(err)
+	defer log.Close()
+	client, err := NewClient(s.server.URL+"/v2", WithAuditLog(log))
+	s.NoError(err)
+
+	resp, err := client.PostTransactionWithBody(s.ctx, "w1", "application/json",
+		bytes.NewReader([]byte(`{"passphrase": "secret12345", "payments": []}`)))
+	s.NoError(err)
+	body, err := ioutil.ReadAll(resp.Body)
+	s.NoError(err)
+	s.Contains(string(body), "tx1")
+	for _, walletId := range []string{"w1", "w2"} {
+		resp, err = client.DeleteWallet(context.Background(), walletId)
+		s.NoError(err)
+		resp.Body.Close()
+	}
+	resp, err = client.MigrateShelleyWalletWithBody(s.ctx, "w1", "application/json", bytes.NewReader([]byte(`{}`)))
+	s.NoError(err)
+	resp.Body.Close()
+	// Read operations are not recorded
+	resp, err = client.ListWallets(s.ctx)
+	s.NoError(err)
+	resp.Body.Close()
+	return path
+}
+
+func (s *AuditLogTestSuite) TestRecords() {
+	path := s.writeLog()
+	lines := s.readRecords(path)
+	s.Len(lines, 4)
+	s.NotContains(lines[0], "secret12345")
+
+	summary, err := VerifyAuditLog(path, nil, nil)
+	s.NoError(err)
+	s.Equal(4, summary.Records)
+	s.Equal(uint64(4), summary.LastSeq)
+
+	var records []*AuditRecord
+	for _, line := range lines {
+		record := new(AuditRecord)
+		s.NoError(json.Unmarshal([]byte(line), record))
+		records = append(records, record)
+	}
+	first := records[0]
+	s.Equal(uint64(1), first.Seq)
+	s.Equal("alice", first.Caller)
+	s.Equal("PostTransaction", first.Operation)
+	s.Equal("w1", first.WalletId)
+	s.Equal(`{"passphrase":"`+RedactedValue+`","payments":[]}`, string(first.Body))
+	s.Equal(http.StatusAccepted, first.Status)
+	s.Equal([]string{"tx1"}, first.TxIds)
+	s.Empty(first.PrevHash)
+	s.Equal(first.Hash, records[1].PrevHash)
+	s.Equal("", records[1].Caller)
+	s.Equal("DeleteWallet", records[2].Operation)
+	s.Equal("w2", records[2].WalletId)
+	s.Equal(http.StatusNoContent, records[2].Status)
+	s.Equal([]string{"tx2", "tx3"}, records[3].TxIds)
+
+	// Reopening the log continues the chain, failed requests are recorded as well
+	log, err := OpenAuditLog(path, nil)
+	s.NoError(err)
+	closed, err := NewClient("http://localhost:1/v2", WithAuditLog(log))
+	s.NoError(err)
+	_, err = closed.DeleteWallet(s.ctx, "w1")
+	s.Error(err)
+	s.NoError(log.Close())
+	summary, err = VerifyAuditLog(path, nil, nil)
+	s.NoError(err)
+	s.Equal(5, summary.Records)
+	lines = s.readRecords(path)
+	record := new(AuditRecord)
+	s.NoError(json.Unmarshal([]byte(lines[4]), record))
+	s.Equal(0, record.Status)
+	s.NotEmpty(record.Error)
+
+	s.Error(log.Append(new(AuditRecord)))
+}
+
+func (s *AuditLogTestSuite) TestTampering() {
+	path := s.writeLog()
+	lines := s.readRecords(path)
+	check := func(line int, modified ...string) {
+		s.NoError(ioutil.WriteFile(path, []byte(strings.Join(modified, "")), 0600))
+		_, err := VerifyAuditLog(path, nil, nil)
+		s.Error(err)
+		s.IsType(new(AuditLogError), err)
+		s.Equal(line, err.(*AuditLogError).Line, err.Error())
+	}
+
+	check(3, lines[0], lines[1], strings.Replace(lines[2], `"status":204`, `"status":500`, 1), lines[3])
+	check(2, lines[0], strings.Replace(lines[1], `"caller":""`, `"caller":"mallory"`, 1), lines[2], lines[3])
+	check(1, strings.Replace(lines[0], `"seq":1,`, `"seq":1, `, 1), lines[1], lines[2], lines[3])
+	check(2, lines[0], lines[2], lines[3])
+	check(1, lines[1], lines[2], lines[3])
+	check(2, lines[0], lines[2], lines[1], lines[3])
+	check(4, lines[0], lines[1], lines[2], strings.TrimSuffix(lines[3], "\n"))
+	check(5, lines[0], lines[1], lines[2], lines[3], "garbage\n")
+	check(2, lines[0], lines[0], lines[1])
+
+	// Appending to a corrupt log fails
+	s.NoError(ioutil.WriteFile(path, []byte(lines[0]+"garbage\n"), 0600))
+	_, err := OpenAuditLog(path, nil)
+	s.Error(err)
+}
+
+func (s *AuditLogTestSuite) TestConcurrentLogs() {
+	// Two logs of the same file, like two processes, continue the chain of each other
+	path := filepath.Join(s.dir, "audit.jsonl")
+	var logs []*AuditLog
+	for i := 0; i < 2; i++ {
+		log, err := OpenAuditLog(path, nil)
+		s.NoError(err)
+		defer log.Close()
+		logs = append(logs, log)
+	}
+	done := make(chan error)
+	for _, log := range logs {
+		go func(log *AuditLog) {
+			for i := 0; i < 20; i++ {
+				// Records larger than the blocks read from the end of the file
+				body := `"` + strings.Repeat("x", 3*auditLogBlockSize) + `"`
+				if err := log.Append(&AuditRecord{Operation: "PutWallet", Body: json.RawMessage(body)}); err != nil {
+					done <- err
+					return
+				}
+			}
+			done <- nil
+		}(log)
+	}
+	s.NoError(<-done)
+	s.NoError(<-done)
+	summary, err := VerifyAuditLog(path, nil, nil)
+	s.NoError(err)
+	s.Equal(40, summary.Records)
+	s.Equal(uint64(40), summary.LastSeq)
+}
+
+func (s *AuditLogTestSuite) TestKey() {
+	path := filepath.Join(s.dir, "audit.jsonl")
+	key := []byte("audit-key")
+	log, err := OpenAuditLog(path, key)
+	s.NoError(err)
+	s.NoError(log.Append(&AuditRecord{Operation: "DeleteWallet", WalletId: "w1", Status: http.StatusNoContent}))
+	s.NoError(log.Append(&AuditRecord{Operation: "DeleteWallet", WalletId: "w2", Status: http.StatusNoContent}))
+	s.NoError(log.Close())
+
+	summary, err := VerifyAuditLog(path, key, nil)
+	s.NoError(err)
+	s.Equal(2, summary.Records)
+	_, err = VerifyAuditLog(path, nil, nil)
+	s.IsType(new(AuditLogError), err)
+	_, err = VerifyAuditLog(path, []byte("other-key"), nil)
+	s.IsType(new(AuditLogError), err)
+	// Records cannot be appended without the key
+	_, err = OpenAuditLog(path, nil)
+	s.Error(err)
+
+	// Without the key, a modified log cannot be re-hashed to pass the verification
+	var records []*AuditRecord
+	for _, line := range s.readRecords(path) {
+		record := new(AuditRecord)
+		s.NoError(json.Unmarshal([]byte(line), record))
+		records = append(records, record)
+	}
+	records[0].Status = http.StatusInternalServerError
+	var modified []byte
+	prevHash := ""
+	for _, record := range records {
+		record.PrevHash = prevHash
+		record.Hash, err = record.computeHash(nil)
+		s.NoError(err)
+		prevHash = record.Hash
+		line, err := json.Marshal(record)
+		s.NoError(err)
+		modified = append(append(modified, line...), '\n')
+	}
+	s.NoError(ioutil.WriteFile(path, modified, 0600))
+	_, err = VerifyAuditLog(path, nil, nil)
+	s.NoError(err)
+	_, err = VerifyAuditLog(path, key, nil)
+	s.Error(err)
+	s.Equal(1, err.(*AuditLogError).Line)
+}
+
+func (s *AuditLogTestSuite) TestAnchor() {
+	path := s.writeLog()
+	lines := s.readRecords(path)
+	summary, err := VerifyAuditLog(path, nil, nil)
+	s.NoError(err)
+	anchor := &AuditLogAnchor{Seq: summary.LastSeq, Hash: summary.LastHash}
+	_, err = VerifyAuditLog(path, nil, anchor)
+	s.NoError(err)
+
+	// Records after the anchor may be added
+	s.NoError(ioutil.WriteFile(path, []byte(strings.Join(lines[:3], "")), 0600))
+	_, err = VerifyAuditLog(path, nil, &AuditLogAnchor{Seq: 2, Hash: s.recordHash(lines[1])})
+	s.NoError(err)
+
+	// Removed records at the end of the log are detected
+	_, err = VerifyAuditLog(path, nil, anchor)
+	s.IsType(new(AuditLogError), err)
+	s.Equal(4, err.(*AuditLogError).Line, err.Error())
+
+	// Without a key, a log with recomputed hashes passes, unless the anchored record is checked
+	var modified []byte
+	prevHash := ""
+	for _, line := range lines {
+		record := new(AuditRecord)
+		s.NoError(json.Unmarshal([]byte(line), record))
+		record.Caller = "mallory"
+		record.PrevHash = prevHash
+		record.Hash, err = record.computeHash(nil)
+		s.NoError(err)
+		prevHash = record.Hash
+		encoded, err := json.Marshal(record)
+		s.NoError(err)
+		modified = append(append(modified, encoded...), '\n')
+	}
+	s.NoError(ioutil.WriteFile(path, modified, 0600))
+	_, err = VerifyAuditLog(path, nil, nil)
+	s.NoError(err)
+	_, err = VerifyAuditLog(path, nil, anchor)
+	s.IsType(new(AuditLogError), err)
+	s.Equal(4, err.(*AuditLogError).Line, err.Error())
+}
+
+func (s *AuditLogTestSuite) recordHash(line string) string {
+	record := new(AuditRecord)
+	s.NoError(json.Unmarshal([]byte(line), record))
+	return record.Hash
+}
+
+func (s *AuditLogTestSuite) TestAuditedOperations() {
+	for _, id := range []string{"PostTransaction", "PutWallet", "PatchSharedWalletInPayment", "DeleteWallet",
+		"JoinStakePool", "QuitStakePool", "MigrateByronWallet", "SignMetadata", "CreateAddress", "ImportAddresses",
+		"PostTransactionFee"} {
+		s.True(IsAuditedOperation(id), id)
+	}
+	for _, id := range []string{"ListWallets", "GetWallet", "SelectCoins", "GetNetworkInformation", "NoSuchOperation"} {
+		s.False(IsAuditedOperation(id), id)
+	}
+}
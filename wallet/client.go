@@ -5,11 +5,7 @@ import (
 	"crypto/x509"
 	"fmt"
 	"io/ioutil"
-	"net"
 	"net/http"
-	"os"
-	"strconv"
-	"time"
 )
 
 // These env-var names are defined as `var` and not `const` on purpose,
@@ -20,12 +16,10 @@ var (
 	EnvClientCertFile = "GODANO_WALLET_CLIENT_CLIENT_CERT"
 	EnvClientKeyFile  = "GODANO_WALLET_CLIENT_CLIENT_KEY"
 
-	// This env-var is not evaluated by MakeTLSConfig(), but in client_test.go and cmd/godano-wallet-cli
+	// This env-var is not evaluated by MakeTLSConfig(), but by LoadProfile(), in client_test.go and cmd/godano-wallet-cli
 	EnvVarWalletServerAddress = "GODANO_WALLET_CLIENT_SERVER_ADDRESS"
 )
 
-const connTimeout = 5 * time.Minute // High timeout for some long-running requests
-
 // NewHTTPSClient returns a `Client` with the given TLS configuration.
 func NewHTTPSClient(server string, tlsConfig *tls.Config) (*Client, error) {
 	return NewClient(server, WithHTTPSClient(tlsConfig))
@@ -37,67 +31,36 @@ func NewHTTPSClientWithResponses(server string, tlsConfig *tls.Config) (*ClientW
 }
 
 // WithHTTPSClient returns a `ClientOption` that sets the given TLS configuration on clients.
+// The other transport settings are taken from DefaultTransportConfig(), and requests are limited by DefaultOperationTimeouts().
+// See Dial() for more options.
 func WithHTTPSClient(tlsConfig *tls.Config) ClientOption {
-	// Default Transport values copied from http package, TLSClientConfig modified. Avoid copying http.DefaultTransport.
-	return WithHTTPClient(&http.Client{
-		Transport: &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-			DialContext: (&net.Dialer{
-				Timeout:   connTimeout,
-				KeepAlive: connTimeout,
-				DualStack: true,
-			}).DialContext,
-			ForceAttemptHTTP2:     true,
-			MaxIdleConns:          100,
-			IdleConnTimeout:       connTimeout,
-			TLSHandshakeTimeout:   10 * time.Second,
-			ExpectContinueTimeout: 1 * time.Second,
-			TLSClientConfig:       tlsConfig,
-		},
-	})
+	config := DefaultTransportConfig()
+	config.TLSConfig = tlsConfig
+	setClient := WithHTTPClient(&http.Client{Transport: config.newTransport("")})
+	return func(c *Client) error {
+		if err := setClient(c); err != nil {
+			return err
+		}
+		return WithOperationTimeouts(nil)(c)
+	}
 }
 
 // MakeTLSConfig creates a *tls.Config objects based on the GODANO_WALLET_CLIENT_* environment
 // variables defined above.
 func MakeTLSConfig() (*tls.Config, error) {
-	tlsConfig := new(tls.Config)
-
-	if skipVerifyStr := os.Getenv(EnvTLSSkipVerify); skipVerifyStr != "" {
-		skipVerify, err := strconv.ParseBool(skipVerifyStr)
-		if err != nil {
-			return nil, fmt.Errorf("Failed to parse env-var %v=%v as bool: %v",
-				EnvTLSSkipVerify, skipVerifyStr, err)
-		} else {
-			tlsConfig.InsecureSkipVerify = skipVerify
-		}
-	}
-
-	// Load server CA
-	if serverCAFile := os.Getenv(EnvServerCAFile); serverCAFile != "" {
-		caRootPool, err := LoadCACert(serverCAFile)
-		if err != nil {
-			return nil, fmt.Errorf("Failed to load server CA file '%v': %v", serverCAFile, err)
-		}
-		tlsConfig.RootCAs = caRootPool
+	profile := new(Profile)
+	if err := profile.applyEnv(); err != nil {
+		return nil, err
 	}
+	return profile.TLSConfig()
+}
 
-	// Load client certificate
-	clientCertFile := os.Getenv(EnvClientCertFile)
-	clientKeyFile := os.Getenv(EnvClientKeyFile)
-	// Check that either both variables are defined, or none of them
-	if (clientCertFile == "") != (clientKeyFile == "") {
-		return nil, fmt.Errorf("Either none or both of these env-vars must be defined: %v, %v",
-			EnvClientCertFile, EnvClientKeyFile)
+// unexpectedResponse returns an error describing an unsuccessful response to the given operation.
+func unexpectedResponse(operation string, resp *http.Response, body []byte) error {
+	if resp == nil {
+		return fmt.Errorf("%v: no response received", operation)
 	}
-	if clientCertFile != "" {
-		cert, err := tls.LoadX509KeyPair(clientCertFile, clientKeyFile)
-		if err != nil {
-			return nil, err
-		}
-		tlsConfig.Certificates = []tls.Certificate{cert}
-	}
-
-	return tlsConfig, nil
+	return fmt.Errorf("%v: unexpected response status %v: %s", operation, resp.Status, Redact(body))
 }
 
 // LoadCACert loads the given server certificate into a certificate pool, which can be
@@ -187,8 +187,8 @@ func (s *CardanoWalletTestSuite) TestGetCurrentSmashHealth() {
 	s.logObject("GetCurrentSmashHealth", resp.JSON200)
 }
 
-// TODO this currently fails with "unexpected EOF"
-// Probably too long-running?
+// TODO this used to fail with "unexpected EOF", probably because the request is too long-running.
+// ListStakePools now has the LongOperationTimeout (see DefaultOperationTimeouts), check again against a synced server.
 // func (s *CardanoWalletTestSuite) TestListStakePools() {
 // 	resp, err := s.client.ListStakePoolsWithResponse(s.ctx,
 // 		&ListStakePoolsParams{Stake: 100 * 1000 * 1000}) // 100 Ada
@@ -0,0 +1,79 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+)
+
+// Networks of Daedalus installations, see DiscoverDaedalus()
+const (
+	DaedalusMainnet = "mainnet"
+	DaedalusTestnet = "testnet"
+	DaedalusFlight  = "flight"
+)
+
+// DaedalusNetworks contains all networks supported by DiscoverDaedalus().
+var DaedalusNetworks = []string{DaedalusMainnet, DaedalusTestnet, DaedalusFlight}
+
+// DaedalusInstance describes a cardano-wallet process started by Daedalus.
+type DaedalusInstance struct {
+	Network string
+
+	// StateDir is the Daedalus state directory of the network, e.g. ~/.local/share/Daedalus/mainnet
+	StateDir string
+
+	// Pid is the process id of the cardano-wallet process
+	Pid int
+
+	// Server is the API URL of the cardano-wallet process, e.g. https://localhost:35012/v2
+	Server string
+
+	ServerCA   string
+	ClientCert string
+	ClientKey  string
+}
+
+// Profile returns a connection profile for the instance.
+func (d *DaedalusInstance) Profile() *Profile {
+	return &Profile{
+		Name:       "daedalus-" + d.Network,
+		Server:     d.Server,
+		ServerCA:   d.ServerCA,
+		ClientCert: d.ClientCert,
+		ClientKey:  d.ClientKey,
+	}
+}
+
+// MergeProfile returns the connection profile of the instance (see Profile()), with the other settings of the given
+// profile: timeouts, default wallet, output format and audit log. The given profile selects no server in this case.
+func (d *DaedalusInstance) MergeProfile(profile *Profile) *Profile {
+	result := d.Profile()
+	result.DialTimeout, result.Timeout, result.OperationTimeouts = profile.DialTimeout, profile.Timeout, profile.OperationTimeouts
+	result.DefaultWallet, result.Output = profile.DefaultWallet, profile.Output
+	result.AuditLog, result.AuditLogKeyFrom = profile.AuditLog, profile.AuditLogKeyFrom
+	return result
+}
+
+// ConnectDaedalus discovers the cardano-wallet process of the Daedalus installation for the given network
+// (see DiscoverDaedalus) and returns a client for it. The connection is checked with GetNetworkInformation.
+// The given options are applied after the TLS options of the instance.
+func ConnectDaedalus(ctx context.Context, network string, opts ...ClientOption) (*ClientWithResponses, *DaedalusInstance, error) {
+	instance, err := DiscoverDaedalus(network)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := instance.Profile().Dial(ctx, WithClientOptions(opts...))
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, instance, nil
+}
+
+func checkDaedalusNetwork(network string) error {
+	for _, known := range DaedalusNetworks {
+		if network == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("Unknown Daedalus network %v, expected one of %v", network, DaedalusNetworks)
+}
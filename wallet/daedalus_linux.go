@@ -0,0 +1,150 @@
+package wallet
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The locations are variables, so they can be replaced in tests.
+var (
+	procDir = "/proc"
+
+	// daedalusDataDir returns the directory containing the Daedalus state directories of all networks.
+	daedalusDataDir = func() (string, error) {
+		if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+			return filepath.Join(dataHome, "Daedalus"), nil
+		}
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, ".local", "share", "Daedalus"), nil
+	}
+)
+
+// daedalusStateDirs are the names of the state directories inside the Daedalus data directory.
+var daedalusStateDirs = map[string]string{
+	DaedalusMainnet: "mainnet",
+	DaedalusTestnet: "testnet",
+	DaedalusFlight:  "mainnet_flight",
+}
+
+// DiscoverDaedalus finds the cardano-wallet process started by Daedalus for the given network (DaedalusMainnet,
+// DaedalusTestnet or DaedalusFlight). The TLS certificates are taken from the tls/ sub-directory of the Daedalus state
+// directory, and the port is read from the command line of the cardano-wallet process that uses the state directory.
+// Discovery is only supported on Linux.
+func DiscoverDaedalus(network string) (*DaedalusInstance, error) {
+	if err := checkDaedalusNetwork(network); err != nil {
+		return nil, err
+	}
+	dataDir, err := daedalusDataDir()
+	if err != nil {
+		return nil, err
+	}
+	instance := &DaedalusInstance{
+		Network:  network,
+		StateDir: filepath.Join(dataDir, daedalusStateDirs[network]),
+	}
+	if _, err := os.Stat(instance.StateDir); err != nil {
+		return nil, fmt.Errorf("Daedalus %v is not installed: %v", network, err)
+	}
+	if err := instance.findTLSFiles(); err != nil {
+		return nil, err
+	}
+	if err := instance.findProcess(); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// findTLSFiles looks for the CA certificate and the client certificate and key below <state dir>/tls.
+func (d *DaedalusInstance) findTLSFiles() error {
+	tlsDir := filepath.Join(d.StateDir, "tls")
+	for _, file := range []struct {
+		target     *string
+		candidates []string
+	}{
+		{&d.ServerCA, []string{"server/ca.crt", "ca.crt"}},
+		{&d.ClientCert, []string{"client/client.crt", "client/client.pem", "client.pem"}},
+		{&d.ClientKey, []string{"client/client.key", "client.key"}},
+	} {
+		for _, candidate := range file.candidates {
+			path := filepath.Join(tlsDir, filepath.FromSlash(candidate))
+			if _, err := os.Stat(path); err == nil {
+				*file.target = path
+				break
+			}
+		}
+		if *file.target == "" {
+			return fmt.Errorf("Daedalus TLS file not found in %v, tried %v", tlsDir, file.candidates)
+		}
+	}
+	return nil
+}
+
+// findProcess scans the running processes for a cardano-wallet process, which refers to the state directory
+// in its arguments (e.g. --database or --tls-ca-cert), and reads its --port and --listen-address arguments.
+func (d *DaedalusInstance) findProcess() error {
+	entries, err := ioutil.ReadDir(procDir)
+	if err != nil {
+		return fmt.Errorf("Failed to list processes: %v", err)
+	}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		cmdline, err := ioutil.ReadFile(filepath.Join(procDir, entry.Name(), "cmdline"))
+		if err != nil || len(cmdline) == 0 {
+			continue // The process exited or belongs to a different user
+		}
+		args := strings.Split(string(bytes.TrimRight(cmdline, "\x00")), "\x00")
+		if !strings.HasPrefix(filepath.Base(args[0]), "cardano-wallet") || !d.refersToStateDir(args[1:]) {
+			continue
+		}
+		port := argumentValue(args, "--port")
+		if port == "" {
+			return fmt.Errorf("Daedalus cardano-wallet process %v has no --port argument", pid)
+		}
+		host := argumentValue(args, "--listen-address")
+		if host == "" || host == "127.0.0.1" || host == "0.0.0.0" || host == "::1" || host == "::" {
+			host = "localhost" // The Daedalus certificates are issued for localhost
+		}
+		d.Pid = pid
+		d.Server = "https://" + net.JoinHostPort(host, port) + "/v2"
+		return nil
+	}
+	return fmt.Errorf("No running cardano-wallet process found for Daedalus %v (%v)", d.Network, d.StateDir)
+}
+
+func (d *DaedalusInstance) refersToStateDir(args []string) bool {
+	prefix := d.StateDir + string(filepath.Separator)
+	for _, arg := range args {
+		if i := strings.Index(arg, "="); i >= 0 {
+			arg = arg[i+1:]
+		}
+		if arg == d.StateDir || strings.HasPrefix(arg, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// argumentValue returns the value of a command line argument in the form "--name value" or "--name=value".
+func argumentValue(args []string, name string) string {
+	for i, arg := range args {
+		if arg == name && i+1 < len(args) {
+			return args[i+1]
+		}
+		if strings.HasPrefix(arg, name+"=") {
+			return arg[len(name)+1:]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type DaedalusTestSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	dir string
+
+	origProcDir         string
+	origDaedalusDataDir func() (string, error)
+}
+
+func TestDaedalus(t *testing.T) {
+	testSuite := new(DaedalusTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *DaedalusTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+	s.origProcDir, s.origDaedalusDataDir = procDir, daedalusDataDir
+}
+
+func (s *DaedalusTestSuite) SetupTest() {
+	var err error
+	s.dir, err = ioutil.TempDir("", "daedalus-test")
+	s.NoError(err)
+	procDir = filepath.Join(s.dir, "proc")
+	daedalusDataDir = func() (string, error) {
+		return filepath.Join(s.dir, "Daedalus"), nil
+	}
+	for _, file := range []string{"tls/server/ca.crt", "tls/client/client.crt", "tls/client/client.key"} {
+		s.writeFile(filepath.Join("Daedalus/mainnet", file), "")
+	}
+	s.writeFile("Daedalus/testnet/tls/ca.crt", "")
+}
+
+func (s *DaedalusTestSuite) TearDownTest() {
+	os.RemoveAll(s.dir)
+}
+
+func (s *DaedalusTestSuite) TearDownSuite() {
+	procDir, daedalusDataDir = s.origProcDir, s.origDaedalusDataDir
+}
+
+func (s *DaedalusTestSuite) writeFile(name string, content string) {
+	path := filepath.Join(s.dir, filepath.FromSlash(name))
+	s.NoError(os.MkdirAll(filepath.Dir(path), 0700))
+	s.NoError(ioutil.WriteFile(path, []byte(content), 0600))
+}
+
+func (s *DaedalusTestSuite) addProcess(pid string, args ...string) {
+	s.writeFile(filepath.Join("proc", pid, "cmdline"), strings.Join(args, "\x00")+"\x00")
+}
+
+func (s *DaedalusTestSuite) TestDiscover() {
+	stateDir := filepath.Join(s.dir, "Daedalus", "mainnet")
+	s.addProcess("100", "/usr/bin/cardano-node", "run", "--database-path", stateDir+"/chain")
+	s.addProcess("101", "/opt/daedalus/cardano-wallet", "serve", "--port", "4242", "--database", "/other/wallets")
+	s.addProcess("102", "/opt/daedalus/cardano-wallet", "serve", "--port=35012", "--database", stateDir+"/wallets")
+	s.addProcess("self")
+
+	instance, err := DiscoverDaedalus(DaedalusMainnet)
+	s.NoError(err)
+	s.Equal(&DaedalusInstance{
+		Network:    DaedalusMainnet,
+		StateDir:   stateDir,
+		Pid:        102,
+		Server:     "https://localhost:35012/v2",
+		ServerCA:   filepath.Join(stateDir, "tls/server/ca.crt"),
+		ClientCert: filepath.Join(stateDir, "tls/client/client.crt"),
+		ClientKey:  filepath.Join(stateDir, "tls/client/client.key"),
+	}, instance)
+	s.Equal("https://localhost:35012/v2", instance.Profile().Server)
+
+	merged := instance.MergeProfile(&Profile{Name: "local", Server: "http://localhost:8090/v2", Timeout: Duration(time.Minute), DefaultWallet: "w1"})
+	s.Equal(&Profile{
+		Name:          "daedalus-mainnet",
+		Server:        "https://localhost:35012/v2",
+		ServerCA:      instance.ServerCA,
+		ClientCert:    instance.ClientCert,
+		ClientKey:     instance.ClientKey,
+		Timeout:       Duration(time.Minute),
+		DefaultWallet: "w1",
+	}, merged)
+}
+
+func (s *DaedalusTestSuite) TestNotFound() {
+	_, err := DiscoverDaedalus("preview")
+	s.Error(err)
+
+	// Not installed
+	_, err = DiscoverDaedalus(DaedalusFlight)
+	s.Error(err)
+
+	// Incomplete TLS files
+	_, err = DiscoverDaedalus(DaedalusTestnet)
+	s.Error(err)
+	s.Contains(err.Error(), "TLS file not found")
+
+	// No process
+	s.NoError(os.MkdirAll(procDir, 0700))
+	_, err = DiscoverDaedalus(DaedalusMainnet)
+	s.Error(err)
+	s.Contains(err.Error(), "No running cardano-wallet process")
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+// +build !linux
+
+package wallet
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// DiscoverDaedalus finds the cardano-wallet process started by Daedalus for the given network.
+// Discovery is only supported on Linux, on other systems it always fails.
+func DiscoverDaedalus(network string) (*DaedalusInstance, error) {
+	if err := checkDaedalusNetwork(network); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("Daedalus discovery is not supported on %v", runtime.GOOS)
+}
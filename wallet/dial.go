@@ -0,0 +1,232 @@
+package wallet
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultUnixSocketBasePath is the API path used for unix:// targets, unless the target sets the "path" query parameter.
+const DefaultUnixSocketBasePath = "/v2"
+
+// TransportConfig holds the connection settings of the HTTP transport created by Dial.
+type TransportConfig struct {
+	// DialTimeout limits establishing TCP or Unix socket connections
+	DialTimeout time.Duration
+	// TLSHandshakeTimeout limits the TLS handshake of https:// targets
+	TLSHandshakeTimeout time.Duration
+	// KeepAlive is the interval of TCP keep-alive probes, a negative value disables them
+	KeepAlive time.Duration
+	// IdleConnTimeout closes idle connections after the given time
+	IdleConnTimeout time.Duration
+	// MaxIdleConns limits the number of idle connections, 0 means no limit
+	MaxIdleConns int
+	// MaxConnsPerHost limits the number of connections to the server, 0 means no limit
+	MaxConnsPerHost int
+	// TLSConfig is used for https:// targets. If nil, the system certificate pool is used.
+	TLSConfig *tls.Config
+}
+
+// DefaultTransportConfig returns the transport settings used by Dial and WithHTTPSClient, unless configured otherwise.
+func DefaultTransportConfig() TransportConfig {
+	return TransportConfig{
+		DialTimeout:         30 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+		KeepAlive:           30 * time.Second,
+		IdleConnTimeout:     90 * time.Second,
+		MaxIdleConns:        100,
+	}
+}
+
+// newTransport returns a transport with the settings of the config. If unixSocket is not empty,
+// all connections are made to the given Unix domain socket, independent of the request URL.
+func (c *TransportConfig) newTransport(unixSocket string) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   c.DialTimeout,
+		KeepAlive: c.KeepAlive,
+	}
+	// Default Transport values copied from http package. Avoid copying http.DefaultTransport.
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          c.MaxIdleConns,
+		MaxConnsPerHost:       c.MaxConnsPerHost,
+		IdleConnTimeout:       c.IdleConnTimeout,
+		TLSHandshakeTimeout:   c.TLSHandshakeTimeout,
+		ExpectContinueTimeout: 1 * time.Second,
+		TLSClientConfig:       c.TLSConfig,
+	}
+	if unixSocket != "" {
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", unixSocket)
+		}
+	}
+	return transport
+}
+
+// DialOption configures Dial.
+type DialOption func(*dialConfig)
+
+type dialConfig struct {
+	transport      TransportConfig
+	requestTimeout time.Duration
+	clientOpts     []ClientOption
+	probe          bool
+}
+
+// WithTransportConfig replaces all transport settings.
+func WithTransportConfig(config TransportConfig) DialOption {
+	return func(c *dialConfig) {
+		c.transport = config
+	}
+}
+
+// WithDialTimeout sets TransportConfig.DialTimeout.
+func WithDialTimeout(timeout time.Duration) DialOption {
+	return func(c *dialConfig) {
+		c.transport.DialTimeout = timeout
+	}
+}
+
+// WithTLSHandshakeTimeout sets TransportConfig.TLSHandshakeTimeout.
+func WithTLSHandshakeTimeout(timeout time.Duration) DialOption {
+	return func(c *dialConfig) {
+		c.transport.TLSHandshakeTimeout = timeout
+	}
+}
+
+// WithKeepAlive sets TransportConfig.KeepAlive.
+func WithKeepAlive(interval time.Duration) DialOption {
+	return func(c *dialConfig) {
+		c.transport.KeepAlive = interval
+	}
+}
+
+// WithMaxConnsPerHost sets TransportConfig.MaxConnsPerHost.
+func WithMaxConnsPerHost(max int) DialOption {
+	return func(c *dialConfig) {
+		c.transport.MaxConnsPerHost = max
+	}
+}
+
+// WithTLSConfig sets TransportConfig.TLSConfig, for example to the result of MakeTLSConfig().
+func WithTLSConfig(tlsConfig *tls.Config) DialOption {
+	return func(c *dialConfig) {
+		c.transport.TLSConfig = tlsConfig
+	}
+}
+
+// WithRequestTimeout limits entire requests of all operations, including reading the response body.
+// By default, only the operation timeouts apply, see WithOperationTimeouts().
+func WithRequestTimeout(timeout time.Duration) DialOption {
+	return func(c *dialConfig) {
+		c.requestTimeout = timeout
+	}
+}
+
+// WithClientOptions adds options for the created client, for example WithNetworkGuard() or WithOperationTimeouts().
+// They are applied after the HTTP client of Dial and the default operation timeouts are set.
+func WithClientOptions(opts ...ClientOption) DialOption {
+	return func(c *dialConfig) {
+		c.clientOpts = append(c.clientOpts, opts...)
+	}
+}
+
+// WithoutProbe disables the GetNetworkInformation request of Dial, so no connection is made before the first request.
+func WithoutProbe() DialOption {
+	return func(c *dialConfig) {
+		c.probe = false
+	}
+}
+
+// Dial returns a client for the cardano-wallet server at the given target. The target is either an http:// or https://
+// URL of the API (e.g. https://localhost:8090/v2), or the path of a Unix domain socket (e.g. unix:///run/cardano-wallet.sock).
+// For Unix sockets, the API path is DefaultUnixSocketBasePath, unless set with the "path" query parameter
+// (e.g. unix:///run/cardano-wallet.sock?path=/api/v2).
+//
+// Unless WithoutProbe() is given, Dial checks that the server is reachable with a GetNetworkInformation request.
+// The requests of the client are limited by DefaultOperationTimeouts().
+func Dial(ctx context.Context, target string, opts ...DialOption) (*ClientWithResponses, error) {
+	client, err := DialClient(ctx, target, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientWithResponses{ClientInterface: client}, nil
+}
+
+// DialClient is like Dial, but returns a *Client.
+func DialClient(ctx context.Context, target string, opts ...DialOption) (*Client, error) {
+	config := &dialConfig{transport: DefaultTransportConfig(), probe: true}
+	for _, opt := range opts {
+		opt(config)
+	}
+	server, unixSocket, err := parseTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := &http.Client{
+		Transport: config.transport.newTransport(unixSocket),
+		Timeout:   config.requestTimeout,
+	}
+	clientOpts := []ClientOption{WithHTTPClient(httpClient), WithOperationTimeouts(nil)}
+	client, err := NewClient(server, append(clientOpts, config.clientOpts...)...)
+	if err != nil {
+		return nil, err
+	}
+	if config.probe {
+		if err := probe(ctx, client); err != nil {
+			return nil, fmt.Errorf("cardano-wallet at %v is not reachable: %v", target, err)
+		}
+	}
+	return client, nil
+}
+
+// parseTarget returns the server URL for the generated client, and the socket path for unix:// targets.
+func parseTarget(target string) (server string, unixSocket string, err error) {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return "", "", fmt.Errorf("Invalid target %v: %v", target, err)
+	}
+	switch parsed.Scheme {
+	case "http", "https":
+		if parsed.Host == "" {
+			return "", "", fmt.Errorf("Missing host in target %v", target)
+		}
+		return target, "", nil
+	case "unix":
+		socket := parsed.Path
+		if socket == "" {
+			socket = parsed.Opaque // unix:relative/path.sock
+		}
+		if socket == "" {
+			return "", "", fmt.Errorf("Missing socket path in target %v", target)
+		}
+		basePath := parsed.Query().Get("path")
+		if basePath == "" {
+			basePath = DefaultUnixSocketBasePath
+		}
+		// The host name is not used for connecting, but it is sent in the Host header
+		return "http://localhost/" + strings.TrimPrefix(basePath, "/"), socket, nil
+	}
+	return "", "", fmt.Errorf("Unsupported target %v, expected an http://, https:// or unix:// URL", target)
+}
+
+// probe sends the cheap GetNetworkInformation request.
+func probe(ctx context.Context, client *Client) error {
+	resp, err := client.GetNetworkInformation(ctx)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GetNetworkInformation: unexpected response status %v", resp.Status)
+	}
+	return nil
+}
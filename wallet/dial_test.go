@@ -0,0 +1,125 @@
+package wallet
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type DialTestSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	handler http.Handler
+	paths   []string
+	status  int
+}
+
+func TestDial(t *testing.T) {
+	testSuite := new(DialTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *DialTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+	s.handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.paths = append(s.paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(s.status)
+		w.Write([]byte("{}"))
+	})
+}
+
+func (s *DialTestSuite) SetupTest() {
+	s.paths = nil
+	s.status = http.StatusOK
+}
+
+func (s *DialTestSuite) TestHTTP() {
+	server := httptest.NewServer(s.handler)
+	defer server.Close()
+
+	client, err := Dial(context.Background(), server.URL+"/v2")
+	s.NoError(err)
+	s.Equal([]string{"/v2/network/information"}, s.paths)
+
+	resp, err := client.ListWallets(context.Background())
+	s.NoError(err)
+	resp.Body.Close()
+	s.Equal(http.StatusOK, resp.StatusCode)
+	s.Equal([]string{"/v2/network/information", "/v2/wallets"}, s.paths)
+}
+
+func (s *DialTestSuite) TestUnixSocket() {
+	dir, err := ioutil.TempDir("", "dial-test")
+	s.NoError(err)
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "wallet.sock")
+	listener, err := net.Listen("unix", socket)
+	s.NoError(err)
+	server := &http.Server{Handler: s.handler}
+	go server.Serve(listener)
+	defer server.Close()
+
+	client, err := Dial(context.Background(), "unix://"+socket)
+	s.NoError(err)
+	resp, err := client.ListWallets(context.Background())
+	s.NoError(err)
+	resp.Body.Close()
+	s.Equal([]string{"/v2/network/information", "/v2/wallets"}, s.paths)
+
+	s.paths = nil
+	client, err = Dial(context.Background(), "unix://"+socket+"?path=/api/v2", WithoutProbe())
+	s.NoError(err)
+	s.Empty(s.paths)
+	resp, err = client.ListWallets(context.Background())
+	s.NoError(err)
+	resp.Body.Close()
+	s.Equal([]string{"/api/v2/wallets"}, s.paths)
+}
+
+func (s *DialTestSuite) TestProbeFailure() {
+	server := httptest.NewServer(s.handler)
+	defer server.Close()
+
+	s.status = http.StatusServiceUnavailable
+	_, err := Dial(context.Background(), server.URL+"/v2")
+	s.Error(err)
+	s.Contains(err.Error(), "not reachable")
+
+	_, err = Dial(context.Background(), "unix:///does/not/exist.sock")
+	s.Error(err)
+
+	_, err = Dial(context.Background(), "unix:///does/not/exist.sock", WithoutProbe())
+	s.NoError(err)
+}
+
+func (s *DialTestSuite) TestParseTarget() {
+	server, socket, err := parseTarget("https://localhost:8090/v2")
+	s.NoError(err)
+	s.Equal("https://localhost:8090/v2", server)
+	s.Empty(socket)
+
+	server, socket, err = parseTarget("unix:///run/cardano-wallet.sock")
+	s.NoError(err)
+	s.Equal("http://localhost/v2", server)
+	s.Equal("/run/cardano-wallet.sock", socket)
+
+	server, socket, err = parseTarget("unix:wallet.sock?path=/api/v2")
+	s.NoError(err)
+	s.Equal("http://localhost/api/v2", server)
+	s.Equal("wallet.sock", socket)
+
+	for _, target := range []string{"localhost:8090", "ftp://localhost/v2", "http:///v2", "unix://", "%zz"} {
+		_, _, err = parseTarget(target)
+		s.Error(err, target)
+	}
+}
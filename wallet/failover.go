@@ -0,0 +1,547 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// readOnlyPostOperations are POST operations that do not modify the state of the server, so they can be routed to any replica.
+var readOnlyPostOperations = map[string]bool{
+	"PostTransactionFee":      true,
+	"PostByronTransactionFee": true,
+	"SelectCoins":             true,
+	"ByronSelectCoins":        true,
+	"PostAnyAddress":          true,
+}
+
+// IsReadOnlyOperation returns true, if the operation with the given Id does not modify the state of the server.
+// These are all GET requests, and some POST requests like PostTransactionFee.
+func IsReadOnlyOperation(operationId string) bool {
+	if readOnlyPostOperations[operationId] {
+		return true
+	}
+	op := OperationById(operationId)
+	return op != nil && op.Method == http.MethodGet
+}
+
+// States of the circuit breaker of an endpoint, see EndpointStatus
+const (
+	// BreakerClosed endpoints receive requests
+	BreakerClosed = "closed"
+	// BreakerOpen endpoints failed repeatedly and do not receive requests, until the open timeout expires
+	BreakerOpen = "open"
+	// BreakerHalfOpen endpoints receive a single trial request, which closes or re-opens the breaker
+	BreakerHalfOpen = "half-open"
+)
+
+// Reasons of routing decisions, see RouteDecision
+const (
+	RoutePrimary  = "primary"    // mutating operation, routed to the primary endpoint
+	RouteHealthy  = "healthiest" // read-only operation, routed to the in-sync endpoint with the highest node tip
+	RouteUnsynced = "unsynced"   // read-only operation, but no endpoint is in sync
+	RouteRetry    = "retry"      // read-only operation, retried after the previous endpoint failed
+	RouteRejected = "rejected"   // no endpoint is available
+)
+
+// RouteDecision describes where the FailoverClient sends a request, see WithOnRoute().
+type RouteDecision struct {
+	Operation string
+	ReadOnly  bool
+	// Endpoint is the target of the selected endpoint, empty if the request was rejected
+	Endpoint string
+	Reason   string
+	// Attempt counts the endpoints tried for the request, starting at 1
+	Attempt int
+}
+
+// EndpointStatus is the last known state of an endpoint of a FailoverClient.
+type EndpointStatus struct {
+	Target  string
+	Primary bool
+
+	// SyncStatus is the sync_progress status reported by GetNetworkInformation (WalletStatusReady, WalletStatusSyncing
+	// or WalletStatusNotResponding), or empty if the last health check failed or no check was made yet.
+	SyncStatus string
+	// SyncProgress is the synchronization progress in percent
+	SyncProgress float64
+	// TipSlot is the absolute slot number of the node tip
+	TipSlot int
+
+	Breaker     string
+	LastCheck   time.Time
+	LastFailure error
+}
+
+// InSync returns true, if the node of the endpoint is synchronized with the network.
+func (s *EndpointStatus) InSync() bool {
+	return s.SyncStatus == WalletStatusReady
+}
+
+// NoEndpointError is returned by FailoverClient requests, when no endpoint is available for the operation.
+type NoEndpointError struct {
+	Operation string
+	// Primary is set for mutating operations, which are only sent to the primary endpoint
+	Primary string
+}
+
+func (e *NoEndpointError) Error() string {
+	if e.Primary != "" {
+		return fmt.Sprintf("%v: primary endpoint %v is unavailable, switch the primary with SetPrimary()", e.Operation, e.Primary)
+	}
+	return fmt.Sprintf("%v: no endpoint is available", e.Operation)
+}
+
+// FailoverOption configures NewFailoverClient.
+type FailoverOption func(*failoverConfig)
+
+type failoverConfig struct {
+	dialOpts            []DialOption
+	clientOpts          []ClientOption
+	primary             string
+	healthCheckInterval time.Duration
+	healthCheckTimeout  time.Duration
+	failureThreshold    int
+	openTimeout         time.Duration
+	onRoute             func(RouteDecision)
+}
+
+// WithEndpointDialOptions sets the options used to create the clients of all endpoints, see Dial().
+// Only the HTTP clients of the endpoints are used, so request editors must be added with WithFailoverClientOptions().
+func WithEndpointDialOptions(opts ...DialOption) FailoverOption {
+	return func(c *failoverConfig) {
+		c.dialOpts = append(c.dialOpts, opts...)
+	}
+}
+
+// WithFailoverClientOptions adds options for the FailoverClient, for example WithNetworkGuard().
+// Request editors of these options are applied once per request, before it is routed.
+func WithFailoverClientOptions(opts ...ClientOption) FailoverOption {
+	return func(c *failoverConfig) {
+		c.clientOpts = append(c.clientOpts, opts...)
+	}
+}
+
+// WithPrimary selects the primary endpoint by its target. By default, the first endpoint is the primary.
+func WithPrimary(target string) FailoverOption {
+	return func(c *failoverConfig) {
+		c.primary = target
+	}
+}
+
+// WithHealthCheckInterval sets the maximum age of health checks, before they are repeated. The default is 10 seconds.
+func WithHealthCheckInterval(interval time.Duration) FailoverOption {
+	return func(c *failoverConfig) {
+		c.healthCheckInterval = interval
+	}
+}
+
+// WithHealthCheckTimeout limits the GetNetworkInformation request of health checks. The default is 5 seconds.
+func WithHealthCheckTimeout(timeout time.Duration) FailoverOption {
+	return func(c *failoverConfig) {
+		c.healthCheckTimeout = timeout
+	}
+}
+
+// WithCircuitBreaker configures the circuit breakers of the endpoints: after failureThreshold consecutive failures,
+// an endpoint receives no requests for the openTimeout. The defaults are 3 failures and 30 seconds.
+func WithCircuitBreaker(failureThreshold int, openTimeout time.Duration) FailoverOption {
+	return func(c *failoverConfig) {
+		c.failureThreshold = failureThreshold
+		c.openTimeout = openTimeout
+	}
+}
+
+// WithOnRoute sets a callback, which is called with the routing decision of every request before it is sent.
+// The callback is called synchronously and must not block.
+func WithOnRoute(onRoute func(RouteDecision)) FailoverOption {
+	return func(c *failoverConfig) {
+		c.onRoute = onRoute
+	}
+}
+
+// failoverServer is the server URL of the FailoverClient. It is replaced by the server URL of the selected endpoint.
+const failoverServer = "http://failover.invalid/"
+
+// FailoverClient sends requests to one of several cardano-wallet servers, which manage the same wallets.
+// Read-only operations (see IsReadOnlyOperation) go to the in-sync endpoint with the highest node tip, and are retried
+// on other endpoints if the request fails. Mutating operations only go to the primary endpoint and are never retried,
+// a failed primary must be replaced explicitly with SetPrimary().
+//
+// The health of the endpoints is checked with GetNetworkInformation, when the last check is older than the health check
+// interval. Each endpoint has a circuit breaker, which stops sending requests to it after repeated failures
+// (transport errors and 5xx responses).
+type FailoverClient struct {
+	*ClientWithResponses
+
+	config    failoverConfig
+	endpoints []*failoverEndpoint
+
+	lock      sync.Mutex
+	primary   int
+	lastCheck time.Time
+	checkLock sync.Mutex // Serializes health checks
+}
+
+type failoverEndpoint struct {
+	target string
+	client *Client
+
+	// The fields below are protected by the lock of the FailoverClient
+	status              EndpointStatus
+	consecutiveFailures int
+	openUntil           time.Time
+	trialRunning        bool
+}
+
+// NewFailoverClient returns a client for the given endpoints, see Dial() for the supported targets.
+// No connection is made before the first request.
+func NewFailoverClient(targets []string, opts ...FailoverOption) (*FailoverClient, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("At least one endpoint is required")
+	}
+	f := &FailoverClient{
+		config: failoverConfig{
+			healthCheckInterval: 10 * time.Second,
+			healthCheckTimeout:  5 * time.Second,
+			failureThreshold:    3,
+			openTimeout:         30 * time.Second,
+		},
+	}
+	for _, opt := range opts {
+		opt(&f.config)
+	}
+	if f.config.failureThreshold < 1 {
+		return nil, fmt.Errorf("Invalid circuit breaker failure threshold %v", f.config.failureThreshold)
+	}
+
+	f.primary = -1
+	for i, target := range targets {
+		for _, existing := range f.endpoints {
+			if existing.target == target {
+				return nil, fmt.Errorf("Duplicate endpoint %v", target)
+			}
+		}
+		dialOpts := append(append([]DialOption{}, f.config.dialOpts...), WithoutProbe())
+		client, err := DialClient(context.Background(), target, dialOpts...)
+		if err != nil {
+			return nil, err
+		}
+		f.endpoints = append(f.endpoints, &failoverEndpoint{
+			target: target,
+			client: client,
+			status: EndpointStatus{Target: target, Breaker: BreakerClosed},
+		})
+		if target == f.config.primary || (f.config.primary == "" && i == 0) {
+			f.primary = i
+		}
+	}
+	if f.primary < 0 {
+		return nil, fmt.Errorf("Primary endpoint %v is not one of the endpoints %v", f.config.primary, targets)
+	}
+
+	clientOpts := append([]ClientOption{WithHTTPClient(&failoverDoer{f})}, f.config.clientOpts...)
+	client, err := NewClientWithResponses(failoverServer, clientOpts...)
+	if err != nil {
+		return nil, err
+	}
+	f.ClientWithResponses = client
+	return f, nil
+}
+
+// Primary returns the target of the primary endpoint.
+func (f *FailoverClient) Primary() string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.endpoints[f.primary].target
+}
+
+// SetPrimary makes the endpoint with the given target the primary, which receives all mutating operations.
+func (f *FailoverClient) SetPrimary(target string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for i, endpoint := range f.endpoints {
+		if endpoint.target == target {
+			f.primary = i
+			return nil
+		}
+	}
+	return fmt.Errorf("Unknown endpoint %v", target)
+}
+
+// Endpoints returns the last known state of all endpoints, in the order given to NewFailoverClient().
+func (f *FailoverClient) Endpoints() []EndpointStatus {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	result := make([]EndpointStatus, len(f.endpoints))
+	now := time.Now()
+	for i, endpoint := range f.endpoints {
+		result[i] = endpoint.status
+		result[i].Primary = i == f.primary
+		result[i].Breaker = endpoint.breakerState(now)
+	}
+	return result
+}
+
+// CheckHealth checks all endpoints concurrently with GetNetworkInformation, and returns their new state.
+// A successful check closes the circuit breaker of an endpoint, a failed check counts as failure.
+func (f *FailoverClient) CheckHealth(ctx context.Context) []EndpointStatus {
+	f.checkLock.Lock()
+	defer f.checkLock.Unlock()
+	f.checkAll(ctx)
+	return f.Endpoints()
+}
+
+// refreshHealth checks the endpoints, if the last check is older than the health check interval.
+func (f *FailoverClient) refreshHealth(ctx context.Context) {
+	f.checkLock.Lock()
+	defer f.checkLock.Unlock()
+	f.lock.Lock()
+	stale := time.Since(f.lastCheck) >= f.config.healthCheckInterval
+	f.lock.Unlock()
+	if stale {
+		f.checkAll(ctx)
+	}
+}
+
+// checkAll checks all endpoints. Must be called with the checkLock.
+func (f *FailoverClient) checkAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, endpoint := range f.endpoints {
+		wg.Add(1)
+		go func(endpoint *failoverEndpoint) {
+			defer wg.Done()
+			f.checkEndpoint(ctx, endpoint)
+		}(endpoint)
+	}
+	wg.Wait()
+	if ctx.Err() == nil {
+		f.lock.Lock()
+		f.lastCheck = time.Now()
+		f.lock.Unlock()
+	}
+}
+
+func (f *FailoverClient) checkEndpoint(parent context.Context, endpoint *failoverEndpoint) {
+	ctx, cancel := context.WithTimeout(parent, f.config.healthCheckTimeout)
+	defer cancel()
+	status := EndpointStatus{Target: endpoint.target, LastCheck: time.Now()}
+	resp, err := (&ClientWithResponses{ClientInterface: endpoint.client}).GetNetworkInformationWithResponse(ctx)
+	if err == nil && resp.JSON200 == nil {
+		err = unexpectedResponse("GetNetworkInformation", resp.HTTPResponse, resp.Body)
+	}
+	if err == nil {
+		info := resp.JSON200
+		status.SyncStatus = info.SyncProgress.Status
+		status.TipSlot = info.NodeTip.AbsoluteSlotNumber
+		if info.SyncProgress.Progress != nil {
+			status.SyncProgress = float64(info.SyncProgress.Progress.Quantity)
+		} else if status.InSync() {
+			status.SyncProgress = 100
+		}
+	}
+
+	if parent.Err() != nil {
+		return // Cancelled by the caller, the result says nothing about the endpoint
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err != nil {
+		status.LastFailure = err
+	} else {
+		status.LastFailure = endpoint.status.LastFailure
+	}
+	endpoint.status = status
+	endpoint.recordResult(err == nil, f.config, time.Now())
+}
+
+// breakerState returns the state of the circuit breaker. Must be called with the lock of the FailoverClient.
+func (e *failoverEndpoint) breakerState(now time.Time) string {
+	switch {
+	case e.openUntil.IsZero():
+		return BreakerClosed
+	case now.Before(e.openUntil):
+		return BreakerOpen
+	default:
+		return BreakerHalfOpen
+	}
+}
+
+// available returns true, if the endpoint may receive a request. For half-open breakers, only one trial request
+// is allowed at a time. Must be called with the lock of the FailoverClient.
+func (e *failoverEndpoint) available(now time.Time) bool {
+	switch e.breakerState(now) {
+	case BreakerClosed:
+		return true
+	case BreakerHalfOpen:
+		return !e.trialRunning
+	}
+	return false
+}
+
+// recordResult updates the circuit breaker. Must be called with the lock of the FailoverClient.
+func (e *failoverEndpoint) recordResult(success bool, config failoverConfig, now time.Time) {
+	e.trialRunning = false
+	if success {
+		e.consecutiveFailures = 0
+		e.openUntil = time.Time{}
+		return
+	}
+	e.consecutiveFailures++
+	if e.consecutiveFailures >= config.failureThreshold || !e.openUntil.IsZero() {
+		// A failed trial request of a half-open breaker opens it again
+		e.openUntil = now.Add(config.openTimeout)
+	}
+}
+
+// route selects the endpoint for a request, which was not tried before, and returns the reason of the decision.
+// A nil endpoint means that no endpoint is available.
+func (f *FailoverClient) route(readOnly bool, exclude map[*failoverEndpoint]bool) (*failoverEndpoint, string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	now := time.Now()
+	if !readOnly {
+		primary := f.endpoints[f.primary]
+		if exclude[primary] || !primary.available(now) {
+			return nil, RouteRejected
+		}
+		primary.trialRunning = primary.breakerState(now) == BreakerHalfOpen
+		return primary, RoutePrimary
+	}
+
+	var candidates []*failoverEndpoint
+	for _, endpoint := range f.endpoints {
+		if !exclude[endpoint] && endpoint.available(now) {
+			candidates = append(candidates, endpoint)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, RouteRejected
+	}
+	// Prefer in-sync endpoints, then the highest node tip, then closed breakers, then the primary
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.status.InSync() != b.status.InSync() {
+			return a.status.InSync()
+		}
+		if a.status.TipSlot != b.status.TipSlot {
+			return a.status.TipSlot > b.status.TipSlot
+		}
+		if a.openUntil.IsZero() != b.openUntil.IsZero() {
+			return a.openUntil.IsZero()
+		}
+		return a == f.endpoints[f.primary]
+	})
+	selected := candidates[0]
+	selected.trialRunning = selected.breakerState(now) == BreakerHalfOpen
+	if len(exclude) > 0 {
+		return selected, RouteRetry
+	}
+	if !selected.status.InSync() {
+		return selected, RouteUnsynced
+	}
+	return selected, RouteHealthy
+}
+
+// failoverDoer is the HttpRequestDoer of the FailoverClient.
+type failoverDoer struct {
+	f *FailoverClient
+}
+
+func (d *failoverDoer) Do(req *http.Request) (*http.Response, error) {
+	f := d.f
+	opId := "request"
+	readOnly := req.Method == http.MethodGet
+	if op := OperationForRequest(req); op != nil {
+		opId = op.Id
+		readOnly = IsReadOnlyOperation(op.Id)
+	}
+	// Requests can only be retried, if the body can be sent again
+	retryable := readOnly && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+	f.refreshHealth(req.Context())
+
+	tried := make(map[*failoverEndpoint]bool)
+	var resp *http.Response
+	var err error
+	for attempt := 1; ; attempt++ {
+		endpoint, reason := f.route(readOnly, tried)
+		decision := RouteDecision{Operation: opId, ReadOnly: readOnly, Reason: reason, Attempt: attempt}
+		if endpoint != nil {
+			decision.Endpoint = endpoint.target
+		}
+		if f.config.onRoute != nil {
+			f.config.onRoute(decision)
+		}
+		if endpoint == nil {
+			if attempt > 1 {
+				return resp, err // The result of the last endpoint
+			}
+			if readOnly {
+				return nil, &NoEndpointError{Operation: opId}
+			}
+			return nil, &NoEndpointError{Operation: opId, Primary: f.Primary()}
+		}
+		tried[endpoint] = true
+
+		if resp != nil {
+			resp.Body.Close() // The failed response of the previous endpoint is replaced
+		}
+		resp, err = d.send(req, endpoint, attempt)
+		// Cancellations by the caller do not count as failures of the endpoint
+		callerErr := err != nil && req.Context().Err() != nil
+		success := err == nil && resp.StatusCode < http.StatusInternalServerError
+		f.lock.Lock()
+		if callerErr {
+			endpoint.trialRunning = false
+		} else {
+			endpoint.recordResult(success, f.config, time.Now())
+			if err != nil {
+				endpoint.status.LastFailure = err
+			} else if !success {
+				endpoint.status.LastFailure = fmt.Errorf("%v: response status %v", opId, resp.Status)
+			}
+		}
+		f.lock.Unlock()
+
+		if success || callerErr || !retryable || len(tried) == len(f.endpoints) {
+			return resp, err
+		}
+	}
+}
+
+// send sends the request to the given endpoint.
+func (d *failoverDoer) send(req *http.Request, endpoint *failoverEndpoint, attempt int) (*http.Response, error) {
+	target := req.Clone(withAttempt(req.Context(), attempt))
+	if attempt > 1 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		target.Body = body
+	}
+	server, err := endpointURL(endpoint.client.Server, req)
+	if err != nil {
+		return nil, err
+	}
+	target.URL = server
+	target.Host = server.Host
+	return endpoint.client.Client.Do(target)
+}
+
+// endpointURL replaces the failoverServer prefix of the request URL with the server URL of an endpoint.
+func endpointURL(server string, req *http.Request) (*url.URL, error) {
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+	result, err := serverURL.Parse("." + req.URL.EscapedPath())
+	if err != nil {
+		return nil, err
+	}
+	result.RawQuery = req.URL.RawQuery
+	return result, nil
+}
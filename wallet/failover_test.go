@@ -0,0 +1,242 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeReplica struct {
+	server *httptest.Server
+
+	lock     sync.Mutex
+	status   string
+	tip      int
+	failing  bool
+	requests []string
+}
+
+func newFakeReplica(status string, tip int) *fakeReplica {
+	r := &fakeReplica{status: status, tip: tip}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if r.failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"code": "not_responding"}`))
+			return
+		}
+		if req.URL.Path == "/v2/network/information" {
+			fmt.Fprintf(w, `{"sync_progress": {"status": "%v"}, "node_tip": {"absolute_slot_number": %v}}`, r.status, r.tip)
+			return
+		}
+		r.requests = append(r.requests, req.Method+" "+req.URL.RequestURI())
+		w.Write([]byte("[]"))
+	}))
+	return r
+}
+
+func (r *fakeReplica) setStatus(status string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.status = status
+}
+
+func (r *fakeReplica) setFailing(failing bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.failing = failing
+}
+
+func (r *fakeReplica) takeRequests() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	result := r.requests
+	r.requests = nil
+	return result
+}
+
+type FailoverTestSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	primary, replica *fakeReplica
+	decisions        []RouteDecision
+	ctx              context.Context
+}
+
+func TestFailover(t *testing.T) {
+	testSuite := new(FailoverTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *FailoverTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+	s.ctx = context.Background()
+}
+
+func (s *FailoverTestSuite) SetupTest() {
+	s.primary = newFakeReplica(WalletStatusReady, 100)
+	s.replica = newFakeReplica(WalletStatusReady, 200)
+	s.decisions = nil
+}
+
+func (s *FailoverTestSuite) TearDownTest() {
+	s.primary.server.Close()
+	s.replica.server.Close()
+}
+
+func (s *FailoverTestSuite) newClient(opts ...FailoverOption) *FailoverClient {
+	opts = append([]FailoverOption{WithOnRoute(func(decision RouteDecision) {
+		s.decisions = append(s.decisions, decision)
+	})}, opts...)
+	client, err := NewFailoverClient([]string{s.primary.server.URL + "/v2", s.replica.server.URL + "/v2"}, opts...)
+	s.NoError(err)
+	return client
+}
+
+func (s *FailoverTestSuite) listWallets(client *FailoverClient) {
+	resp, err := client.ListWallets(s.ctx)
+	s.NoError(err)
+	resp.Body.Close()
+	s.Equal(http.StatusOK, resp.StatusCode)
+}
+
+func (s *FailoverTestSuite) deleteWallet(client *FailoverClient) error {
+	resp, err := client.DeleteWallet(s.ctx, "abc")
+	if err == nil {
+		resp.Body.Close()
+	}
+	return err
+}
+
+func (s *FailoverTestSuite) TestRouting() {
+	client := s.newClient()
+	s.Equal(s.primary.server.URL+"/v2", client.Primary())
+
+	// Reads go to the replica with the highest tip, mutations to the primary
+	s.listWallets(client)
+	s.NoError(s.deleteWallet(client))
+	s.Equal([]string{"GET /v2/wallets"}, s.replica.takeRequests())
+	s.Equal([]string{"DELETE /v2/wallets/abc"}, s.primary.takeRequests())
+	s.Equal([]RouteDecision{
+		{Operation: "ListWallets", ReadOnly: true, Endpoint: s.replica.server.URL + "/v2", Reason: RouteHealthy, Attempt: 1},
+		{Operation: "DeleteWallet", ReadOnly: false, Endpoint: s.primary.server.URL + "/v2", Reason: RoutePrimary, Attempt: 1},
+	}, s.decisions)
+
+	// Endpoints that are not in sync are avoided, independent of their tip
+	s.replica.setStatus(WalletStatusSyncing)
+	status := client.CheckHealth(s.ctx)
+	s.True(status[0].InSync())
+	s.True(status[0].Primary)
+	s.False(status[1].InSync())
+	s.Equal(200, status[1].TipSlot)
+	s.listWallets(client)
+	s.Equal([]string{"GET /v2/wallets"}, s.primary.takeRequests())
+
+	// Query parameters are kept
+	order := "ascending"
+	resp, err := client.ListTransactions(s.ctx, "abc", &ListTransactionsParams{Order: &order})
+	s.NoError(err)
+	resp.Body.Close()
+	s.Equal([]string{"GET /v2/wallets/abc/transactions?order=ascending"}, s.primary.takeRequests())
+}
+
+func (s *FailoverTestSuite) TestReadRetry() {
+	client := s.newClient(WithHealthCheckInterval(time.Hour))
+	client.CheckHealth(s.ctx)
+	s.replica.setFailing(true)
+
+	s.listWallets(client)
+	s.Equal([]string{"GET /v2/wallets"}, s.primary.takeRequests())
+	s.Len(s.decisions, 2)
+	s.Equal(RouteRetry, s.decisions[1].Reason)
+	s.Equal(2, s.decisions[1].Attempt)
+	s.Error(client.Endpoints()[1].LastFailure)
+
+	// If all endpoints fail, the last response is returned
+	s.primary.setFailing(true)
+	resp, err := client.ListWallets(s.ctx)
+	s.NoError(err)
+	resp.Body.Close()
+	s.Equal(http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func (s *FailoverTestSuite) TestPrimaryFailover() {
+	client := s.newClient(WithHealthCheckInterval(time.Hour), WithCircuitBreaker(2, time.Hour))
+	client.CheckHealth(s.ctx)
+	s.primary.server.Close()
+
+	// Mutations are not retried on other endpoints
+	for i := 0; i < 2; i++ {
+		s.Error(s.deleteWallet(client))
+	}
+	s.Equal(BreakerOpen, client.Endpoints()[0].Breaker)
+	err := s.deleteWallet(client)
+	s.IsType(new(NoEndpointError), err)
+	s.Equal(&NoEndpointError{Operation: "DeleteWallet", Primary: s.primary.server.URL + "/v2"}, err)
+	s.Equal(RouteRejected, s.decisions[len(s.decisions)-1].Reason)
+	s.Empty(s.replica.takeRequests())
+
+	s.NoError(client.SetPrimary(s.replica.server.URL + "/v2"))
+	s.NoError(s.deleteWallet(client))
+	s.Equal([]string{"DELETE /v2/wallets/abc"}, s.replica.takeRequests())
+	s.Error(client.SetPrimary("http://unknown/v2"))
+}
+
+func (s *FailoverTestSuite) TestCircuitBreaker() {
+	client := s.newClient(WithHealthCheckInterval(time.Hour), WithCircuitBreaker(1, 50*time.Millisecond))
+	client.CheckHealth(s.ctx)
+	s.primary.setFailing(true)
+	s.NoError(s.deleteWallet(client)) // The 503 response is returned, but opens the breaker
+	s.Equal(BreakerOpen, client.Endpoints()[0].Breaker)
+	s.IsType(new(NoEndpointError), s.deleteWallet(client))
+
+	// After the open timeout, a failed trial request opens the breaker again, a successful one closes it
+	time.Sleep(60 * time.Millisecond)
+	s.Equal(BreakerHalfOpen, client.Endpoints()[0].Breaker)
+	s.NoError(s.deleteWallet(client))
+	s.Equal(BreakerOpen, client.Endpoints()[0].Breaker)
+	time.Sleep(60 * time.Millisecond)
+	s.primary.setFailing(false)
+	s.NoError(s.deleteWallet(client))
+	s.Equal(BreakerClosed, client.Endpoints()[0].Breaker)
+
+	// Successful health checks close the breaker
+	s.primary.setFailing(true)
+	s.NoError(s.deleteWallet(client))
+	s.Equal(BreakerOpen, client.Endpoints()[0].Breaker)
+	s.primary.setFailing(false)
+	s.Equal(BreakerClosed, client.CheckHealth(s.ctx)[0].Breaker)
+}
+
+func (s *FailoverTestSuite) TestReadOnlyOperations() {
+	s.True(IsReadOnlyOperation("ListWallets"))
+	s.True(IsReadOnlyOperation("PostTransactionFee"))
+	s.False(IsReadOnlyOperation("PostTransaction"))
+	s.False(IsReadOnlyOperation("DeleteWallet"))
+	s.False(IsReadOnlyOperation("Unknown"))
+}
+
+func (s *FailoverTestSuite) TestInvalidConfig() {
+	_, err := NewFailoverClient(nil)
+	s.Error(err)
+	_, err = NewFailoverClient([]string{"http://a/v2", "http://a/v2"})
+	s.Error(err)
+	_, err = NewFailoverClient([]string{"http://a/v2"}, WithPrimary("http://b/v2"))
+	s.Error(err)
+	_, err = NewFailoverClient([]string{"ftp://a/v2"})
+	s.Error(err)
+	client, err := NewFailoverClient([]string{"http://a/v2", "unix:///run/wallet.sock"}, WithPrimary("unix:///run/wallet.sock"))
+	s.NoError(err)
+	s.True(strings.HasPrefix(client.Primary(), "unix://"))
+}
@@ -0,0 +1,70 @@
+package wallet
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// CIP20Label is the top-level metadata label for transaction messages, as defined by CIP-20.
+	CIP20Label = 674
+
+	cip20MessageKey = "msg"
+)
+
+// CIP20Metadata encodes the given text as transaction message under label 674, see https://cips.cardano.org/cips/cip20/.
+// The text is split into lines, and lines longer than MetadataStringMaxLength bytes are split further,
+// without splitting multi-byte UTF-8 characters.
+func CIP20Metadata(text string) (Metadata, error) {
+	if text == "" {
+		return nil, fmt.Errorf("Cannot encode empty transaction message")
+	}
+	var msg []interface{}
+	for _, line := range strings.Split(text, "\n") {
+		for _, chunk := range SplitMetadataString(line) {
+			msg = append(msg, chunk)
+		}
+	}
+	return EncodeMetadata(map[uint]interface{}{
+		CIP20Label: map[interface{}]interface{}{
+			cip20MessageKey: msg,
+		},
+	})
+}
+
+// ParseCIP20 extracts the message strings stored under label 674 from the given metadata.
+// If the metadata does not contain a CIP-20 message, ParseCIP20 returns nil without error.
+func ParseCIP20(meta *Metadata) ([]string, error) {
+	if meta == nil {
+		return nil, nil
+	}
+	rawVal, ok := (*meta)[CIP20Label]
+	if !ok {
+		return nil, nil
+	}
+	parsed, err := parseMetaValue(fmt.Sprint(CIP20Label), rawVal)
+	if err != nil {
+		return nil, err
+	}
+	parsedMap, ok := parsed.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Expected map under label %v, but got: %s", CIP20Label, str(parsed))
+	}
+	rawMsg, ok := parsedMap[cip20MessageKey]
+	if !ok {
+		return nil, fmt.Errorf("Missing key '%v' under label %v", cip20MessageKey, CIP20Label)
+	}
+	msgList, ok := rawMsg.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Expected list of strings for '%v', but got: %s", cip20MessageKey, str(rawMsg))
+	}
+	result := make([]string, len(msgList))
+	for i, rawLine := range msgList {
+		line, ok := rawLine.(string)
+		if !ok {
+			return nil, fmt.Errorf("%v/%v: expected string, but got: %s", cip20MessageKey, i, str(rawLine))
+		}
+		result[i] = line
+	}
+	return result, nil
+}
@@ -0,0 +1,54 @@
+package wallet
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type CIP20TestSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestCIP20(t *testing.T) {
+	testSuite := new(CIP20TestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *CIP20TestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+}
+
+func (s *CIP20TestSuite) TestRoundTrip() {
+	longLine := strings.Repeat("ü", 40) // 80 bytes
+	meta, err := CIP20Metadata("invoice-42\n" + longLine)
+	s.NoError(err)
+
+	marshalled, err := json.Marshal(meta)
+	s.NoError(err)
+	var received Metadata
+	s.NoError(json.Unmarshal(marshalled, &received))
+
+	msg, err := ParseCIP20(&received)
+	s.NoError(err)
+	s.Equal([]string{"invoice-42", strings.Repeat("ü", 32), strings.Repeat("ü", 8)}, msg)
+}
+
+func (s *CIP20TestSuite) TestEmptyMessage() {
+	_, err := CIP20Metadata("")
+	s.Error(err)
+}
+
+func (s *CIP20TestSuite) TestParseInvalid() {
+	meta := Metadata{CIP20Label: map[string]interface{}{MetadataTypeString: "not a map"}}
+	_, err := ParseCIP20(&meta)
+	s.Error(err)
+
+	msg, err := ParseCIP20(&Metadata{})
+	s.NoError(err)
+	s.Nil(msg)
+}
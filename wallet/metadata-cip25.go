@@ -0,0 +1,348 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	// CIP25Label is the top-level metadata label for NFT metadata, as defined by CIP-25.
+	CIP25Label = 721
+
+	// CIP25Version1 encodes policy IDs as hex strings and asset names as UTF-8 strings.
+	CIP25Version1 = 1
+	// CIP25Version2 encodes policy IDs and asset names as raw bytestrings.
+	CIP25Version2 = 2
+
+	cip25VersionKey     = "version"
+	cip25NameKey        = "name"
+	cip25ImageKey       = "image"
+	cip25MediaTypeKey   = "mediaType"
+	cip25DescriptionKey = "description"
+	cip25FilesKey       = "files"
+	cip25SrcKey         = "src"
+)
+
+// CIP25 represents the NFT metadata stored under label 721, see https://cips.cardano.org/cips/cip25/.
+// Assets are grouped by policy ID (hex-encoded) and asset name. Asset names are stored as raw bytes
+// in a Go string, which is the UTF-8 name for most assets.
+type CIP25 struct {
+	Version  int
+	Policies map[string]map[string]*CIP25Asset
+}
+
+// CIP25Asset contains the metadata of a single NFT.
+// Long values for Image and Description are split into arrays of strings when encoding, and joined when parsing.
+type CIP25Asset struct {
+	Name        string
+	Image       string
+	MediaType   string
+	Description string
+	Files       []CIP25File
+
+	// Extra contains additional properties. The values must be encodable by EncodeMetadata().
+	// String values longer than MetadataStringMaxLength are split automatically. When parsing,
+	// these split values are returned as lists of strings, since they cannot be distinguished from regular lists.
+	Extra map[string]interface{}
+}
+
+// CIP25File references an additional file of an NFT.
+type CIP25File struct {
+	Name      string
+	MediaType string
+	Src       string
+	Extra     map[string]interface{}
+}
+
+// NewCIP25 returns an empty CIP25 object with the given version (CIP25Version1 or CIP25Version2).
+func NewCIP25(version int) *CIP25 {
+	return &CIP25{
+		Version:  version,
+		Policies: make(map[string]map[string]*CIP25Asset),
+	}
+}
+
+// AddAsset adds the given asset under the hex-encoded policy ID and asset name.
+func (c *CIP25) AddAsset(policyId string, assetName string, asset *CIP25Asset) {
+	if c.Policies == nil {
+		c.Policies = make(map[string]map[string]*CIP25Asset)
+	}
+	assets, ok := c.Policies[policyId]
+	if !ok {
+		assets = make(map[string]*CIP25Asset)
+		c.Policies[policyId] = assets
+	}
+	assets[assetName] = asset
+}
+
+// Metadata encodes the CIP-25 data as transaction metadata under label 721.
+func (c *CIP25) Metadata() (Metadata, error) {
+	if c.Version != CIP25Version1 && c.Version != CIP25Version2 {
+		return nil, fmt.Errorf("Unsupported CIP-25 version: %v", c.Version)
+	}
+	policies := make(map[interface{}]interface{}, len(c.Policies)+1)
+	for policyId, assets := range c.Policies {
+		policyKey, err := c.encodeKey(policyId, true)
+		if err != nil {
+			return nil, err
+		}
+		encodedAssets := make(map[interface{}]interface{}, len(assets))
+		for assetName, asset := range assets {
+			assetKey, err := c.encodeKey(assetName, false)
+			if err != nil {
+				return nil, err
+			}
+			encodedAsset, err := asset.encode()
+			if err != nil {
+				return nil, fmt.Errorf("Asset %v of policy %v: %v", assetName, policyId, err)
+			}
+			encodedAssets[assetKey] = encodedAsset
+		}
+		policies[policyKey] = encodedAssets
+	}
+	if c.Version == CIP25Version2 {
+		policies[cip25VersionKey] = CIP25Version2
+	} else {
+		policies[cip25VersionKey] = "1.0"
+	}
+	return EncodeMetadata(map[uint]interface{}{CIP25Label: policies})
+}
+
+func (c *CIP25) encodeKey(key string, isPolicy bool) (interface{}, error) {
+	switch {
+	case isPolicy && c.Version == CIP25Version2:
+		policyBytes, err := hex.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decode policy ID %v: %v", key, err)
+		}
+		return BytesKey(policyBytes), nil
+	case c.Version == CIP25Version2:
+		return BytesKey(key), nil
+	case len(key) > MetadataStringMaxLength:
+		return nil, fmt.Errorf("Key is longer than %v bytes: %v", MetadataStringMaxLength, key)
+	default:
+		return key, nil
+	}
+}
+
+func (a *CIP25Asset) encode() (map[interface{}]interface{}, error) {
+	result := make(map[interface{}]interface{}, len(a.Extra)+5)
+	if err := encodeCIP25Extra(result, a.Extra); err != nil {
+		return nil, err
+	}
+	if len(a.Name) > MetadataStringMaxLength {
+		return nil, fmt.Errorf("Name is longer than %v bytes", MetadataStringMaxLength)
+	}
+	result[cip25NameKey] = a.Name
+	result[cip25ImageKey] = splitCIP25String(a.Image)
+	if a.MediaType != "" {
+		result[cip25MediaTypeKey] = a.MediaType
+	}
+	if a.Description != "" {
+		result[cip25DescriptionKey] = splitCIP25String(a.Description)
+	}
+	if len(a.Files) > 0 {
+		files := make([]interface{}, len(a.Files))
+		for i, file := range a.Files {
+			encodedFile := make(map[interface{}]interface{}, len(file.Extra)+3)
+			if err := encodeCIP25Extra(encodedFile, file.Extra); err != nil {
+				return nil, err
+			}
+			encodedFile[cip25NameKey] = file.Name
+			encodedFile[cip25MediaTypeKey] = file.MediaType
+			encodedFile[cip25SrcKey] = splitCIP25String(file.Src)
+			files[i] = encodedFile
+		}
+		result[cip25FilesKey] = files
+	}
+	return result, nil
+}
+
+func encodeCIP25Extra(target map[interface{}]interface{}, extra map[string]interface{}) error {
+	for key, val := range extra {
+		if len(key) > MetadataStringMaxLength {
+			return fmt.Errorf("Property name is longer than %v bytes: %v", MetadataStringMaxLength, key)
+		}
+		if strVal, ok := val.(string); ok {
+			val = splitCIP25String(strVal)
+		}
+		target[key] = val
+	}
+	return nil
+}
+
+// splitCIP25String returns short strings unmodified, and splits longer strings into a list of strings.
+func splitCIP25String(s string) interface{} {
+	chunks := SplitMetadataString(s)
+	if len(chunks) == 1 {
+		return chunks[0]
+	}
+	result := make([]interface{}, len(chunks))
+	for i, chunk := range chunks {
+		result[i] = chunk
+	}
+	return result
+}
+
+// ParseCIP25 extracts the CIP-25 data from the given metadata.
+// If the metadata does not contain label 721, ParseCIP25 returns nil without error.
+func ParseCIP25(meta *Metadata) (*CIP25, error) {
+	if meta == nil {
+		return nil, nil
+	}
+	rawVal, ok := (*meta)[CIP25Label]
+	if !ok {
+		return nil, nil
+	}
+	parsed, err := parseMetaValue(fmt.Sprint(CIP25Label), rawVal)
+	if err != nil {
+		return nil, err
+	}
+	policies, ok := parsed.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Expected map under label %v, but got: %s", CIP25Label, str(parsed))
+	}
+
+	result := NewCIP25(CIP25Version1)
+	if version, ok := policies[cip25VersionKey].(int); ok && version == CIP25Version2 {
+		result.Version = CIP25Version2
+	}
+	for rawPolicy, rawAssets := range policies {
+		if rawPolicy == cip25VersionKey {
+			continue
+		}
+		var policyId string
+		switch key := rawPolicy.(type) {
+		case string:
+			policyId = key
+		case BytesKey:
+			policyId = hex.EncodeToString([]byte(key))
+		default:
+			return nil, fmt.Errorf("Unexpected policy ID: %s", str(rawPolicy))
+		}
+		assets, ok := rawAssets.(map[interface{}]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Policy %v: expected map of assets, but got: %s", policyId, str(rawAssets))
+		}
+		for rawAssetName, rawAsset := range assets {
+			var assetName string
+			switch key := rawAssetName.(type) {
+			case string:
+				assetName = key
+			case BytesKey:
+				assetName = string(key)
+			default:
+				return nil, fmt.Errorf("Policy %v: unexpected asset name: %s", policyId, str(rawAssetName))
+			}
+			asset, err := parseCIP25Asset(rawAsset)
+			if err != nil {
+				return nil, fmt.Errorf("Asset %v of policy %v: %v", assetName, policyId, err)
+			}
+			result.AddAsset(policyId, assetName, asset)
+		}
+	}
+	return result, nil
+}
+
+func parseCIP25Asset(rawAsset interface{}) (*CIP25Asset, error) {
+	props, err := parseCIP25Properties(rawAsset)
+	if err != nil {
+		return nil, err
+	}
+	asset := &CIP25Asset{
+		Name:        joinCIP25String(props[cip25NameKey]),
+		Image:       joinCIP25String(props[cip25ImageKey]),
+		MediaType:   joinCIP25String(props[cip25MediaTypeKey]),
+		Description: joinCIP25String(props[cip25DescriptionKey]),
+	}
+	if rawFiles, ok := props[cip25FilesKey].([]interface{}); ok {
+		for i, rawFile := range rawFiles {
+			fileProps, err := parseCIP25Properties(rawFile)
+			if err != nil {
+				return nil, fmt.Errorf("File %v: %v", i, err)
+			}
+			file := CIP25File{
+				Name:      joinCIP25String(fileProps[cip25NameKey]),
+				MediaType: joinCIP25String(fileProps[cip25MediaTypeKey]),
+				Src:       joinCIP25String(fileProps[cip25SrcKey]),
+			}
+			file.Extra = cip25Extra(fileProps, cip25NameKey, cip25MediaTypeKey, cip25SrcKey)
+			asset.Files = append(asset.Files, file)
+		}
+	}
+	asset.Extra = cip25Extra(props, cip25NameKey, cip25ImageKey, cip25MediaTypeKey, cip25DescriptionKey, cip25FilesKey)
+	return asset, nil
+}
+
+func parseCIP25Properties(raw interface{}) (map[string]interface{}, error) {
+	rawMap, ok := raw.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Expected map of properties, but got: %s", str(raw))
+	}
+	props := make(map[string]interface{}, len(rawMap))
+	for key, val := range rawMap {
+		strKey, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("Expected string property name, but got: %s", str(key))
+		}
+		props[strKey] = val
+	}
+	return props, nil
+}
+
+// cip25Extra returns all properties except the given known keys.
+func cip25Extra(props map[string]interface{}, knownKeys ...string) map[string]interface{} {
+	known := make(map[string]bool, len(knownKeys))
+	for _, key := range knownKeys {
+		known[key] = true
+	}
+	var extra map[string]interface{}
+	for key, val := range props {
+		if known[key] {
+			continue
+		}
+		if extra == nil {
+			extra = make(map[string]interface{})
+		}
+		extra[key] = val
+	}
+	return extra
+}
+
+// joinCIP25String returns string values as-is, and concatenates lists of strings.
+// Other values result in an empty string.
+func joinCIP25String(val interface{}) string {
+	switch typedVal := val.(type) {
+	case string:
+		return typedVal
+	case []interface{}:
+		var builder strings.Builder
+		for _, item := range typedVal {
+			if s, ok := item.(string); ok {
+				builder.WriteString(s)
+			}
+		}
+		return builder.String()
+	}
+	return ""
+}
+
+// CIP25FromTransactions extracts CIP-25 data from all transactions returned by ListTransactions.
+// The result maps transaction IDs to the contained CIP-25 data. Transactions without label 721 are omitted.
+func CIP25FromTransactions(resp *ListTransactionsResponse) (map[string]*CIP25, error) {
+	result := make(map[string]*CIP25)
+	if resp == nil || resp.JSON200 == nil {
+		return result, nil
+	}
+	for _, tx := range *resp.JSON200 {
+		cip25, err := ParseCIP25(tx.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("Transaction %v: %v", tx.Id, err)
+		}
+		if cip25 != nil {
+			result[tx.Id] = cip25
+		}
+	}
+	return result, nil
+}
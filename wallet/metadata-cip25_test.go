@@ -0,0 +1,124 @@
+package wallet
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type CIP25TestSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestCIP25(t *testing.T) {
+	testSuite := new(CIP25TestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *CIP25TestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+}
+
+const testPolicyId = "b0d07d45fe9514f80213f4020e5a61241458be626841cde717cb38a7"
+
+func (s *CIP25TestSuite) testAsset() *CIP25Asset {
+	return &CIP25Asset{
+		Name:        "NFT #1",
+		Image:       "ipfs://" + strings.Repeat("Qm", 40),
+		MediaType:   "image/png",
+		Description: "A short description",
+		Files: []CIP25File{{
+			Name:      "hi-res",
+			MediaType: "image/png",
+			Src:       "ipfs://QmSmall",
+			Extra:     map[string]interface{}{"size": 1024},
+		}},
+		Extra: map[string]interface{}{
+			"artist": "someone",
+			"traits": []interface{}{"round", "blue"},
+		},
+	}
+}
+
+// roundTrip encodes the given data and parses it again, after passing it through JSON
+// like it would be when received from the cardano-wallet.
+func (s *CIP25TestSuite) roundTrip(cip25 *CIP25) (*CIP25, Metadata) {
+	meta, err := cip25.Metadata()
+	s.NoError(err)
+	marshalled, err := json.Marshal(meta)
+	s.NoError(err)
+	var received Metadata
+	s.NoError(json.Unmarshal(marshalled, &received))
+	parsed, err := ParseCIP25(&received)
+	s.NoError(err)
+	s.NotNil(parsed)
+	return parsed, received
+}
+
+func (s *CIP25TestSuite) TestRoundTripVersion1() {
+	cip25 := NewCIP25(CIP25Version1)
+	cip25.AddAsset(testPolicyId, "NFT1", s.testAsset())
+
+	parsed, _ := s.roundTrip(cip25)
+	s.Equal(CIP25Version1, parsed.Version)
+	s.Equal(cip25.Policies, parsed.Policies)
+}
+
+func (s *CIP25TestSuite) TestRoundTripVersion2() {
+	cip25 := NewCIP25(CIP25Version2)
+	cip25.AddAsset(testPolicyId, "\x00\x01binary", s.testAsset())
+
+	parsed, received := s.roundTrip(cip25)
+	s.Equal(CIP25Version2, parsed.Version)
+	s.Equal(cip25.Policies, parsed.Policies)
+
+	// Policy IDs must be encoded as bytestrings in version 2
+	marshalled, err := json.Marshal(received)
+	s.NoError(err)
+	s.Contains(string(marshalled), `{"bytes":"`+testPolicyId+`"}`)
+}
+
+func (s *CIP25TestSuite) TestLongStringsAreSplit() {
+	cip25 := NewCIP25(CIP25Version1)
+	cip25.AddAsset(testPolicyId, "NFT1", s.testAsset())
+	meta, err := cip25.Metadata()
+	s.NoError(err)
+
+	parsed, err := meta.Parse()
+	s.NoError(err)
+	asset := parsed[CIP25Label].(map[interface{}]interface{})[testPolicyId].(map[interface{}]interface{})["NFT1"]
+	image := asset.(map[interface{}]interface{})[cip25ImageKey]
+	s.Equal([]interface{}{"ipfs://" + strings.Repeat("Qm", 28) + "Q", "m" + strings.Repeat("Qm", 11)}, image)
+}
+
+func (s *CIP25TestSuite) TestNameTooLong() {
+	cip25 := NewCIP25(CIP25Version1)
+	cip25.AddAsset(testPolicyId, "NFT1", &CIP25Asset{Name: strings.Repeat("x", 65)})
+	_, err := cip25.Metadata()
+	s.Error(err)
+}
+
+func (s *CIP25TestSuite) TestParseWithoutLabel() {
+	meta := Metadata{674: map[string]interface{}{MetadataTypeString: "hello"}}
+	parsed, err := ParseCIP25(&meta)
+	s.NoError(err)
+	s.Nil(parsed)
+}
+
+func (s *CIP25TestSuite) TestSplitMetadataString() {
+	s.Equal([]string{""}, SplitMetadataString(""))
+	s.Equal([]string{"short"}, SplitMetadataString("short"))
+
+	// 'ä' is encoded as 2 bytes and must not be split at byte 64
+	input := strings.Repeat("a", 63) + "ä" + "b"
+	chunks := SplitMetadataString(input)
+	s.Equal([]string{strings.Repeat("a", 63), "äb"}, chunks)
+	for _, chunk := range SplitMetadataString(strings.Repeat("€", 100)) {
+		s.True(len(chunk) <= MetadataStringMaxLength)
+		s.Equal(0, len(chunk)%len("€"))
+	}
+}
@@ -0,0 +1,176 @@
+package wallet
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/godano/cardano-wallet-client/bech32"
+	"github.com/godano/cardano-wallet-client/internal/cbor"
+	"golang.org/x/crypto/blake2b"
+)
+
+// ErrInvalidSignature is returned by VerifyMetadataSignature, if the signature does not match.
+var ErrInvalidSignature = errors.New("Invalid metadata signature")
+
+// MarshalCBOR encodes the metadata in the binary format that is stored on the ledger.
+// Top-level labels are sorted in ascending order. Nested maps keep the order of their key-value pairs,
+// since the ledger stores them as ordered lists of pairs. Their keys are deliberately not sorted canonically:
+// cardano-wallet hashes the pairs in the given order, which is reproduced here.
+//
+// To encode integers beyond 2^53 exactly, the metadata must be decoded from JSON with json.Decoder.UseNumber().
+func (meta Metadata) MarshalCBOR() ([]byte, error) {
+	labels := make([]uint, 0, len(meta))
+	for label := range meta {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i] < labels[j] })
+
+	e := new(cbor.Encoder)
+	e.WriteMapHeader(len(labels))
+	for _, label := range labels {
+		e.WriteUint(uint64(label))
+		if err := encodeMetaCBOR(strconv.Itoa(int(label)), e, meta[label]); err != nil {
+			return nil, err
+		}
+	}
+	return e.Bytes(), nil
+}
+
+func encodeMetaCBOR(path string, e *cbor.Encoder, rawVal interface{}) error {
+	val, ok := rawVal.(map[string]interface{})
+	if !ok || len(val) != 1 {
+		return fmt.Errorf("%v: unexpected metadata object: %s", path, str(rawVal))
+	}
+	for valType, actualVal := range val {
+		// This loop will be entered only once
+		switch valType {
+		case MetadataTypeInt:
+			intVal, err := parseMetaInt(path, actualVal)
+			if err != nil {
+				return err
+			}
+			switch typedVal := intVal.(type) {
+			case int:
+				e.WriteInt(int64(typedVal))
+			case uint64:
+				e.WriteUint(typedVal)
+			}
+		case MetadataTypeString:
+			strVal, ok := actualVal.(string)
+			if !ok {
+				return fmt.Errorf("%v: expected type string, but got: %s", path, str(actualVal))
+			}
+			e.WriteText(strVal)
+		case MetadataTypeBytes:
+			strVal, ok := actualVal.(string)
+			if !ok {
+				return fmt.Errorf("%v: expected type string, but got: %s", path, str(actualVal))
+			}
+			bytesVal, err := parseByteString(path, strVal)
+			if err != nil {
+				return err
+			}
+			e.WriteBytes(bytesVal)
+		case MetadataTypeList:
+			listVal, ok := actualVal.([]interface{})
+			if !ok {
+				return fmt.Errorf("%v: expected type []interface{} for list, but got: %s", path, str(actualVal))
+			}
+			e.WriteArrayHeader(len(listVal))
+			for i, item := range listVal {
+				if err := encodeMetaCBOR(path+"/"+strconv.Itoa(i), e, item); err != nil {
+					return err
+				}
+			}
+		case MetadataTypeMap:
+			listVal, ok := actualVal.([]interface{})
+			if !ok {
+				return fmt.Errorf("%v: expected type []interface{} for map, but got: %s", path, str(actualVal))
+			}
+			e.WriteMapHeader(len(listVal))
+			for i, rawPair := range listVal {
+				itemPath := path + "/" + strconv.Itoa(i)
+				pair, ok := rawPair.(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("%v: expected map[string]interface{}, but got %s", itemPath, str(rawPair))
+				}
+				if err := encodeMetaCBOR(itemPath+"["+MetadataMapKey+"]", e, pair[MetadataMapKey]); err != nil {
+					return err
+				}
+				if err := encodeMetaCBOR(itemPath+"["+MetadataMapVal+"]", e, pair[MetadataMapVal]); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("%v: unknown metadata type '%v' for %s", path, valType, str(actualVal))
+		}
+	}
+	return nil
+}
+
+// MetadataHash returns the blake2b-256 hash of the CBOR-encoded metadata. This is the message
+// that is signed by the SignMetadata operation.
+func MetadataHash(meta Metadata) ([]byte, error) {
+	encoded, err := meta.MarshalCBOR()
+	if err != nil {
+		return nil, err
+	}
+	hash := blake2b.Sum256(encoded)
+	return hash[:], nil
+}
+
+// VerifyMetadataSignature checks a signature returned by SignMetadata, without contacting the server.
+// The nested maps of the metadata must list their pairs in the order, in which they were given to SignMetadata.
+// The public key can be given in Bech32 (e.g. stake_vk or acct_xvk, as returned by GetWalletKey or PostAccountKey)
+// or as hex. For extended keys, the chain code is ignored.
+// VerifyMetadataSignature returns ErrInvalidSignature if the signature does not match.
+func VerifyMetadataSignature(meta Metadata, signature []byte, publicKey string) error {
+	key, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	if len(signature) != ed25519.SignatureSize {
+		return fmt.Errorf("Unexpected signature length %v, expected %v bytes", len(signature), ed25519.SignatureSize)
+	}
+	hash, err := MetadataHash(meta)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, hash, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// ParsePublicKey decodes an Ed25519 public key, given in Bech32 or hex, either plain (32 bytes)
+// or extended with a chain code (64 bytes). The returned key does not include the chain code.
+func ParsePublicKey(key string) (ed25519.PublicKey, error) {
+	var keyBytes []byte
+	var err error
+	if strings.ContainsRune(key, '1') && !isHex(key) {
+		var hrp string
+		hrp, keyBytes, err = bech32.Decode(key)
+		if err == nil && strings.HasSuffix(hrp, "sk") {
+			err = fmt.Errorf("Expected a public key, but got a secret key (Bech32 prefix %v)", hrp)
+		}
+	} else {
+		keyBytes, err = hex.DecodeString(key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode public key: %v", err)
+	}
+	if len(keyBytes) != ed25519.PublicKeySize && len(keyBytes) != 2*ed25519.PublicKeySize {
+		return nil, fmt.Errorf("Unexpected public key length %v, expected 32 or 64 bytes", len(keyBytes))
+	}
+	return ed25519.PublicKey(keyBytes[:ed25519.PublicKeySize]), nil
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
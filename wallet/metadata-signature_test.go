@@ -0,0 +1,86 @@
+package wallet
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/godano/cardano-wallet-client/bech32"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/blake2b"
+)
+
+type MetadataSignatureTestSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestMetadataSignature(t *testing.T) {
+	testSuite := new(MetadataSignatureTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *MetadataSignatureTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+}
+
+func (s *MetadataSignatureTestSuite) parse(metaJSON string) Metadata {
+	decoder := json.NewDecoder(strings.NewReader(metaJSON))
+	decoder.UseNumber()
+	var meta Metadata
+	s.NoError(decoder.Decode(&meta))
+	return meta
+}
+
+func (s *MetadataSignatureTestSuite) TestMarshalCBOR() {
+	meta := s.parse(`{
+		"674": {"map": [{"k": {"string": "msg"}, "v": {"list": [{"string": "hi"}]}}]},
+		"1": {"list": [{"int": -1}, {"int": 18446744073709551615}, {"bytes": "cafe"}]}
+	}`)
+	encoded, err := meta.MarshalCBOR()
+	s.NoError(err)
+	s.Equal("a2"+
+		"01"+"83"+"20"+"1bffffffffffffffff"+"42cafe"+
+		"1902a2"+"a1"+"636d7367"+"81"+"626869",
+		hex.EncodeToString(encoded))
+}
+
+func (s *MetadataSignatureTestSuite) TestMapOrderIsPreserved() {
+	meta := s.parse(`{"1": {"map": [
+		{"k": {"int": 2}, "v": {"int": 0}},
+		{"k": {"int": 1}, "v": {"int": 0}}
+	]}}`)
+	encoded, err := meta.MarshalCBOR()
+	s.NoError(err)
+	s.Equal("a101a202000100", hex.EncodeToString(encoded))
+}
+
+func (s *MetadataSignatureTestSuite) TestVerify() {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	s.NoError(err)
+	meta := s.parse(`{"61284": {"map": [{"k": {"int": 4}, "v": {"int": 1234}}]}}`)
+	encoded, err := meta.MarshalCBOR()
+	s.NoError(err)
+	hash := blake2b.Sum256(encoded)
+	signature := ed25519.Sign(priv, hash[:])
+
+	stakeVk, err := bech32.Encode("stake_vk", pub)
+	s.NoError(err)
+	s.NoError(VerifyMetadataSignature(meta, signature, stakeVk))
+
+	// Extended keys contain a chain code after the public key
+	acctXvk, err := bech32.Encode("acct_xvk", append(append([]byte{}, pub...), make([]byte, 32)...))
+	s.NoError(err)
+	s.NoError(VerifyMetadataSignature(meta, signature, acctXvk))
+	s.NoError(VerifyMetadataSignature(meta, signature, hex.EncodeToString(pub)))
+
+	tampered := s.parse(`{"61284": {"map": [{"k": {"int": 4}, "v": {"int": 1235}}]}}`)
+	s.Equal(ErrInvalidSignature, VerifyMetadataSignature(tampered, signature, stakeVk))
+
+	secretKey, err := bech32.Encode("stake_sk", priv.Seed())
+	s.NoError(err)
+	s.Error(VerifyMetadataSignature(meta, signature, secretKey))
+}
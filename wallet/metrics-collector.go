@@ -0,0 +1,186 @@
+package wallet
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/godano/cardano-wallet-client/internal/prometheus"
+)
+
+// MetricsPrefix is the prefix of all metrics of a MetricsCollector.
+const MetricsPrefix = "cardano_wallet_client_"
+
+// DefaultDurationBuckets are the upper bounds in seconds of the request duration histogram of a MetricsCollector.
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300, 1800}
+
+// unknownOperation is the operation label of requests that do not match any operation.
+const unknownOperation = "unknown"
+
+// MetricsCollector is an Observer, which aggregates the requests of one or more clients in memory.
+// All metrics are labelled by operation Id, not by URL, so that wallet ids do not increase the number of time series.
+// The metrics are rendered in the Prometheus text exposition format by WritePrometheus() or ServeHTTP().
+// Retries of a FailoverClient are only counted, when the collector observes the endpoint clients, see WithObserver().
+type MetricsCollector struct {
+	buckets []float64
+
+	lock       sync.Mutex
+	operations map[string]*operationMetrics
+}
+
+type operationMetrics struct {
+	inFlight      int64
+	requests      map[string]uint64 // By status code, or "error" if no response was received
+	errors        map[string]uint64 // By ErrorKind()
+	retries       uint64
+	requestBytes  uint64
+	responseBytes uint64
+
+	durationBuckets []uint64 // Not cumulative, the last entry counts durations above all buckets
+	durationSum     float64
+	durationCount   uint64
+}
+
+// NewMetricsCollector returns an empty MetricsCollector. If no buckets are given, DefaultDurationBuckets are used.
+func NewMetricsCollector(buckets ...float64) *MetricsCollector {
+	if len(buckets) == 0 {
+		buckets = DefaultDurationBuckets
+	}
+	sorted := append([]float64{}, buckets...)
+	sort.Float64s(sorted)
+	return &MetricsCollector{buckets: sorted, operations: make(map[string]*operationMetrics)}
+}
+
+func (m *MetricsCollector) metrics(info *RequestInfo) *operationMetrics {
+	operation := info.Operation
+	if operation == "" {
+		operation = unknownOperation
+	}
+	result := m.operations[operation]
+	if result == nil {
+		result = &operationMetrics{
+			requests:        make(map[string]uint64),
+			errors:          make(map[string]uint64),
+			durationBuckets: make([]uint64, len(m.buckets)+1),
+		}
+		m.operations[operation] = result
+	}
+	return result
+}
+
+// BeforeRequest implements Observer.
+func (m *MetricsCollector) BeforeRequest(_ context.Context, info *RequestInfo) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	metrics := m.metrics(info)
+	metrics.inFlight++
+	if info.Attempt > 1 {
+		metrics.retries++
+	}
+	if info.RequestBytes > 0 {
+		metrics.requestBytes += uint64(info.RequestBytes)
+	}
+}
+
+// AfterRequest implements Observer.
+func (m *MetricsCollector) AfterRequest(_ context.Context, info *RequestInfo, result *RequestResult) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	metrics := m.metrics(info)
+	metrics.inFlight--
+	code := "error"
+	if result.StatusCode != 0 {
+		code = strconv.Itoa(result.StatusCode)
+	}
+	metrics.requests[code]++
+	if result.Err != nil {
+		metrics.errors[ErrorKind(result.Err)]++
+	}
+	metrics.responseBytes += uint64(result.ResponseBytes)
+
+	seconds := result.Duration.Seconds()
+	bucket := sort.SearchFloat64s(m.buckets, seconds) // The first bucket with an upper bound >= seconds
+	metrics.durationBuckets[bucket]++
+	metrics.durationSum += seconds
+	metrics.durationCount++
+}
+
+// WritePrometheus writes all metrics in the Prometheus text exposition format.
+func (m *MetricsCollector) WritePrometheus(w io.Writer) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	operations := make([]string, 0, len(m.operations))
+	for operation := range m.operations {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+
+	out := prometheus.NewWriter(w, MetricsPrefix)
+	family := func(name, metricType, help string, write func(operation string, metrics *operationMetrics)) {
+		out.Family(name, metricType, help)
+		for _, operation := range operations {
+			write(operation, m.operations[operation])
+		}
+	}
+	sample := out.Sample
+
+	family("requests_total", "counter", "Completed requests by operation and HTTP status code, code=\"error\" if no response was received.",
+		func(operation string, metrics *operationMetrics) {
+			for _, code := range sortedKeys(metrics.requests) {
+				sample("requests_total", metrics.requests[code], "operation", operation, "code", code)
+			}
+		})
+	family("errors_total", "counter", "Failed requests by operation and kind of error.",
+		func(operation string, metrics *operationMetrics) {
+			for _, kind := range sortedKeys(metrics.errors) {
+				sample("errors_total", metrics.errors[kind], "operation", operation, "kind", kind)
+			}
+		})
+	family("retries_total", "counter", "Requests retried on another endpoint by operation.",
+		func(operation string, metrics *operationMetrics) {
+			sample("retries_total", metrics.retries, "operation", operation)
+		})
+	family("requests_in_flight", "gauge", "Requests in flight by operation.",
+		func(operation string, metrics *operationMetrics) {
+			sample("requests_in_flight", metrics.inFlight, "operation", operation)
+		})
+	family("request_bytes_total", "counter", "Bytes of request bodies by operation.",
+		func(operation string, metrics *operationMetrics) {
+			sample("request_bytes_total", metrics.requestBytes, "operation", operation)
+		})
+	family("response_bytes_total", "counter", "Bytes of response bodies read by operation.",
+		func(operation string, metrics *operationMetrics) {
+			sample("response_bytes_total", metrics.responseBytes, "operation", operation)
+		})
+	family("request_duration_seconds", "histogram", "Duration of requests by operation, including reading the response body.",
+		func(operation string, metrics *operationMetrics) {
+			var cumulative uint64
+			for i, bound := range m.buckets {
+				cumulative += metrics.durationBuckets[i]
+				sample("request_duration_seconds_bucket", cumulative,
+					"operation", operation, "le", strconv.FormatFloat(bound, 'g', -1, 64))
+			}
+			sample("request_duration_seconds_bucket", metrics.durationCount, "operation", operation, "le", "+Inf")
+			sample("request_duration_seconds_sum", metrics.durationSum, "operation", operation)
+			sample("request_duration_seconds_count", metrics.durationCount, "operation", operation)
+		})
+	return out.Flush()
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format.
+func (m *MetricsCollector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", prometheus.ContentType)
+	m.WritePrometheus(w)
+}
+
+func sortedKeys(m map[string]uint64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,94 @@
+package wallet
+
+import (
+	"net/http"
+)
+
+// Middleware wraps the requests of a Client, see WithMiddleware(). Do is called for each request, with the
+// HttpRequestDoer that sends the request onwards: the next middleware, or the HTTP client of the Client.
+type Middleware interface {
+	Do(req *http.Request, next HttpRequestDoer) (*http.Response, error)
+}
+
+// MiddlewareFunc implements Middleware with a function.
+type MiddlewareFunc func(req *http.Request, next HttpRequestDoer) (*http.Response, error)
+
+func (f MiddlewareFunc) Do(req *http.Request, next HttpRequestDoer) (*http.Response, error) {
+	return f(req, next)
+}
+
+// WithMiddleware returns a ClientOption that passes all requests of the client through the given middleware.
+// Middlewares of later options wrap the middlewares of earlier options, so they see the requests first.
+// The options of this package that wrap requests, like WithOperationTimeouts(), WithRateLimit(), WithObserver()
+// or WithAuditLog(), are middlewares as well.
+//
+// The middlewares are kept, when the HTTP client is replaced by WithHTTPClient() or WithHTTPSClient(), so these
+// options can be given in any order.
+func WithMiddleware(middleware Middleware) ClientOption {
+	return func(c *Client) error {
+		middlewaresOf(c).add(middleware)
+		return nil
+	}
+}
+
+// middlewareDoer is the HttpRequestDoer of a Client with middlewares.
+type middlewareDoer struct {
+	client      *Client
+	httpClient  HttpRequestDoer // nil for http.DefaultClient, which NewClient() only sets after applying the options
+	middlewares []Middleware
+}
+
+// middlewaresOf returns the middlewareDoer of the client, after installing it if necessary.
+// A middlewareDoer of another client, given through WithHTTPClient(), is used as HTTP client, so it is not modified.
+func middlewaresOf(c *Client) *middlewareDoer {
+	if doer, ok := c.Client.(*middlewareDoer); ok && doer.client == c {
+		return doer
+	}
+	doer := &middlewareDoer{client: c, httpClient: c.Client}
+	c.Client = doer
+	return doer
+}
+
+// setHTTPClient replaces the HTTP client of the Client, but keeps its middlewares. It is called by WithHTTPClient().
+func setHTTPClient(c *Client, httpClient HttpRequestDoer) {
+	if doer, ok := c.Client.(*middlewareDoer); ok && doer.client == c {
+		doer.httpClient = httpClient
+		return
+	}
+	c.Client = httpClient
+}
+
+func (d *middlewareDoer) add(middleware Middleware) {
+	d.middlewares = append(d.middlewares, middleware)
+}
+
+// last returns the last middleware, for which the predicate is true, or nil.
+func (d *middlewareDoer) last(predicate func(Middleware) bool) Middleware {
+	for i := len(d.middlewares) - 1; i >= 0; i-- {
+		if predicate(d.middlewares[i]) {
+			return d.middlewares[i]
+		}
+	}
+	return nil
+}
+
+func (d *middlewareDoer) Do(req *http.Request) (*http.Response, error) {
+	return middlewareChain{doer: d, index: len(d.middlewares)}.Do(req)
+}
+
+// middlewareChain sends requests through the first index middlewares, and then through the HTTP client.
+type middlewareChain struct {
+	doer  *middlewareDoer
+	index int
+}
+
+func (c middlewareChain) Do(req *http.Request) (*http.Response, error) {
+	if c.index == 0 {
+		if c.doer.httpClient == nil {
+			return http.DefaultClient.Do(req)
+		}
+		return c.doer.httpClient.Do(req)
+	}
+	next := middlewareChain{doer: c.doer, index: c.index - 1}
+	return c.doer.middlewares[c.index-1].Do(req, next)
+}
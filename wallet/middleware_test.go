@@ -0,0 +1,88 @@
+package wallet
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	server *httptest.Server
+}
+
+func TestMiddleware(t *testing.T) {
+	testSuite := new(MiddlewareTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *MiddlewareTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}))
+}
+
+func (s *MiddlewareTestSuite) TearDownSuite() {
+	s.server.Close()
+}
+
+// recorder returns a middleware, which appends the given name to the calls.
+func recorder(calls *[]string, name string) ClientOption {
+	return WithMiddleware(MiddlewareFunc(func(req *http.Request, next HttpRequestDoer) (*http.Response, error) {
+		*calls = append(*calls, name)
+		return next.Do(req)
+	}))
+}
+
+// doerFunc implements HttpRequestDoer with a function.
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func (s *MiddlewareTestSuite) TestOrder() {
+	var calls []string
+	httpClient := doerFunc(func(req *http.Request) (*http.Response, error) {
+		calls = append(calls, "http")
+		return s.server.Client().Do(req)
+	})
+	// The HTTP client is replaced after the middlewares were added, but the middlewares are kept
+	client, err := NewClient(s.server.URL+"/v2", recorder(&calls, "first"), WithOperationTimeouts(nil),
+		WithHTTPClient(httpClient), recorder(&calls, "second"))
+	s.NoError(err)
+	resp, err := client.ListWallets(context.Background())
+	s.NoError(err)
+	resp.Body.Close()
+	s.Equal([]string{"second", "first", "http"}, calls)
+	s.Len(client.Client.(*middlewareDoer).middlewares, 3)
+
+	// The middlewares of a client, which is used as HTTP client of another client, are not modified
+	calls = nil
+	outer, err := NewClient(s.server.URL+"/v2", WithHTTPClient(client.Client), recorder(&calls, "outer"))
+	s.NoError(err)
+	resp, err = outer.ListWallets(context.Background())
+	s.NoError(err)
+	resp.Body.Close()
+	s.Equal([]string{"outer", "second", "first", "http"}, calls)
+	s.Len(client.Client.(*middlewareDoer).middlewares, 3)
+}
+
+func (s *MiddlewareTestSuite) TestDefaultClient() {
+	var calls []string
+	client, err := NewClient(s.server.URL+"/v2", recorder(&calls, "only"))
+	s.NoError(err)
+	s.Nil(client.Client.(*middlewareDoer).httpClient)
+	resp, err := client.ListWallets(context.Background())
+	s.NoError(err)
+	resp.Body.Close()
+	s.Equal([]string{"only"}, calls)
+}
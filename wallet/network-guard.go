@@ -0,0 +1,139 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/godano/cardano-wallet-client/address"
+)
+
+// MainnetGenesisBlockHash is the genesis block hash reported by GetNetworkParameters on the Cardano mainnet.
+// All other genesis block hashes are treated as test networks.
+const MainnetGenesisBlockHash = "5f20df933584822601f9e3f8c024eb5eb252fe8cefb24d1317dc3d432e940ebb"
+
+// networkGuardedOperations are the operations whose bodies contain destination addresses.
+var networkGuardedOperations = map[string]bool{
+	"PostTransaction":      true,
+	"PostTransactionFee":   true,
+	"SelectCoins":          true,
+	"MigrateShelleyWallet": true,
+}
+
+// NetworkMismatchError is returned by requests rejected by the network guard, see WithNetworkGuard().
+type NetworkMismatchError struct {
+	Operation     string
+	Address       string
+	ServerMainnet bool
+}
+
+func (e *NetworkMismatchError) Error() string {
+	return fmt.Sprintf("%v: address %v does not belong to the %v of the wallet server",
+		e.Operation, e.Address, networkName(e.ServerMainnet))
+}
+
+func networkName(mainnet bool) string {
+	if mainnet {
+		return "mainnet"
+	}
+	return "testnet"
+}
+
+// WithNetworkGuard returns a ClientOption that checks the destination addresses of PostTransaction, PostTransactionFee,
+// SelectCoins and MigrateShelleyWallet requests before sending them. Requests containing addresses of a different
+// network than the one of the wallet server fail with a *NetworkMismatchError.
+// The network of the server is queried through GetNetworkParameters before the first checked request, and cached afterwards.
+func WithNetworkGuard() ClientOption {
+	return func(c *Client) error {
+		guard := &networkGuard{client: c}
+		c.RequestEditors = append(c.RequestEditors, guard.checkRequest)
+		return nil
+	}
+}
+
+type networkGuard struct {
+	client *Client
+
+	lock    sync.Mutex
+	mainnet *bool
+}
+
+func (g *networkGuard) checkRequest(ctx context.Context, req *http.Request) error {
+	op := OperationForRequest(req)
+	if op == nil || !networkGuardedOperations[op.Id] || req.Body == nil {
+		return nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return fmt.Errorf("%v: failed to read request body: %v", op.Id, err)
+	}
+	req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	addresses, err := destinationAddresses(body)
+	if err != nil {
+		return fmt.Errorf("%v: %v", op.Id, err)
+	}
+	if len(addresses) == 0 {
+		return nil
+	}
+	mainnet, err := g.serverIsMainnet(ctx)
+	if err != nil {
+		return err
+	}
+	for _, addrStr := range addresses {
+		addr, err := address.Decode(addrStr)
+		if err != nil {
+			return fmt.Errorf("%v: invalid destination address %v: %v", op.Id, addrStr, err)
+		}
+		if addr.IsMainnet() != mainnet {
+			return &NetworkMismatchError{Operation: op.Id, Address: addrStr, ServerMainnet: mainnet}
+		}
+	}
+	return nil
+}
+
+// destinationAddresses returns the addresses of the "payments" (PostTransaction, PostTransactionFee, SelectCoins)
+// and "addresses" (MigrateShelleyWallet) fields of a request body.
+func destinationAddresses(body []byte) ([]string, error) {
+	var parsed struct {
+		Payments []struct {
+			Address string `json:"address"`
+		} `json:"payments"`
+		Addresses []string `json:"addresses"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("Failed to parse request body: %v", err)
+	}
+	result := parsed.Addresses
+	for _, payment := range parsed.Payments {
+		result = append(result, payment.Address)
+	}
+	return result, nil
+}
+
+func (g *networkGuard) serverIsMainnet(ctx context.Context) (bool, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.mainnet != nil {
+		return *g.mainnet, nil
+	}
+	resp, err := g.client.GetNetworkParameters(ctx)
+	if err != nil {
+		return false, fmt.Errorf("Network guard failed to query network parameters: %v", err)
+	}
+	parsed, err := ParseGetNetworkParametersResponse(resp)
+	if err != nil {
+		return false, fmt.Errorf("Network guard failed to parse network parameters: %v", err)
+	}
+	if parsed.JSON200 == nil {
+		return false, unexpectedResponse("GetNetworkParameters", parsed.HTTPResponse, parsed.Body)
+	}
+	mainnet := parsed.JSON200.GenesisBlockHash == MainnetGenesisBlockHash
+	g.mainnet = &mainnet
+	return mainnet, nil
+}
@@ -0,0 +1,135 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/godano/cardano-wallet-client/address"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type NetworkGuardTestSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	server           *httptest.Server
+	parameterQueries int
+	submitted        int
+
+	mainnetAddress string
+	testnetAddress string
+}
+
+func TestNetworkGuard(t *testing.T) {
+	testSuite := new(NetworkGuardTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *NetworkGuardTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+
+	payment := &address.Credential{Kind: address.KeyHash, Hash: make([]byte, address.HashSize)}
+	mainnet, err := address.NewEnterpriseAddress(address.NetworkMainnet, payment)
+	s.NoError(err)
+	testnet, err := address.NewEnterpriseAddress(address.NetworkTestnet, payment)
+	s.NoError(err)
+	s.mainnetAddress, s.testnetAddress = mainnet.String(), testnet.String()
+}
+
+func (s *NetworkGuardTestSuite) SetupTest() {
+	s.parameterQueries, s.submitted = 0, 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/network/parameters", func(w http.ResponseWriter, r *http.Request) {
+		s.parameterQueries++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"genesis_block_hash": MainnetGenesisBlockHash})
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		s.submitted++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	})
+	s.server = httptest.NewServer(mux)
+}
+
+func (s *NetworkGuardTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *NetworkGuardTestSuite) client() *Client {
+	client, err := NewClient(s.server.URL+"/v2", WithNetworkGuard())
+	s.NoError(err)
+	return client
+}
+
+func (s *NetworkGuardTestSuite) paymentBody(addr string) string {
+	return fmt.Sprintf(`{"passphrase": "x", "payments": [{"address": %q, "amount": {"quantity": 1, "unit": "lovelace"}}]}`, addr)
+}
+
+func (s *NetworkGuardTestSuite) TestMatchingNetwork() {
+	client := s.client()
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		resp, err := client.PostTransactionWithBody(ctx, "w1", "application/json", strings.NewReader(s.paymentBody(s.mainnetAddress)))
+		s.NoError(err)
+		resp.Body.Close()
+	}
+	s.Equal(2, s.submitted)
+	s.Equal(1, s.parameterQueries, "network must be cached")
+}
+
+func (s *NetworkGuardTestSuite) TestMismatchIsRejected() {
+	client := s.client()
+	ctx := context.Background()
+
+	_, err := client.SelectCoinsWithBody(ctx, "w1", "application/json", strings.NewReader(s.paymentBody(s.testnetAddress)))
+	s.IsType(&NetworkMismatchError{}, err)
+	s.Equal("SelectCoins", err.(*NetworkMismatchError).Operation)
+	s.Equal(s.testnetAddress, err.(*NetworkMismatchError).Address)
+
+	body := fmt.Sprintf(`{"passphrase": "x", "addresses": [%q, %q]}`, s.mainnetAddress, s.testnetAddress)
+	_, err = client.MigrateShelleyWalletWithBody(ctx, "w1", "application/json", strings.NewReader(body))
+	s.IsType(&NetworkMismatchError{}, err)
+
+	_, err = client.PostTransactionFeeWithBody(ctx, "w1", "application/json", strings.NewReader(s.paymentBody("not-an-address")))
+	s.Error(err)
+
+	s.Equal(0, s.submitted)
+}
+
+func (s *NetworkGuardTestSuite) TestUnguardedOperations() {
+	resp, err := s.client().GetWallet(context.Background(), "w1")
+	s.NoError(err)
+	resp.Body.Close()
+	s.Equal(1, s.submitted)
+	s.Equal(0, s.parameterQueries)
+}
+
+// The result of the offline inspection must stay assignable to the result of the InspectAddress operation
+var _ = func(resp *InspectAddressResponse, inspection *address.Inspection) {
+	*resp.JSON200 = *inspection
+}
+
+func (s *NetworkGuardTestSuite) TestOperationForRequest() {
+	req, err := NewPostTransactionRequestWithBody("https://localhost:8090/v2/", "w1", "application/json", nil)
+	s.NoError(err)
+	op := OperationForRequest(req)
+	s.NotNil(op)
+	s.Equal("PostTransaction", op.Id)
+	s.Equal(map[string]string{"walletId": "w1"}, op.PathParameters(req.URL.Path))
+
+	req, err = NewGetByronWalletRequest("https://localhost:8090/v2/", "w1")
+	s.NoError(err)
+	s.Equal("GetByronWallet", OperationForRequest(req).Id)
+
+	req, err = http.NewRequest(http.MethodGet, "https://localhost:8090/v2/unknown", nil)
+	s.NoError(err)
+	s.Nil(OperationForRequest(req))
+	s.NotNil(OperationById("GetNetworkParameters"))
+}
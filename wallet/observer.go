@@ -0,0 +1,182 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RequestInfo describes a request passed to an Observer.
+type RequestInfo struct {
+	// Operation is the operation Id (see Operations()), or empty if the request does not match any operation
+	Operation string
+	Method    string
+	// Endpoint is the host of the request URL
+	Endpoint string
+	// Attempt is 1, unless the request is retried on another endpoint by a FailoverClient. It is only counted by
+	// observers of the endpoint clients, see WithObserver().
+	Attempt int
+	// RequestBytes is the size of the request body, or -1 if unknown
+	RequestBytes int64
+	// TraceParent is the traceparent header of the request, see PropagateTraceContext()
+	TraceParent string
+	Start       time.Time
+}
+
+// RequestResult describes the outcome of a request passed to an Observer.
+type RequestResult struct {
+	// StatusCode is 0, if no response was received
+	StatusCode int
+	// ResponseBytes is the number of bytes read from the response body
+	ResponseBytes int64
+	// Duration is the time from sending the request until the response body is closed or read completely
+	Duration time.Duration
+	// Err is the error of the request or of reading the response body
+	Err error
+}
+
+// Observer is notified about all requests of a client, see WithObserver().
+type Observer interface {
+	// BeforeRequest is called before the request is sent.
+	BeforeRequest(ctx context.Context, info *RequestInfo)
+
+	// AfterRequest is called after the request failed, or after the response body was read completely or closed.
+	// If the response body is never closed, AfterRequest might not be called.
+	AfterRequest(ctx context.Context, info *RequestInfo, result *RequestResult)
+}
+
+// WithObserver returns a ClientOption that notifies the given observer about all requests of the client.
+// The observer is a Middleware (see WithMiddleware()). When it is given after WithRateLimit() or WithConcurrencyLimit(),
+// the observed durations include the time spent waiting for the limits.
+// The observers are called synchronously and must not block.
+//
+// Observers of a FailoverClient see each request once, with Attempt 1, when they are given by
+// WithFailoverClientOptions(). To observe every attempt on the endpoints, give them to the endpoint clients
+// with WithEndpointDialOptions(WithClientOptions(WithObserver(observer))).
+func WithObserver(observer Observer) ClientOption {
+	return WithMiddleware(&observerMiddleware{observer: observer})
+}
+
+type attemptKey struct{}
+
+// withAttempt stores the number of the attempt of a request in the context, see RequestInfo.Attempt.
+func withAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+func attemptFromContext(ctx context.Context) int {
+	if attempt, ok := ctx.Value(attemptKey{}).(int); ok {
+		return attempt
+	}
+	return 1
+}
+
+type observerMiddleware struct {
+	observer Observer
+}
+
+func (m *observerMiddleware) Do(req *http.Request, next HttpRequestDoer) (*http.Response, error) {
+	ctx := req.Context()
+	info := &RequestInfo{
+		Method:       req.Method,
+		Endpoint:     req.URL.Host,
+		Attempt:      attemptFromContext(ctx),
+		RequestBytes: req.ContentLength,
+		TraceParent:  req.Header.Get(TraceParentHeader),
+		Start:        time.Now(),
+	}
+	if req.Body == nil || req.Body == http.NoBody {
+		info.RequestBytes = 0
+	} else if info.RequestBytes == 0 {
+		info.RequestBytes = -1
+	}
+	if op := OperationForRequest(req); op != nil {
+		info.Operation = op.Id
+	}
+	m.observer.BeforeRequest(ctx, info)
+
+	resp, err := next.Do(req)
+	if err != nil {
+		m.observer.AfterRequest(ctx, info, &RequestResult{Duration: time.Since(info.Start), Err: err})
+		return nil, err
+	}
+	resp.Body = &observedBody{
+		ReadCloser: resp.Body,
+		finish: func(bytes int64, err error) {
+			m.observer.AfterRequest(ctx, info, &RequestResult{
+				StatusCode:    resp.StatusCode,
+				ResponseBytes: bytes,
+				Duration:      time.Since(info.Start),
+				Err:           err,
+			})
+		},
+	}
+	return resp, nil
+}
+
+// observedBody counts the bytes of a response body, and calls finish once at the end of the body, at the first
+// read error, or when the body is closed.
+type observedBody struct {
+	io.ReadCloser
+	finish func(bytes int64, err error)
+
+	bytes int64
+	once  sync.Once
+}
+
+func (b *observedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += int64(n)
+	if err == io.EOF {
+		b.done(nil)
+	} else if err != nil {
+		b.done(err)
+	}
+	return n, err
+}
+
+func (b *observedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.done(nil)
+	return err
+}
+
+func (b *observedBody) done(err error) {
+	b.once.Do(func() {
+		b.finish(b.bytes, err)
+	})
+}
+
+// Kinds of errors, see ErrorKind()
+const (
+	ErrorKindTimeout    = "timeout"
+	ErrorKindLimit      = "limit"
+	ErrorKindNoEndpoint = "no_endpoint"
+	ErrorKindCanceled   = "canceled"
+	ErrorKindTransport  = "transport"
+)
+
+// ErrorKind classifies request errors for metrics: ErrorKindTimeout, ErrorKindLimit, ErrorKindNoEndpoint,
+// ErrorKindCanceled or ErrorKindTransport.
+func ErrorKind(err error) string {
+	var timeoutErr *OperationTimeoutError
+	var limitErr *RequestLimitError
+	var noEndpointErr *NoEndpointError
+	var netErr interface{ Timeout() bool } // e.g. *url.Error for the timeout of http.Client
+	switch {
+	case errors.As(err, &timeoutErr):
+		return ErrorKindTimeout
+	case errors.As(err, &limitErr):
+		return ErrorKindLimit
+	case errors.As(err, &noEndpointErr):
+		return ErrorKindNoEndpoint
+	case errors.Is(err, context.Canceled):
+		return ErrorKindCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorKindTimeout
+	}
+	return ErrorKindTransport
+}
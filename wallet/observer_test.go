@@ -0,0 +1,163 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type recordingObserver struct {
+	before []RequestInfo
+	after  []RequestResult
+}
+
+func (o *recordingObserver) BeforeRequest(_ context.Context, info *RequestInfo) {
+	o.before = append(o.before, *info)
+}
+
+func (o *recordingObserver) AfterRequest(_ context.Context, _ *RequestInfo, result *RequestResult) {
+	o.after = append(o.after, *result)
+}
+
+type ObserverTestSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	server *httptest.Server
+	ctx    context.Context
+}
+
+func TestObserver(t *testing.T) {
+	testSuite := new(ObserverTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *ObserverTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+	s.ctx = context.Background()
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		w.Write([]byte(`[{"id": "abc"}]`))
+	}))
+}
+
+func (s *ObserverTestSuite) TearDownSuite() {
+	s.server.Close()
+}
+
+func (s *ObserverTestSuite) TestObserver() {
+	observer := new(recordingObserver)
+	client, err := NewClientWithResponses(s.server.URL+"/v2",
+		WithRequestEditorFn(PropagateTraceContext), WithObserver(observer))
+	s.NoError(err)
+
+	_, err = client.ListWalletsWithResponse(s.ctx)
+	s.NoError(err)
+	s.Len(observer.before, 1)
+	info := observer.before[0]
+	s.Equal("ListWallets", info.Operation)
+	s.Equal(http.MethodGet, info.Method)
+	s.Equal(1, info.Attempt)
+	s.Equal(int64(0), info.RequestBytes)
+	s.Equal(strings.TrimPrefix(s.server.URL, "http://"), info.Endpoint)
+	_, err = ParseTraceParent(info.TraceParent)
+	s.NoError(err)
+	s.Equal([]RequestResult{{StatusCode: 200, ResponseBytes: 15, Duration: observer.after[0].Duration}}, observer.after)
+
+	// The result is reported once, when the body is closed before it is read completely
+	resp, err := client.PostWalletWithBody(s.ctx, "application/json", bytes.NewReader([]byte(`{"name": "x"}`)))
+	s.NoError(err)
+	resp.Body.Read(make([]byte, 2))
+	resp.Body.Close()
+	resp.Body.Close()
+	s.Equal(int64(13), observer.before[1].RequestBytes)
+	s.Len(observer.after, 2)
+	s.Equal(int64(2), observer.after[1].ResponseBytes)
+
+	closed, err := NewClient("http://localhost:1/v2", WithObserver(observer))
+	s.NoError(err)
+	_, err = closed.GetSettings(s.ctx)
+	s.Error(err)
+	s.Len(observer.after, 3)
+	s.Equal(0, observer.after[2].StatusCode)
+	s.Equal(ErrorKindTransport, ErrorKind(observer.after[2].Err))
+}
+
+func (s *ObserverTestSuite) TestMetricsCollector() {
+	metrics := NewMetricsCollector(0.1, 1)
+	client, err := NewClient(s.server.URL+"/v2",
+		WithOperationTimeouts(OperationTimeouts{"GetSettings": time.Nanosecond}), WithObserver(metrics))
+	s.NoError(err)
+
+	for _, walletId := range []string{"a", "b"} {
+		resp, err := client.DeleteWallet(s.ctx, walletId)
+		s.NoError(err)
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+	_, err = client.GetSettings(s.ctx)
+	s.Error(err)
+	resp, err := client.PostWalletWithBody(s.ctx, "application/json", bytes.NewReader([]byte(`{"name": "x"}`)))
+	s.NoError(err)
+	resp.Body.Close()
+
+	out := new(bytes.Buffer)
+	s.NoError(metrics.WritePrometheus(out))
+	text := out.String()
+	for _, line := range []string{
+		"# TYPE cardano_wallet_client_requests_total counter",
+		`cardano_wallet_client_requests_total{operation="DeleteWallet",code="404"} 2`,
+		`cardano_wallet_client_requests_total{operation="GetSettings",code="error"} 1`,
+		`cardano_wallet_client_requests_total{operation="PostWallet",code="200"} 1`,
+		`cardano_wallet_client_errors_total{operation="GetSettings",kind="timeout"} 1`,
+		`cardano_wallet_client_retries_total{operation="DeleteWallet"} 0`,
+		`cardano_wallet_client_requests_in_flight{operation="DeleteWallet"} 0`,
+		`cardano_wallet_client_request_bytes_total{operation="PostWallet"} 13`,
+		`cardano_wallet_client_response_bytes_total{operation="DeleteWallet"} 30`,
+		"# TYPE cardano_wallet_client_request_duration_seconds histogram",
+		`cardano_wallet_client_request_duration_seconds_bucket{operation="DeleteWallet",le="1"} 2`,
+		`cardano_wallet_client_request_duration_seconds_bucket{operation="DeleteWallet",le="+Inf"} 2`,
+		`cardano_wallet_client_request_duration_seconds_count{operation="DeleteWallet"} 2`,
+	} {
+		s.Contains(text, line+"\n")
+	}
+	s.NotContains(text, `wallet_id`)
+	s.NotContains(text, `/wallets/a`)
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	s.Equal(text, recorder.Body.String())
+	s.Contains(recorder.Header().Get("Content-Type"), "text/plain")
+}
+
+func (s *ObserverTestSuite) TestFailoverRetries() {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	metrics := NewMetricsCollector()
+	client, err := NewFailoverClient([]string{failing.URL + "/v2", s.server.URL + "/v2"},
+		WithEndpointDialOptions(WithClientOptions(WithObserver(metrics))))
+	s.NoError(err)
+	client.CheckHealth(s.ctx)
+
+	resp, err := client.ListWallets(s.ctx)
+	s.NoError(err)
+	resp.Body.Close()
+	out := new(bytes.Buffer)
+	s.NoError(metrics.WritePrometheus(out))
+	s.Contains(out.String(), `cardano_wallet_client_retries_total{operation="ListWallets"} 1`+"\n")
+	s.Contains(out.String(), `cardano_wallet_client_requests_total{operation="ListWallets",code="503"} 1`+"\n")
+	s.Contains(out.String(), `cardano_wallet_client_requests_total{operation="GetNetworkInformation",code="503"} 1`+"\n")
+}
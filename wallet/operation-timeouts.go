@@ -0,0 +1,197 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+	"time"
+)
+
+// Timeouts used by DefaultOperationTimeouts()
+const (
+	// ShortOperationTimeout applies to GET requests, which only read the state of the server
+	ShortOperationTimeout = 30 * time.Second
+	// DefaultOperationTimeout applies to all other requests, e.g. creating wallets or transactions
+	DefaultOperationTimeout = 2 * time.Minute
+	// LongOperationTimeout applies to the long-running operations listed in longRunningOperations
+	LongOperationTimeout = 30 * time.Minute
+)
+
+// longRunningOperations take much longer than other requests, because the server has to query all stake pools,
+// or process all transactions or UTxOs of a wallet.
+var longRunningOperations = map[string]bool{
+	"ListStakePools":                true,
+	"MigrateShelleyWallet":          true,
+	"MigrateByronWallet":            true,
+	"GetShelleyWalletMigrationInfo": true,
+	"GetByronWalletMigrationInfo":   true,
+	"ListTransactions":              true,
+	"ListByronTransactions":         true,
+	"GetUTxOsStatistics":            true,
+	"GetByronUTxOsStatistics":       true,
+}
+
+// Phases of a request, see OperationTimeoutError
+const (
+	PhaseDial    = "dial"
+	PhaseTLS     = "TLS handshake"
+	PhaseHeaders = "response headers"
+	PhaseBody    = "response body"
+)
+
+var requestPhases = []string{PhaseDial, PhaseTLS, PhaseHeaders, PhaseBody}
+
+// OperationTimeoutError is returned when a request exceeds the timeout of its operation, see WithOperationTimeouts().
+// Phase is the phase of the request that was interrupted: PhaseDial, PhaseTLS, PhaseHeaders or PhaseBody.
+type OperationTimeoutError struct {
+	Operation string
+	Phase     string
+	Timeout   time.Duration
+}
+
+func (e *OperationTimeoutError) Error() string {
+	return fmt.Sprintf("%v timed out after %v while waiting for the %v", e.Operation, e.Timeout, e.Phase)
+}
+
+// Unwrap returns context.DeadlineExceeded, so that errors.Is() recognizes timeouts.
+func (e *OperationTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// OperationTimeouts maps operation Ids (the Client method names, see Operations()) to the timeout of their requests.
+// A timeout of 0 means no limit. Requests that do not match any operation are not limited.
+type OperationTimeouts map[string]time.Duration
+
+// DefaultOperationTimeouts returns a new table with the default timeouts of all operations:
+// ShortOperationTimeout for GET requests, LongOperationTimeout for long-running operations like ListStakePools
+// or MigrateShelleyWallet, and DefaultOperationTimeout for all others.
+func DefaultOperationTimeouts() OperationTimeouts {
+	result := make(OperationTimeouts)
+	ops, _ := Operations()
+	for _, op := range ops {
+		switch {
+		case longRunningOperations[op.Id]:
+			result[op.Id] = LongOperationTimeout
+		case op.Method == http.MethodGet:
+			result[op.Id] = ShortOperationTimeout
+		default:
+			result[op.Id] = DefaultOperationTimeout
+		}
+	}
+	return result
+}
+
+// Validate checks that all keys of the table are known operation Ids.
+func (t OperationTimeouts) Validate() error {
+	for id, timeout := range t {
+		if OperationById(id) == nil {
+			return fmt.Errorf("Unknown operation %v in operation timeouts", id)
+		}
+		if timeout < 0 {
+			return fmt.Errorf("Negative timeout %v for operation %v", timeout, id)
+		}
+	}
+	return nil
+}
+
+// WithOperationTimeouts returns a ClientOption that limits the duration of requests, including reading the response
+// body, by the timeout of their operation. The given timeouts override the entries of DefaultOperationTimeouts(),
+// or of a previous WithOperationTimeouts() option. Timeouts fail with an *OperationTimeoutError.
+//
+// Dial() and WithHTTPSClient() enable the default timeouts. The timeouts are a Middleware, see WithMiddleware().
+func WithOperationTimeouts(timeouts OperationTimeouts) ClientOption {
+	return func(c *Client) error {
+		if err := timeouts.Validate(); err != nil {
+			return err
+		}
+		middlewares := middlewaresOf(c)
+		previous, ok := middlewares.last(func(m Middleware) bool {
+			_, ok := m.(*timeoutMiddleware)
+			return ok
+		}).(*timeoutMiddleware)
+		if ok {
+			// The table of the previous option might be used by other clients, so it is replaced instead of modified
+			previous.timeouts = previous.timeouts.merge(timeouts)
+		} else {
+			middlewares.add(&timeoutMiddleware{timeouts: DefaultOperationTimeouts().merge(timeouts)})
+		}
+		return nil
+	}
+}
+
+// merge returns a copy of the table with the given entries added.
+func (t OperationTimeouts) merge(overrides OperationTimeouts) OperationTimeouts {
+	result := make(OperationTimeouts, len(t))
+	for id, timeout := range t {
+		result[id] = timeout
+	}
+	for id, timeout := range overrides {
+		result[id] = timeout
+	}
+	return result
+}
+
+type timeoutMiddleware struct {
+	timeouts OperationTimeouts
+}
+
+func (m *timeoutMiddleware) Do(req *http.Request, next HttpRequestDoer) (*http.Response, error) {
+	op := OperationForRequest(req)
+	if op == nil || m.timeouts[op.Id] == 0 {
+		return next.Do(req)
+	}
+	timeout := m.timeouts[op.Id]
+
+	parent := req.Context()
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	phase := new(int32) // Index in requestPhases, the trace functions are called from other goroutines
+	setPhase := func(p int) { atomic.StoreInt32(phase, int32(p)) }
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		TLSHandshakeStart: func() { setPhase(1) },
+		GotConn:           func(httptrace.GotConnInfo) { setPhase(2) },
+	})
+	timeoutErr := func(err error) error {
+		// Cancellations and deadlines of the caller's context are reported as they are
+		if ctx.Err() != context.DeadlineExceeded || parent.Err() != nil {
+			return err
+		}
+		return &OperationTimeoutError{
+			Operation: op.Id,
+			Phase:     requestPhases[atomic.LoadInt32(phase)],
+			Timeout:   timeout,
+		}
+	}
+
+	resp, err := next.Do(req.WithContext(ctx))
+	if err != nil {
+		err = timeoutErr(err)
+		cancel()
+		return nil, err
+	}
+	setPhase(3)
+	resp.Body = &timeoutBody{ReadCloser: resp.Body, cancel: cancel, timeoutErr: timeoutErr}
+	return resp, nil
+}
+
+// timeoutBody releases the timer of the request when closed, and converts read errors caused by the timeout.
+type timeoutBody struct {
+	io.ReadCloser
+	cancel     context.CancelFunc
+	timeoutErr func(error) error
+}
+
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		err = b.timeoutErr(err)
+	}
+	return n, err
+}
+
+func (b *timeoutBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
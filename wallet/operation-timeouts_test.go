@@ -0,0 +1,130 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const testTimeout = 50 * time.Millisecond
+
+type OperationTimeoutsTestSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	server *httptest.Server
+}
+
+func TestOperationTimeouts(t *testing.T) {
+	testSuite := new(OperationTimeoutsTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *OperationTimeoutsTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/settings", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(4 * testTimeout)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	})
+	mux.HandleFunc("/v2/network/information", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{"))
+		w.(http.Flusher).Flush()
+		time.Sleep(4 * testTimeout)
+		w.Write([]byte("}"))
+	})
+	s.server = httptest.NewServer(mux)
+}
+
+func (s *OperationTimeoutsTestSuite) TearDownSuite() {
+	s.server.Close()
+}
+
+func (s *OperationTimeoutsTestSuite) timeoutError(err error) *OperationTimeoutError {
+	s.Error(err)
+	var timeoutErr *OperationTimeoutError
+	s.True(errors.As(err, &timeoutErr), "Unexpected error: %v", err)
+	s.True(errors.Is(err, context.DeadlineExceeded))
+	return timeoutErr
+}
+
+func (s *OperationTimeoutsTestSuite) TestDefaults() {
+	timeouts := DefaultOperationTimeouts()
+	s.Equal(LongOperationTimeout, timeouts["ListStakePools"])
+	s.Equal(LongOperationTimeout, timeouts["MigrateShelleyWallet"])
+	s.Equal(ShortOperationTimeout, timeouts["GetSettings"])
+	s.Equal(DefaultOperationTimeout, timeouts["PostWallet"])
+	ops, err := Operations()
+	s.NoError(err)
+	s.Len(timeouts, len(ops))
+	s.NoError(timeouts.Validate())
+}
+
+func (s *OperationTimeoutsTestSuite) TestHeadersTimeout() {
+	client, err := Dial(context.Background(), s.server.URL+"/v2", WithoutProbe(),
+		WithClientOptions(WithOperationTimeouts(OperationTimeouts{"GetSettings": testTimeout})))
+	s.NoError(err)
+	_, err = client.GetSettingsWithResponse(context.Background())
+	s.Equal(&OperationTimeoutError{Operation: "GetSettings", Phase: PhaseHeaders, Timeout: testTimeout}, s.timeoutError(err))
+}
+
+func (s *OperationTimeoutsTestSuite) TestBodyTimeout() {
+	client, err := NewClientWithResponses(s.server.URL+"/v2",
+		WithOperationTimeouts(OperationTimeouts{"GetNetworkInformation": testTimeout}))
+	s.NoError(err)
+	_, err = client.GetNetworkInformationWithResponse(context.Background())
+	s.Equal(PhaseBody, s.timeoutError(err).Phase)
+}
+
+func (s *OperationTimeoutsTestSuite) TestDialTimeout() {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	client, err := NewClient(s.server.URL+"/v2", WithHTTPClient(&http.Client{Transport: transport}),
+		WithOperationTimeouts(OperationTimeouts{"ListWallets": testTimeout}))
+	s.NoError(err)
+	_, err = client.ListWallets(context.Background())
+	s.Equal(PhaseDial, s.timeoutError(err).Phase)
+}
+
+func (s *OperationTimeoutsTestSuite) TestOverrides() {
+	client, err := NewClient(s.server.URL+"/v2",
+		WithOperationTimeouts(OperationTimeouts{"GetSettings": testTimeout, "ListStakePools": time.Hour}),
+		WithOperationTimeouts(OperationTimeouts{"GetSettings": 0}))
+	s.NoError(err)
+	timeouts := client.Client.(*middlewareDoer).middlewares[0].(*timeoutMiddleware).timeouts
+	s.Equal(time.Hour, timeouts["ListStakePools"])
+	s.Equal(ShortOperationTimeout, timeouts["GetWallet"])
+
+	// A timeout of 0 disables the limit
+	resp, err := client.GetSettings(context.Background())
+	s.NoError(err)
+	resp.Body.Close()
+	s.Equal(http.StatusOK, resp.StatusCode)
+
+	_, err = NewClient(s.server.URL+"/v2", WithOperationTimeouts(OperationTimeouts{"GetSetings": time.Second}))
+	s.Error(err)
+}
+
+func (s *OperationTimeoutsTestSuite) TestCallerDeadline() {
+	client, err := NewClient(s.server.URL+"/v2", WithOperationTimeouts(nil))
+	s.NoError(err)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	_, err = client.GetSettings(ctx)
+	s.Error(err)
+	var timeoutErr *OperationTimeoutError
+	s.False(errors.As(err, &timeoutErr), "Unexpected error: %v", err)
+}
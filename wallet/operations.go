@@ -0,0 +1,137 @@
+package wallet
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Operation describes one operation of the cardano-wallet API, as defined in the Swagger specification.
+type Operation struct {
+	// Id is the name of the corresponding Client method, e.g. "PostTransaction".
+	Id string
+
+	Method string
+
+	// Path is the path template relative to the server URL, e.g. "/wallets/{walletId}/transactions".
+	Path string
+
+	// Spec is the full operation definition from GetSwagger()
+	Spec *openapi3.Operation
+
+	segments []string
+}
+
+// matches returns true, if the last segments of the given request path match the path template of the operation.
+func (op *Operation) matches(method string, pathSegments []string) bool {
+	if op.Method != method || len(pathSegments) < len(op.segments) {
+		return false
+	}
+	pathSegments = pathSegments[len(pathSegments)-len(op.segments):]
+	for i, segment := range op.segments {
+		isParam := strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+		if !isParam && segment != pathSegments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// PathParameters extracts the values of the path parameters from the given request path.
+// The result is undefined if the path does not belong to the operation.
+func (op *Operation) PathParameters(path string) map[string]string {
+	pathSegments := splitPath(path)
+	if len(pathSegments) < len(op.segments) {
+		return nil
+	}
+	pathSegments = pathSegments[len(pathSegments)-len(op.segments):]
+	result := make(map[string]string)
+	for i, segment := range op.segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			result[segment[1:len(segment)-1]] = pathSegments[i]
+		}
+	}
+	return result
+}
+
+var (
+	operationsOnce sync.Once
+	operations     []*Operation
+	operationsErr  error
+)
+
+// Operations returns all operations defined in the Swagger specification of the cardano-wallet API.
+// The specification is only loaded once, the returned objects must not be modified.
+func Operations() ([]*Operation, error) {
+	operationsOnce.Do(func() {
+		var swagger *openapi3.Swagger
+		swagger, operationsErr = GetSwagger()
+		if operationsErr != nil {
+			return
+		}
+		for path, pathItem := range swagger.Paths {
+			for method, spec := range pathItem.Operations() {
+				operations = append(operations, &Operation{
+					Id:       strings.ToUpper(spec.OperationID[:1]) + spec.OperationID[1:],
+					Method:   method,
+					Path:     path,
+					Spec:     spec,
+					segments: splitPath(path),
+				})
+			}
+		}
+		// Prefer longer path templates, and literal segments over parameters, when matching request paths
+		sort.Slice(operations, func(i, j int) bool {
+			a, b := operations[i], operations[j]
+			if len(a.segments) != len(b.segments) {
+				return len(a.segments) > len(b.segments)
+			}
+			if a.Path != b.Path {
+				return strings.Count(a.Path, "{") < strings.Count(b.Path, "{")
+			}
+			return a.Method < b.Method
+		})
+	})
+	return operations, operationsErr
+}
+
+// OperationForRequest returns the API operation of the given request, or nil if the request does not match any operation.
+// The request path is matched from the end, so that the base path of the server URL (e.g. "/v2/") does not matter.
+func OperationForRequest(req *http.Request) *Operation {
+	ops, err := Operations()
+	if err != nil {
+		return nil
+	}
+	pathSegments := splitPath(req.URL.Path)
+	for _, op := range ops {
+		if op.matches(req.Method, pathSegments) {
+			return op
+		}
+	}
+	return nil
+}
+
+// OperationById returns the operation with the given Id (the Client method name), or nil if it does not exist.
+func OperationById(id string) *Operation {
+	ops, err := Operations()
+	if err != nil {
+		return nil
+	}
+	for _, op := range ops {
+		if op.Id == id {
+			return op
+		}
+	}
+	return nil
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
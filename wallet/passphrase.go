@@ -0,0 +1,345 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// PassphraseProvider supplies wallet passphrases, so they do not have to be part of request bodies, command lines
+// or logs. The caller of Passphrase() owns the returned slice and overwrites it with zeros after use.
+type PassphraseProvider interface {
+	Passphrase(ctx context.Context) ([]byte, error)
+}
+
+// PassphraseFunc implements PassphraseProvider with a function.
+type PassphraseFunc func(ctx context.Context) ([]byte, error)
+
+func (f PassphraseFunc) Passphrase(ctx context.Context) ([]byte, error) {
+	return f(ctx)
+}
+
+// TerminalPassphrase prompts for the passphrase on the terminal connected to stdin, without echoing the input.
+// The prompt is written to stderr. It fails if stdin is not a terminal.
+func TerminalPassphrase(prompt string) PassphraseProvider {
+	return PassphraseFunc(func(ctx context.Context) ([]byte, error) {
+		fd := int(os.Stdin.Fd())
+		if !terminal.IsTerminal(fd) {
+			return nil, fmt.Errorf("Cannot prompt for the passphrase, stdin is not a terminal")
+		}
+		fmt.Fprint(os.Stderr, prompt)
+		passphrase, err := terminal.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read passphrase: %v", err)
+		}
+		return passphrase, nil
+	})
+}
+
+// CachedPassphrase is a PassphraseProvider, which reads the passphrase only once from another provider and returns
+// a copy to every caller. Close() overwrites the cached passphrase with zeros, afterwards Passphrase() fails.
+type CachedPassphrase struct {
+	provider PassphraseProvider
+
+	lock   sync.Mutex
+	cached []byte
+	read   bool
+	closed bool
+}
+
+// NewCachedPassphrase returns a CachedPassphrase for the given provider.
+func NewCachedPassphrase(provider PassphraseProvider) *CachedPassphrase {
+	return &CachedPassphrase{provider: provider}
+}
+
+func (c *CachedPassphrase) Passphrase(ctx context.Context) ([]byte, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.closed {
+		return nil, fmt.Errorf("The cached passphrase was already closed")
+	}
+	if !c.read {
+		passphrase, err := c.provider.Passphrase(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.cached, c.read = passphrase, true
+	}
+	return append([]byte(nil), c.cached...), nil
+}
+
+// Close overwrites the cached passphrase with zeros.
+func (c *CachedPassphrase) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ZeroPassphrase(c.cached)
+	c.cached, c.closed = nil, true
+	return nil
+}
+
+// FdPassphrase reads the passphrase from the given, already opened file descriptor until EOF, e.g. from a pipe
+// set up by the calling process. The file descriptor is closed afterwards, it can only be read once, so the passphrase
+// is cached until Close() is called. One trailing line break is removed.
+func FdPassphrase(fd uintptr) *CachedPassphrase {
+	return NewCachedPassphrase(PassphraseFunc(func(ctx context.Context) ([]byte, error) {
+		file := os.NewFile(fd, "fd:"+strconv.FormatUint(uint64(fd), 10))
+		if file == nil {
+			return nil, fmt.Errorf("Invalid file descriptor %v", fd)
+		}
+		defer file.Close()
+		data, err := readPassphrase(file)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read passphrase from file descriptor %v: %v", fd, err)
+		}
+		return data, nil
+	}))
+}
+
+// FilePassphrase reads the passphrase from the given file. One trailing line break is removed.
+func FilePassphrase(path string) PassphraseProvider {
+	return PassphraseFunc(func(ctx context.Context) ([]byte, error) {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read passphrase file: %v", err)
+		}
+		defer file.Close()
+		data, err := readPassphrase(file)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read passphrase file %v: %v", path, err)
+		}
+		return data, nil
+	})
+}
+
+// EnvPassphrase reads the passphrase from the given environment variable, which must be set.
+func EnvPassphrase(name string) PassphraseProvider {
+	return PassphraseFunc(func(ctx context.Context) ([]byte, error) {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fmt.Errorf("Passphrase environment variable %v is not set", name)
+		}
+		return []byte(value), nil
+	})
+}
+
+// CommandPassphrase executes the given command (without shell) and uses its standard output as passphrase,
+// e.g. for password managers like "pass show cardano/wallet". One trailing line break is removed.
+func CommandPassphrase(name string, args ...string) PassphraseProvider {
+	return PassphraseFunc(func(ctx context.Context) ([]byte, error) {
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Stdin = os.Stdin
+		cmd.Stderr = os.Stderr
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("Failed to run passphrase command %v: %v", name, err)
+		}
+		data, readErr := readPassphrase(stdout)
+		if err := cmd.Wait(); err != nil {
+			ZeroPassphrase(data)
+			return nil, fmt.Errorf("Passphrase command %v failed: %v", name, err)
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("Failed to read output of passphrase command %v: %v", name, readErr)
+		}
+		return data, nil
+	})
+}
+
+// ParsePassphraseSource returns the PassphraseProvider for a source description, as used by the --passphrase-from
+// flag of the CLI: "prompt", "fd:<number>", "file:<path>", "env:<variable>" or "cmd:<command and arguments>".
+// The prompt is only used for the "prompt" source. The provider of the "fd" source is a *CachedPassphrase,
+// which should be closed after use.
+func ParsePassphraseSource(source string, prompt string) (PassphraseProvider, error) {
+	kind, value := source, ""
+	if i := strings.Index(source, ":"); i >= 0 {
+		kind, value = source[:i], source[i+1:]
+	}
+	switch {
+	case kind == "prompt" && value == "":
+		return TerminalPassphrase(prompt), nil
+	case kind == "fd" && value != "":
+		fd, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid file descriptor in passphrase source %v", source)
+		}
+		return FdPassphrase(uintptr(fd)), nil
+	case kind == "file" && value != "":
+		return FilePassphrase(value), nil
+	case kind == "env" && value != "":
+		return EnvPassphrase(value), nil
+	case kind == "cmd" && strings.TrimSpace(value) != "":
+		fields := strings.Fields(value)
+		return CommandPassphrase(fields[0], fields[1:]...), nil
+	}
+	return nil, fmt.Errorf("Invalid passphrase source %q, expected prompt, fd:<number>, file:<path>, env:<variable> or cmd:<command>", source)
+}
+
+// readPassphrase reads all data and removes one trailing line break. On errors, the data read so far is zeroed.
+func readPassphrase(reader io.Reader) ([]byte, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		ZeroPassphrase(data)
+		return nil, err
+	}
+	if bytes.HasSuffix(data, []byte("\n")) {
+		data = data[:len(data)-1]
+		if bytes.HasSuffix(data, []byte("\r")) {
+			data = data[:len(data)-1]
+		}
+	}
+	return data, nil
+}
+
+// ZeroPassphrase overwrites the passphrase with zeros.
+func ZeroPassphrase(passphrase []byte) {
+	for i := range passphrase {
+		passphrase[i] = 0
+	}
+}
+
+// passphraseFields are the body fields of all operations that require the current wallet passphrase.
+var passphraseFields = map[string]string{
+	"PostTransaction":          "passphrase",
+	"JoinStakePool":            "passphrase",
+	"QuitStakePool":            "passphrase",
+	"PutWalletPassphrase":      "old_passphrase",
+	"PostAccountKey":           "passphrase",
+	"SignMetadata":             "passphrase",
+	"MigrateShelleyWallet":     "passphrase",
+	"PostByronTransaction":     "passphrase",
+	"MigrateByronWallet":       "passphrase",
+	"PutByronWalletPassphrase": "old_passphrase",
+}
+
+// newPassphraseFields are the body fields of the operations that set a new wallet passphrase.
+var newPassphraseFields = map[string]string{
+	"PutWalletPassphrase":      "new_passphrase",
+	"PutByronWalletPassphrase": "new_passphrase",
+}
+
+// WithPassphraseProvider returns a ClientOption that inserts the passphrase of the provider into the bodies of all
+// operations that require the wallet passphrase: PostTransaction, JoinStakePool, QuitStakePool, PutWalletPassphrase
+// (old passphrase), PostAccountKey, SignMetadata, the migrations, and their Byron counterparts.
+// The passphrase is only inserted, if the body does not contain it already (or contains an empty string).
+// The passphrase and the resulting request body are overwritten with zeros, once the request body is closed.
+func WithPassphraseProvider(provider PassphraseProvider) ClientOption {
+	return withPassphraseFields(provider, passphraseFields)
+}
+
+// WithNewPassphraseProvider is like WithPassphraseProvider, but supplies the new passphrase
+// of PutWalletPassphrase and PutByronWalletPassphrase.
+func WithNewPassphraseProvider(provider PassphraseProvider) ClientOption {
+	return withPassphraseFields(provider, newPassphraseFields)
+}
+
+func withPassphraseFields(provider PassphraseProvider, fields map[string]string) ClientOption {
+	return func(c *Client) error {
+		c.RequestEditors = append(c.RequestEditors, func(ctx context.Context, req *http.Request) error {
+			op := OperationForRequest(req)
+			if op == nil || fields[op.Id] == "" || req.Body == nil {
+				return nil
+			}
+			return insertPassphrase(ctx, req, op.Id, fields[op.Id], provider)
+		})
+		return nil
+	}
+}
+
+func insertPassphrase(ctx context.Context, req *http.Request, operation string, field string, provider PassphraseProvider) error {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return fmt.Errorf("%v: failed to read request body: %v", operation, err)
+	}
+	req.Body.Close()
+
+	var parsed map[string]json.RawMessage
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return fmt.Errorf("%v: failed to parse request body: %v", operation, err)
+	}
+	if existing, ok := parsed[field]; ok && string(existing) != `""` && string(existing) != "null" {
+		// Keep the passphrase given in the body
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		return nil
+	}
+
+	passphrase, err := provider.Passphrase(ctx)
+	if err != nil {
+		return fmt.Errorf("%v: %v", operation, err)
+	}
+	defer ZeroPassphrase(passphrase)
+	if !utf8.Valid(passphrase) {
+		return fmt.Errorf("%v: passphrase is not valid UTF-8", operation)
+	}
+	if err := validatePassphraseLength(utf8.RuneCount(passphrase)); err != nil {
+		return fmt.Errorf("%v: %v", operation, err)
+	}
+
+	// Build the new body without passing the passphrase through strings, which cannot be zeroed
+	delete(parsed, field)
+	rest, err := json.Marshal(parsed)
+	if err != nil {
+		return fmt.Errorf("%v: failed to encode request body: %v", operation, err)
+	}
+	// Control characters are escaped with 6 bytes, reallocations would leave copies of the passphrase behind
+	newBody := make([]byte, 0, len(rest)+len(field)+6*len(passphrase)+8)
+	newBody = append(newBody, '{')
+	newBody = appendJSONString(newBody, []byte(field))
+	newBody = append(newBody, ':')
+	newBody = appendJSONString(newBody, passphrase)
+	if len(parsed) > 0 {
+		newBody = append(newBody, ',')
+		newBody = append(newBody, rest[1:]...)
+	} else {
+		newBody = append(newBody, '}')
+	}
+
+	req.Body = &zeroingBody{Reader: bytes.NewReader(newBody), data: newBody}
+	req.ContentLength = int64(len(newBody))
+	req.GetBody = nil
+	return nil
+}
+
+// appendJSONString appends the data as JSON string. The data must be valid UTF-8.
+func appendJSONString(buf []byte, data []byte) []byte {
+	const hexDigits = "0123456789abcdef"
+	buf = append(buf, '"')
+	for _, b := range data {
+		switch {
+		case b == '"' || b == '\\':
+			buf = append(buf, '\\', b)
+		case b < 0x20:
+			buf = append(buf, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xf])
+		default:
+			buf = append(buf, b)
+		}
+	}
+	return append(buf, '"')
+}
+
+// zeroingBody is a request body that overwrites its data with zeros when closed.
+type zeroingBody struct {
+	*bytes.Reader
+	data []byte
+}
+
+func (b *zeroingBody) Close() error {
+	ZeroPassphrase(b.data)
+	b.Reader.Reset(nil)
+	return nil
+}
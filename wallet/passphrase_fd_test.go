@@ -0,0 +1,27 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package wallet
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+)
+
+func (s *PassphraseTestSuite) TestFdPassphrase() {
+	reader, writer, err := os.Pipe()
+	s.NoError(err)
+	defer reader.Close()
+	writer.Write([]byte("from fd 123\r\n"))
+	writer.Close()
+	// FdPassphrase closes the file descriptor, so it gets a duplicate that is not owned by the *os.File
+	fd, err := syscall.Dup(int(reader.Fd()))
+	s.NoError(err)
+
+	provider, err := ParsePassphraseSource("fd:"+strconv.Itoa(fd), "")
+	s.NoError(err)
+	s.Equal("from fd 123", s.passphrase(provider))
+	s.Equal("from fd 123", s.passphrase(provider))
+	s.NoError(provider.(*CachedPassphrase).Close())
+}
@@ -0,0 +1,163 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type PassphraseTestSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestPassphrase(t *testing.T) {
+	testSuite := new(PassphraseTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *PassphraseTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+}
+
+func (s *PassphraseTestSuite) passphrase(provider PassphraseProvider) string {
+	passphrase, err := provider.Passphrase(context.Background())
+	s.NoError(err)
+	return string(passphrase)
+}
+
+func (s *PassphraseTestSuite) TestSources() {
+	dir, err := ioutil.TempDir("", "passphrase-test")
+	s.NoError(err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "passphrase")
+	s.NoError(ioutil.WriteFile(file, []byte("from file 123\n"), 0600))
+
+	provider, err := ParsePassphraseSource("file:"+file, "")
+	s.NoError(err)
+	s.Equal("from file 123", s.passphrase(provider))
+
+	os.Setenv("GODANO_TEST_PASSPHRASE", "from env 123")
+	defer os.Unsetenv("GODANO_TEST_PASSPHRASE")
+	provider, err = ParsePassphraseSource("env:GODANO_TEST_PASSPHRASE", "")
+	s.NoError(err)
+	s.Equal("from env 123", s.passphrase(provider))
+	_, err = EnvPassphrase("GODANO_TEST_PASSPHRASE_UNSET").Passphrase(context.Background())
+	s.Error(err)
+
+	provider, err = ParsePassphraseSource("cmd:cat "+file, "")
+	s.NoError(err)
+	s.Equal("from file 123", s.passphrase(provider))
+	_, err = CommandPassphrase("false").Passphrase(context.Background())
+	s.Error(err)
+
+	for _, invalid := range []string{"", "prompt:x", "fd:", "fd:x", "file:", "env:", "cmd: ", "password"} {
+		_, err = ParsePassphraseSource(invalid, "")
+		s.Error(err, invalid)
+	}
+}
+
+func (s *PassphraseTestSuite) TestCachedPassphrase() {
+	calls := 0
+	cached := NewCachedPassphrase(PassphraseFunc(func(ctx context.Context) ([]byte, error) {
+		calls++
+		return []byte("cached 12345"), nil
+	}))
+	first, err := cached.Passphrase(context.Background())
+	s.NoError(err)
+	ZeroPassphrase(first)
+	s.Equal("cached 12345", s.passphrase(cached))
+	s.Equal(1, calls)
+
+	cache := cached.cached
+	s.NoError(cached.Close())
+	s.Equal(make([]byte, len(cache)), cache)
+	_, err = cached.Passphrase(context.Background())
+	s.Error(err)
+}
+
+func (s *PassphraseTestSuite) TestInsertPassphrase() {
+	var received []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		s.NoError(json.NewDecoder(r.Body).Decode(&body))
+		received = append(received, body)
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	const passphrase = `secret "pass" \ 123 ä`
+	var provided [][]byte
+	provider := PassphraseFunc(func(ctx context.Context) ([]byte, error) {
+		data := []byte(passphrase)
+		provided = append(provided, data)
+		return data, nil
+	})
+	client, err := NewClient(server.URL+"/v2", WithPassphraseProvider(provider),
+		WithNewPassphraseProvider(PassphraseFunc(func(ctx context.Context) ([]byte, error) {
+			return []byte("new passphrase"), nil
+		})))
+	s.NoError(err)
+	ctx := context.Background()
+
+	_, err = client.PostTransaction(ctx, "w1", map[string]interface{}{"payments": []interface{}{}})
+	s.NoError(err)
+	s.Equal(passphrase, received[0]["passphrase"])
+	s.Contains(received[0], "payments")
+
+	_, err = client.PutWalletPassphraseWithBody(ctx, "w1", "application/json", strings.NewReader(`{}`))
+	s.NoError(err)
+	s.Equal(map[string]interface{}{"old_passphrase": passphrase, "new_passphrase": "new passphrase"}, received[1])
+
+	// Passphrases in the body are kept
+	_, err = client.JoinStakePoolWithBody(ctx, "pool", "w1", "application/json", strings.NewReader(`{"passphrase": "explicit 123"}`))
+	s.NoError(err)
+	s.Equal("explicit 123", received[2]["passphrase"])
+
+	// Other operations are not modified
+	_, err = client.PostTransactionFeeWithBody(ctx, "w1", "application/json", strings.NewReader(`{"payments": []}`))
+	s.NoError(err)
+	s.NotContains(received[3], "passphrase")
+
+	s.Len(provided, 2)
+	for _, data := range provided {
+		s.Equal(make([]byte, len(passphrase)), data, "passphrase was not zeroed")
+	}
+
+	// Invalid passphrases are rejected before sending
+	shortClient, err := NewClient(server.URL+"/v2", WithPassphraseProvider(PassphraseFunc(func(ctx context.Context) ([]byte, error) {
+		return []byte("short"), nil
+	})))
+	s.NoError(err)
+	_, err = shortClient.PostTransaction(ctx, "w1", map[string]interface{}{"payments": []interface{}{}})
+	s.Error(err)
+	s.Len(received, 4)
+}
+
+func (s *PassphraseTestSuite) TestZeroingBody() {
+	data := []byte(`{"passphrase":"0123456789"}`)
+	body := &zeroingBody{Reader: bytes.NewReader(data), data: data}
+	read, err := ioutil.ReadAll(body)
+	s.NoError(err)
+	s.Equal(`{"passphrase":"0123456789"}`, string(read))
+	s.NoError(body.Close())
+	s.Equal(make([]byte, len(data)), data)
+}
+
+func (s *PassphraseTestSuite) TestAppendJSONString() {
+	for _, str := range []string{"", "plain", `quote " backslash \ `, "tab\tnewline\n\x01", "ünïcödé ✓"} {
+		var decoded string
+		s.NoError(json.Unmarshal(appendJSONString(nil, []byte(str)), &decoded))
+		s.Equal(str, decoded)
+	}
+}
@@ -0,0 +1,297 @@
+package wallet
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ghodss/yaml"
+)
+
+// These env-var names select the profiles file and the profile, see LoadProfile().
+var (
+	EnvConfigFile = "GODANO_WALLET_CLIENT_CONFIG"
+	EnvProfile    = "GODANO_WALLET_CLIENT_PROFILE"
+)
+
+// ConfigFileName is the name of the profiles file inside the user configuration directory
+// ($XDG_CONFIG_HOME or ~/.config on Linux), see DefaultConfigFile().
+const ConfigFileName = "godano-wallet-client/config.yaml"
+
+// Output formats of profiles
+const (
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+)
+
+// Duration is a time.Duration, which is encoded as string like "30s" or "5m" in profiles files.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("Duration must be a string like \"30s\": %s", data)
+	}
+	parsed, err := time.ParseDuration(str)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Profile is a named connection configuration for a cardano-wallet server, as stored in the profiles file.
+// The server is any target supported by Dial().
+// Relative file names are resolved relative to the directory of the profiles file.
+type Profile struct {
+	Name string `json:"-"`
+
+	Server        string `json:"server,omitempty"`
+	ServerCA      string `json:"server_ca,omitempty"`
+	ClientCert    string `json:"client_cert,omitempty"`
+	ClientKey     string `json:"client_key,omitempty"`
+	TLSSkipVerify bool   `json:"tls_skip_verify,omitempty"`
+
+	// DialTimeout limits establishing connections, the default is DefaultTransportConfig().DialTimeout
+	DialTimeout Duration `json:"dial_timeout,omitempty"`
+	// Timeout limits entire requests, including reading the response. The default is no limit.
+	Timeout Duration `json:"timeout,omitempty"`
+	// OperationTimeouts overrides entries of DefaultOperationTimeouts(), keyed by operation Id like "ListStakePools"
+	OperationTimeouts map[string]Duration `json:"operation_timeouts,omitempty"`
+
+	// DefaultWallet is the wallet id used by the CLI, if the walletId argument is omitted
+	DefaultWallet string `json:"default_wallet,omitempty"`
+	// Output is the output format of the CLI, OutputJSON or OutputYAML
+	Output string `json:"output,omitempty"`
+	// AuditLog is the file, in which the CLI records all mutating operations, see WithAuditLog()
+	AuditLog string `json:"audit_log,omitempty"`
+	// AuditLogKeyFrom is the source of the HMAC key of the audit log, see ReadAuditLogKey()
+	AuditLogKeyFrom string `json:"audit_log_key_from,omitempty"`
+}
+
+// ProfilesFile is the content of the profiles file. The profile named by DefaultProfile is used,
+// when no profile is selected explicitly.
+type ProfilesFile struct {
+	DefaultProfile string              `json:"default_profile,omitempty"`
+	Profiles       map[string]*Profile `json:"profiles"`
+}
+
+// DefaultConfigFile returns the path of the profiles file: the value of GODANO_WALLET_CLIENT_CONFIG,
+// or ConfigFileName inside the user configuration directory.
+func DefaultConfigFile() (string, error) {
+	if file := os.Getenv(EnvConfigFile); file != "" {
+		return file, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, filepath.FromSlash(ConfigFileName)), nil
+}
+
+// LoadProfilesFile reads and validates a profiles file.
+func LoadProfilesFile(path string) (*ProfilesFile, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file := new(ProfilesFile)
+	if err := yaml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("Failed to parse profiles file %v: %v", path, err)
+	}
+	dir := filepath.Dir(path)
+	for name, profile := range file.Profiles {
+		if profile == nil {
+			profile = new(Profile)
+			file.Profiles[name] = profile
+		}
+		profile.Name = name
+		for _, fileName := range []*string{&profile.ServerCA, &profile.ClientCert, &profile.ClientKey, &profile.AuditLog} {
+			if *fileName != "" && !filepath.IsAbs(*fileName) {
+				*fileName = filepath.Join(dir, *fileName)
+			}
+		}
+		if err := profile.Validate(); err != nil {
+			return nil, fmt.Errorf("Profile %v in %v: %v", name, path, err)
+		}
+	}
+	if file.DefaultProfile != "" && file.Profiles[file.DefaultProfile] == nil {
+		return nil, fmt.Errorf("Default profile %v is not defined in %v", file.DefaultProfile, path)
+	}
+	return file, nil
+}
+
+// ProfileNames returns the sorted names of all profiles.
+func (f *ProfilesFile) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadProfile loads the profile with the given name from the default profiles file, see LoadProfileFromFile().
+func LoadProfile(name string) (*Profile, error) {
+	return LoadProfileFromFile("", name)
+}
+
+// LoadProfileFromFile loads a profile and applies the GODANO_WALLET_CLIENT_* environment variables on top of it.
+// The precedence is: environment variables, then the profile, then the defaults. Flags of the CLI take precedence over all of them.
+//
+// An empty path selects DefaultConfigFile(). An empty name selects the profile named by GODANO_WALLET_CLIENT_PROFILE,
+// or the default profile of the file. If neither a name is given nor the default profiles file exists, the result
+// only contains the values of the environment variables.
+func LoadProfileFromFile(path string, name string) (*Profile, error) {
+	explicitPath := path != "" || os.Getenv(EnvConfigFile) != ""
+	if path == "" {
+		var err error
+		if path, err = DefaultConfigFile(); err != nil {
+			return nil, err
+		}
+	}
+	if name == "" {
+		name = os.Getenv(EnvProfile)
+	}
+
+	profile := new(Profile)
+	file, err := LoadProfilesFile(path)
+	switch {
+	case err == nil:
+		if name == "" {
+			name = file.DefaultProfile
+		}
+		if name != "" {
+			if file.Profiles[name] == nil {
+				return nil, fmt.Errorf("Profile %v is not defined in %v, available profiles: %v", name, path, file.ProfileNames())
+			}
+			copied := *file.Profiles[name]
+			profile = &copied
+		}
+	case os.IsNotExist(err) && !explicitPath && name == "":
+		// Without profiles file, only the environment variables are used
+	default:
+		return nil, err
+	}
+
+	if err := profile.applyEnv(); err != nil {
+		return nil, err
+	}
+	return profile, profile.Validate()
+}
+
+// applyEnv overwrites the profile values with the values of the GODANO_WALLET_CLIENT_* environment variables, if set.
+func (p *Profile) applyEnv() error {
+	if server := os.Getenv(EnvVarWalletServerAddress); server != "" {
+		p.Server = server
+	}
+	if skipVerifyStr := os.Getenv(EnvTLSSkipVerify); skipVerifyStr != "" {
+		skipVerify, err := strconv.ParseBool(skipVerifyStr)
+		if err != nil {
+			return fmt.Errorf("Failed to parse env-var %v=%v as bool: %v", EnvTLSSkipVerify, skipVerifyStr, err)
+		}
+		p.TLSSkipVerify = skipVerify
+	}
+	if serverCAFile := os.Getenv(EnvServerCAFile); serverCAFile != "" {
+		p.ServerCA = serverCAFile
+	}
+	clientCertFile := os.Getenv(EnvClientCertFile)
+	clientKeyFile := os.Getenv(EnvClientKeyFile)
+	if (clientCertFile == "") != (clientKeyFile == "") {
+		return fmt.Errorf("Either none or both of these env-vars must be defined: %v, %v",
+			EnvClientCertFile, EnvClientKeyFile)
+	}
+	if clientCertFile != "" {
+		p.ClientCert, p.ClientKey = clientCertFile, clientKeyFile
+	}
+	return nil
+}
+
+// Validate checks the client certificate settings, the operation timeouts and the output format.
+func (p *Profile) Validate() error {
+	if (p.ClientCert == "") != (p.ClientKey == "") {
+		return fmt.Errorf("Either none or both of client_cert and client_key must be set")
+	}
+	if err := p.operationTimeouts().Validate(); err != nil {
+		return err
+	}
+	switch p.Output {
+	case "", OutputJSON, OutputYAML:
+	default:
+		return fmt.Errorf("Unknown output format %v, expected %v or %v", p.Output, OutputJSON, OutputYAML)
+	}
+	return nil
+}
+
+// TLSConfig loads the certificates of the profile.
+func (p *Profile) TLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: p.TLSSkipVerify}
+	if p.ServerCA != "" {
+		caRootPool, err := LoadCACert(p.ServerCA)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load server CA file '%v': %v", p.ServerCA, err)
+		}
+		tlsConfig.RootCAs = caRootPool
+	}
+	if p.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(p.ClientCert, p.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// DialOptions returns the options for Dial with the TLS settings and timeouts of the profile.
+func (p *Profile) DialOptions() ([]DialOption, error) {
+	tlsConfig, err := p.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	opts := []DialOption{WithTLSConfig(tlsConfig), WithRequestTimeout(time.Duration(p.Timeout))}
+	if p.DialTimeout > 0 {
+		opts = append(opts, WithDialTimeout(time.Duration(p.DialTimeout)))
+	}
+	if len(p.OperationTimeouts) > 0 {
+		opts = append(opts, WithClientOptions(WithOperationTimeouts(p.operationTimeouts())))
+	}
+	return opts, nil
+}
+
+func (p *Profile) operationTimeouts() OperationTimeouts {
+	result := make(OperationTimeouts, len(p.OperationTimeouts))
+	for id, timeout := range p.OperationTimeouts {
+		result[id] = time.Duration(timeout)
+	}
+	return result
+}
+
+// Dial connects to the server of the profile, see Dial(). The given options are applied after the options of the profile.
+func (p *Profile) Dial(ctx context.Context, opts ...DialOption) (*ClientWithResponses, error) {
+	if p.Server == "" {
+		return nil, fmt.Errorf("No server address configured, set it in the profile or in %v", EnvVarWalletServerAddress)
+	}
+	profileOpts, err := p.DialOptions()
+	if err != nil {
+		return nil, err
+	}
+	return Dial(ctx, p.Server, append(profileOpts, opts...)...)
+}
+
+// NewClientWithResponses returns a client for the server of the profile, without checking the connection.
+// The given options are applied after the options of the profile.
+func (p *Profile) NewClientWithResponses(opts ...ClientOption) (*ClientWithResponses, error) {
+	return p.Dial(context.Background(), WithoutProbe(), WithClientOptions(opts...))
+}
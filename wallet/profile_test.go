@@ -0,0 +1,151 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const testProfilesFile = `
+default_profile: local
+profiles:
+  local:
+    server: https://localhost:8090/v2
+    server_ca: tls/ca.crt
+    tls_skip_verify: true
+    timeout: 30s
+    default_wallet: 2512a00e9653fe49a44a5886202e24d77eeb998f
+  mainnet:
+    server: https://mainnet.example:8090/v2
+    client_cert: /etc/wallet/client.pem
+    client_key: /etc/wallet/client.key
+    dial_timeout: 5s
+    operation_timeouts:
+      ListStakePools: 1h
+    output: yaml
+    audit_log: audit.jsonl
+    audit_log_key_from: env:AUDIT_LOG_KEY
+`
+
+type ProfileTestSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	dir  string
+	file string
+	env  map[string]string
+}
+
+func TestProfile(t *testing.T) {
+	testSuite := new(ProfileTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *ProfileTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+}
+
+func (s *ProfileTestSuite) SetupTest() {
+	var err error
+	s.dir, err = ioutil.TempDir("", "profile-test")
+	s.NoError(err)
+	s.file = filepath.Join(s.dir, "config.yaml")
+	s.NoError(ioutil.WriteFile(s.file, []byte(testProfilesFile), 0600))
+
+	// Clear the environment, restore it after the test
+	s.env = make(map[string]string)
+	for _, name := range []string{EnvConfigFile, EnvProfile, EnvVarWalletServerAddress, EnvTLSSkipVerify, EnvServerCAFile, EnvClientCertFile, EnvClientKeyFile, "XDG_CONFIG_HOME"} {
+		if value, ok := os.LookupEnv(name); ok {
+			s.env[name] = value
+		}
+		os.Unsetenv(name)
+	}
+}
+
+func (s *ProfileTestSuite) TearDownTest() {
+	os.RemoveAll(s.dir)
+	for name, value := range s.env {
+		os.Setenv(name, value)
+	}
+}
+
+func (s *ProfileTestSuite) TestLoad() {
+	profile, err := LoadProfileFromFile(s.file, "")
+	s.NoError(err)
+	s.Equal(&Profile{
+		Name:          "local",
+		Server:        "https://localhost:8090/v2",
+		ServerCA:      filepath.Join(s.dir, "tls/ca.crt"),
+		TLSSkipVerify: true,
+		Timeout:       Duration(30 * time.Second),
+		DefaultWallet: "2512a00e9653fe49a44a5886202e24d77eeb998f",
+	}, profile)
+
+	profile, err = LoadProfileFromFile(s.file, "mainnet")
+	s.NoError(err)
+	s.Equal("/etc/wallet/client.pem", profile.ClientCert)
+	s.Equal(Duration(5*time.Second), profile.DialTimeout)
+	s.Equal(map[string]Duration{"ListStakePools": Duration(time.Hour)}, profile.OperationTimeouts)
+	s.Equal(OutputYAML, profile.Output)
+	s.Equal(filepath.Join(s.dir, "audit.jsonl"), profile.AuditLog)
+	s.Equal("env:AUDIT_LOG_KEY", profile.AuditLogKeyFrom)
+
+	os.Setenv(EnvProfile, "mainnet")
+	profile, err = LoadProfileFromFile(s.file, "")
+	s.NoError(err)
+	s.Equal("mainnet", profile.Name)
+
+	_, err = LoadProfileFromFile(s.file, "preprod")
+	s.Error(err)
+	s.Contains(err.Error(), "[local mainnet]")
+}
+
+func (s *ProfileTestSuite) TestPrecedence() {
+	os.Setenv(EnvConfigFile, s.file)
+	os.Setenv(EnvVarWalletServerAddress, "https://env:8090/v2")
+	os.Setenv(EnvTLSSkipVerify, "false")
+	profile, err := LoadProfile("")
+	s.NoError(err)
+	s.Equal("local", profile.Name)
+	s.Equal("https://env:8090/v2", profile.Server)
+	s.False(profile.TLSSkipVerify)
+	s.Equal(Duration(30*time.Second), profile.Timeout)
+
+	os.Setenv(EnvClientCertFile, "cert.pem")
+	_, err = LoadProfile("")
+	s.Error(err)
+}
+
+func (s *ProfileTestSuite) TestMissingFile() {
+	missing := filepath.Join(s.dir, "missing.yaml")
+	_, err := LoadProfileFromFile(missing, "")
+	s.Error(err)
+
+	// Without explicit file and profile, a missing default file is no error
+	os.Setenv("XDG_CONFIG_HOME", s.dir)
+	os.Setenv(EnvVarWalletServerAddress, "https://env:8090/v2")
+	profile, err := LoadProfile("")
+	s.NoError(err)
+	s.Equal(&Profile{Server: "https://env:8090/v2"}, profile)
+	_, err = LoadProfile("local")
+	s.Error(err)
+}
+
+func (s *ProfileTestSuite) TestInvalidFile() {
+	for _, content := range []string{
+		"profiles:\n  a:\n    output: xml\n",
+		"profiles:\n  a:\n    client_cert: cert.pem\n",
+		"profiles:\n  a:\n    timeout: 30\n",
+		"profiles:\n  a:\n    operation_timeouts:\n      ListPools: 1h\n",
+		"default_profile: b\nprofiles:\n  a:\n    server: x\n",
+	} {
+		s.NoError(ioutil.WriteFile(s.file, []byte(content), 0600))
+		_, err := LoadProfilesFile(s.file)
+		s.Error(err, content)
+	}
+}
@@ -0,0 +1,206 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// RedactedValue replaces the values of secret fields in the output of Redact().
+const RedactedValue = "[redacted]"
+
+// mnemonicWordFormat is the format prefix of mnemonic words in the Swagger specification.
+const mnemonicWordFormat = "bip-0039-mnemonic-word"
+
+var (
+	secretFieldsOnce sync.Once
+	secretFields     map[string]string // Field name to JSON type of the secret values ("string" or "array")
+	secretFieldsErr  error
+)
+
+// SecretFields returns the names of all JSON fields that contain secrets, as derived from the request and response
+// schemas of the Swagger specification: string fields holding passphrases (passphrase, old_passphrase, new_passphrase, ...)
+// and arrays of mnemonic words (mnemonic_sentence, mnemonic_second_factor, ...).
+func SecretFields() ([]string, error) {
+	fields, err := loadSecretFields()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(fields))
+	for field := range fields {
+		result = append(result, field)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func loadSecretFields() (map[string]string, error) {
+	secretFieldsOnce.Do(func() {
+		var ops []*Operation
+		ops, secretFieldsErr = Operations()
+		if secretFieldsErr != nil {
+			return
+		}
+		secretFields = make(map[string]string)
+		visited := make(map[*openapi3.Schema]bool)
+		for _, op := range ops {
+			if op.Spec.RequestBody != nil && op.Spec.RequestBody.Value != nil {
+				collectSecretFields(op.Spec.RequestBody.Value.Content, visited)
+			}
+			for _, resp := range op.Spec.Responses {
+				if resp.Value != nil {
+					collectSecretFields(resp.Value.Content, visited)
+				}
+			}
+		}
+	})
+	return secretFields, secretFieldsErr
+}
+
+func collectSecretFields(content openapi3.Content, visited map[*openapi3.Schema]bool) {
+	for _, mediaType := range content {
+		collectSchemaSecretFields(mediaType.Schema, visited)
+	}
+}
+
+func collectSchemaSecretFields(ref *openapi3.SchemaRef, visited map[*openapi3.Schema]bool) {
+	if ref == nil || ref.Value == nil || visited[ref.Value] {
+		return
+	}
+	schema := ref.Value
+	visited[schema] = true
+	for name, property := range schema.Properties {
+		if property.Value != nil {
+			if secretType := secretSchemaType(name, property.Value); secretType != "" {
+				secretFields[name] = secretType
+			}
+		}
+		collectSchemaSecretFields(property, visited)
+	}
+	for _, refs := range []openapi3.SchemaRefs{schema.OneOf, schema.AnyOf, schema.AllOf} {
+		for _, sub := range refs {
+			collectSchemaSecretFields(sub, visited)
+		}
+	}
+	collectSchemaSecretFields(schema.Items, visited)
+	collectSchemaSecretFields(schema.AdditionalProperties, visited)
+}
+
+// secretSchemaType returns "string" for passphrases and "array" for mnemonic word lists, or an empty string for
+// other schemas. Objects describing passphrases (like the "passphrase" field with the last update time in GetWallet
+// responses) are not secret. For fields with alternative schemas, like the "withdrawal" field that is either
+// "self" or a mnemonic sentence, only the secret alternative is returned.
+func secretSchemaType(name string, schema *openapi3.Schema) string {
+	switch schema.Type {
+	case "string":
+		if strings.Contains(name, "passphrase") {
+			return "string"
+		}
+	case "array":
+		if schema.Items != nil && schema.Items.Value != nil && strings.HasPrefix(schema.Items.Value.Format, mnemonicWordFormat) {
+			return "array"
+		}
+	}
+	for _, sub := range schema.OneOf {
+		if sub.Value != nil {
+			if secretType := secretSchemaType(name, sub.Value); secretType != "" {
+				return secretType
+			}
+		}
+	}
+	return ""
+}
+
+// Redact replaces the values of all secret fields (see SecretFields) in the given JSON data with RedactedValue,
+// at any nesting level. Values are only replaced if their type matches the secret schema, so for example objects with
+// the same field name are kept.
+// Data that is not valid JSON or contains no secrets is returned unchanged.
+func Redact(data []byte) []byte {
+	fields, err := loadSecretFields()
+	if err != nil || !containsAnyField(data, fields) {
+		return data
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var parsed interface{}
+	if err := decoder.Decode(&parsed); err != nil {
+		return data
+	}
+	if !redactValue(parsed, fields) {
+		return data
+	}
+	var redacted bytes.Buffer
+	encoder := json.NewEncoder(&redacted)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(parsed); err != nil {
+		return data
+	}
+	return bytes.TrimSuffix(redacted.Bytes(), []byte("\n"))
+}
+
+// RedactObject marshals the given object to JSON and redacts it. For objects that cannot be marshalled,
+// only the type is returned, because their fields could contain secrets.
+func RedactObject(obj interface{}) string {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Sprintf("<%T>", obj)
+	}
+	return string(Redact(data))
+}
+
+// containsAnyField is a quick check that avoids parsing data without any secret fields.
+func containsAnyField(data []byte, fields map[string]string) bool {
+	for field := range fields {
+		if bytes.Contains(data, []byte(`"`+field+`"`)) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValue replaces secrets in the parsed JSON value and returns true, if anything was replaced.
+func redactValue(value interface{}, fields map[string]string) bool {
+	redacted := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			if isSecretValue(val, fields[key]) {
+				v[key] = RedactedValue
+				redacted = true
+			} else if redactValue(val, fields) {
+				redacted = true
+			}
+		}
+	case []interface{}:
+		for _, val := range v {
+			if redactValue(val, fields) {
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
+
+// isSecretValue returns true, if the value has the JSON type of the secret schema.
+func isSecretValue(value interface{}, secretType string) bool {
+	switch v := value.(type) {
+	case string:
+		return secretType == "string"
+	case []interface{}:
+		if secretType != "array" {
+			return false
+		}
+		for _, item := range v {
+			if _, ok := item.(string); !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
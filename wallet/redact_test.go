@@ -0,0 +1,66 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type RedactTestSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestRedact(t *testing.T) {
+	testSuite := new(RedactTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *RedactTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+}
+
+func (s *RedactTestSuite) TestSecretFields() {
+	fields, err := SecretFields()
+	s.NoError(err)
+	for _, field := range []string{"passphrase", "old_passphrase", "new_passphrase", "mnemonic_sentence", "mnemonic_second_factor"} {
+		s.Contains(fields, field)
+	}
+	s.NotContains(fields, "name")
+	s.NotContains(fields, "address_pool_gap")
+}
+
+func (s *RedactTestSuite) TestRedact() {
+	for input, expected := range map[string]string{
+		// Request bodies
+		`{"name": "w", "passphrase": "0123456789", "mnemonic_sentence": ["a", "b"], "address_pool_gap": 20}`: `{"address_pool_gap":20,"mnemonic_sentence":"[redacted]","name":"w","passphrase":"[redacted]"}`,
+		`{"old_passphrase": "0123456789", "new_passphrase": "9876543210"}`:                                   `{"new_passphrase":"[redacted]","old_passphrase":"[redacted]"}`,
+		`{"payments": [], "withdrawal": ["word"], "nested": [{"mnemonic_second_factor": ["x"]}]}`:            `{"nested":[{"mnemonic_second_factor":"[redacted]"}],"payments":[],"withdrawal":"[redacted]"}`,
+		// The withdrawal is only secret if it is a mnemonic sentence
+		`{"withdrawal": "self", "passphrase": "a&b"}`: `{"passphrase":"[redacted]","withdrawal":"self"}`,
+		// Large numbers are kept exactly
+		`{"passphrase": "x", "amount": 123456789012345678901234567890}`: `{"amount":123456789012345678901234567890,"passphrase":"[redacted]"}`,
+	} {
+		s.Equal(expected, string(Redact([]byte(input))), input)
+	}
+
+	// Unchanged data is returned as is
+	for _, input := range []string{
+		`{"name": "w",  "address_pool_gap": 20}`,
+		`{"id": "w", "passphrase": {"last_updated_at": "2021-01-01T00:00:00Z"}}`,
+		`not json "passphrase"`,
+		``,
+	} {
+		s.Equal(input, string(Redact([]byte(input))))
+	}
+}
+
+func (s *RedactTestSuite) TestRedactObject() {
+	s.Equal(`{"name":"w","passphrase":"[redacted]"}`, RedactObject(map[string]string{"name": "w", "passphrase": "0123456789"}))
+
+	// Objects that cannot be marshalled are not formatted, since they could contain secrets
+	invalid := &WalletPostData{Name: "w", Passphrase: "0123456789", MnemonicSentence: []string{"a"}}
+	s.Equal("<*wallet.WalletPostData>", RedactObject(invalid))
+	s.Equal("<*struct { F func() }>", RedactObject(&struct{ F func() }{}))
+}
@@ -0,0 +1,281 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Kinds of limits, see LimitEvent
+const (
+	LimitRate        = "rate"
+	LimitConcurrency = "concurrency"
+)
+
+// LimitEvent describes how a request passed the limits configured with WithRateLimit() and WithConcurrencyLimit(),
+// see WithLimitCallback().
+type LimitEvent struct {
+	Operation string
+	// Wait is the time the request was queued, before it was sent or rejected
+	Wait time.Duration
+	// Rejected is true, if the context of the request ended while it was queued. The request was not sent.
+	Rejected bool
+	// Limit is the kind of limit (LimitRate or LimitConcurrency) that delayed or rejected the request the longest,
+	// empty if the request was not delayed
+	Limit string
+	// Scope is the operation Id of the limit, empty for the limits of all operations
+	Scope string
+}
+
+// RequestLimitError is returned for requests, whose context ended while they were waiting for a rate or concurrency limit.
+type RequestLimitError struct {
+	Operation string
+	Limit     string
+	Scope     string
+	Wait      time.Duration
+	Err       error
+}
+
+func (e *RequestLimitError) Error() string {
+	scope := "all operations"
+	if e.Scope != "" {
+		scope = e.Scope
+	}
+	return fmt.Sprintf("%v: rejected after waiting %v for the %v limit of %v: %v", e.Operation, e.Wait, e.Limit, scope, e.Err)
+}
+
+// Unwrap returns the error of the context, e.g. context.DeadlineExceeded.
+func (e *RequestLimitError) Unwrap() error {
+	return e.Err
+}
+
+// WithRateLimit returns a ClientOption that limits the requests of all operations with a token bucket: on average,
+// at most rate requests per second are sent, with bursts of up to burst requests. A burst of 0 selects the rate,
+// but at least 1. Requests wait until a token is available, or until their context ends.
+//
+// All limits of a client are one Middleware (see WithMiddleware()), which is added by the first limit option.
+// When it is added after WithOperationTimeouts(), the time spent waiting for the limits does not count for the timeouts.
+func WithRateLimit(rate float64, burst int) ClientOption {
+	return WithOperationRateLimit("", rate, burst)
+}
+
+// WithOperationRateLimit is like WithRateLimit(), but only limits the requests of the operation with the given Id.
+// These requests are also subject to the limits of all operations.
+func WithOperationRateLimit(operationId string, rate float64, burst int) ClientOption {
+	return withLimiter(operationId, func(l *limiter) error {
+		if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+			return fmt.Errorf("Invalid rate limit %v", rate)
+		}
+		if burst <= 0 {
+			burst = int(math.Max(1, math.Ceil(rate)))
+		}
+		l.bucket = &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+		return nil
+	})
+}
+
+// WithConcurrencyLimit returns a ClientOption that limits the number of requests of all operations, which are in flight
+// at the same time. A request is in flight until its response body is closed. Requests wait for a free slot,
+// or until their context ends.
+func WithConcurrencyLimit(max int) ClientOption {
+	return WithOperationConcurrencyLimit("", max)
+}
+
+// WithOperationConcurrencyLimit is like WithConcurrencyLimit(), but only limits the requests of the operation
+// with the given Id. These requests are also subject to the limits of all operations.
+func WithOperationConcurrencyLimit(operationId string, max int) ClientOption {
+	return withLimiter(operationId, func(l *limiter) error {
+		if max <= 0 {
+			return fmt.Errorf("Invalid concurrency limit %v", max)
+		}
+		l.slots = make(chan struct{}, max)
+		return nil
+	})
+}
+
+// WithLimitCallback sets a callback, which is called for every request that passed the configured limits or was
+// rejected by them. The callback is called synchronously and must not block.
+func WithLimitCallback(callback func(LimitEvent)) ClientOption {
+	return func(c *Client) error {
+		limitsOf(c).callback = callback
+		return nil
+	}
+}
+
+func withLimiter(operationId string, configure func(*limiter) error) ClientOption {
+	return func(c *Client) error {
+		if operationId != "" && OperationById(operationId) == nil {
+			return fmt.Errorf("Unknown operation %v in request limits", operationId)
+		}
+		limits := limitsOf(c)
+		l := limits.limiters[operationId]
+		if l == nil {
+			l = &limiter{scope: operationId}
+			limits.limiters[operationId] = l
+		}
+		return configure(l)
+	}
+}
+
+// limitsOf returns the limitMiddleware of the client, after adding it if necessary.
+func limitsOf(c *Client) *limitMiddleware {
+	middlewares := middlewaresOf(c)
+	if limits, ok := middlewares.last(func(m Middleware) bool {
+		_, ok := m.(*limitMiddleware)
+		return ok
+	}).(*limitMiddleware); ok {
+		return limits
+	}
+	limits := &limitMiddleware{limiters: make(map[string]*limiter)}
+	middlewares.add(limits)
+	return limits
+}
+
+// limiter is a token bucket and/or a semaphore, for all operations or for one operation.
+type limiter struct {
+	scope  string
+	bucket *tokenBucket
+	slots  chan struct{}
+}
+
+type tokenBucket struct {
+	rate  float64
+	burst float64
+
+	lock   sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// take removes a token from the bucket, waiting until one is available or the context ends.
+// The result tells whether the caller had to wait.
+func (b *tokenBucket) take(ctx context.Context) (bool, error) {
+	for waited := false; ; waited = true {
+		b.lock.Lock()
+		now := time.Now()
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.lock.Unlock()
+			return waited, nil
+		}
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.lock.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return true, ctx.Err()
+		}
+	}
+}
+
+// acquire takes a free slot, waiting until one is available or the context ends.
+// The result tells whether the caller had to wait.
+func acquire(ctx context.Context, slots chan struct{}) (bool, error) {
+	select {
+	case slots <- struct{}{}:
+		return false, nil
+	default:
+	}
+	select {
+	case slots <- struct{}{}:
+		return true, nil
+	case <-ctx.Done():
+		return true, ctx.Err()
+	}
+}
+
+type limitMiddleware struct {
+	limiters map[string]*limiter
+	callback func(LimitEvent)
+}
+
+func (m *limitMiddleware) Do(req *http.Request, next HttpRequestDoer) (*http.Response, error) {
+	opId := ""
+	if op := OperationForRequest(req); op != nil {
+		opId = op.Id
+	}
+	// The limits of the operation are checked first, so that waiting requests of one operation do not hold
+	// the slots of all operations
+	limiters := make([]*limiter, 0, 2)
+	if l := m.limiters[opId]; opId != "" && l != nil {
+		limiters = append(limiters, l)
+	}
+	if l := m.limiters[""]; l != nil {
+		limiters = append(limiters, l)
+	}
+
+	ctx := req.Context()
+	event := LimitEvent{Operation: opId}
+	var longestWait time.Duration
+	var release []chan struct{}
+	releaseAll := func() {
+		for _, slots := range release {
+			<-slots
+		}
+	}
+	start := time.Now()
+	for _, l := range limiters {
+		for _, kind := range []string{LimitConcurrency, LimitRate} {
+			waitStart := time.Now()
+			var waited bool
+			var err error
+			switch {
+			case kind == LimitConcurrency && l.slots != nil:
+				if waited, err = acquire(ctx, l.slots); err == nil {
+					release = append(release, l.slots)
+				}
+			case kind == LimitRate && l.bucket != nil:
+				waited, err = l.bucket.take(ctx)
+			default:
+				continue
+			}
+			if wait := time.Since(waitStart); waited && wait >= longestWait {
+				longestWait = wait
+				event.Limit, event.Scope = kind, l.scope
+			}
+			if err != nil {
+				releaseAll()
+				event.Wait, event.Rejected = time.Since(start), true
+				m.notify(event)
+				return nil, &RequestLimitError{Operation: opId, Limit: kind, Scope: l.scope, Wait: event.Wait, Err: err}
+			}
+		}
+	}
+	event.Wait = time.Since(start)
+	m.notify(event)
+
+	resp, err := next.Do(req)
+	if err != nil || len(release) == 0 {
+		releaseAll()
+		return resp, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: releaseAll}
+	return resp, nil
+}
+
+func (m *limitMiddleware) notify(event LimitEvent) {
+	if m.callback != nil {
+		m.callback(event)
+	}
+}
+
+// releasingBody frees the concurrency slots of a request, when its response body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	defer b.once.Do(b.release)
+	return b.ReadCloser.Close()
+}
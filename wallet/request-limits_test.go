@@ -0,0 +1,197 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type RequestLimitsTestSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	server *httptest.Server
+	ctx    context.Context
+
+	lock        sync.Mutex
+	inFlight    int
+	maxInFlight int
+	delay       time.Duration
+	events      []LimitEvent
+}
+
+func TestRequestLimits(t *testing.T) {
+	testSuite := new(RequestLimitsTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *RequestLimitsTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+	s.ctx = context.Background()
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.lock.Lock()
+		s.inFlight++
+		if s.inFlight > s.maxInFlight {
+			s.maxInFlight = s.inFlight
+		}
+		delay := s.delay
+		s.lock.Unlock()
+		time.Sleep(delay)
+		s.lock.Lock()
+		s.inFlight--
+		s.lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}))
+}
+
+func (s *RequestLimitsTestSuite) TearDownSuite() {
+	s.server.Close()
+}
+
+func (s *RequestLimitsTestSuite) SetupTest() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.inFlight, s.maxInFlight, s.delay, s.events = 0, 0, 0, nil
+}
+
+// newClient uses the HTTP client of the test server, so that the connections are not shared with other tests.
+func (s *RequestLimitsTestSuite) newClient(opts ...ClientOption) *Client {
+	opts = append([]ClientOption{WithHTTPClient(s.server.Client())}, opts...)
+	opts = append(opts, WithLimitCallback(func(event LimitEvent) {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		s.events = append(s.events, event)
+	}))
+	client, err := NewClient(s.server.URL+"/v2", opts...)
+	s.NoError(err)
+	return client
+}
+
+// recorded returns the limit events and the maximum number of parallel requests seen by the server so far.
+func (s *RequestLimitsTestSuite) recorded() ([]LimitEvent, int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]LimitEvent(nil), s.events...), s.maxInFlight
+}
+
+// parallel runs the given number of ListWallets requests in parallel and returns their errors.
+func (s *RequestLimitsTestSuite) parallel(client *Client, count int) []error {
+	errs := make([]error, count)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := client.ListWallets(s.ctx)
+			if err == nil {
+				resp.Body.Close()
+			}
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
+
+func (s *RequestLimitsTestSuite) TestConcurrencyLimit() {
+	s.lock.Lock()
+	s.delay = 20 * time.Millisecond
+	s.lock.Unlock()
+	client := s.newClient(WithConcurrencyLimit(2))
+	for _, err := range s.parallel(client, 6) {
+		s.NoError(err)
+	}
+	events, maxInFlight := s.recorded()
+	s.Equal(2, maxInFlight)
+	s.Len(events, 6)
+	delayed := 0
+	for _, event := range events {
+		s.Equal("ListWallets", event.Operation)
+		s.False(event.Rejected)
+		if event.Limit != "" {
+			s.Equal(LimitConcurrency, event.Limit)
+			s.Equal("", event.Scope)
+			delayed++
+		}
+	}
+	s.True(delayed >= 4, "%v requests were delayed", delayed)
+}
+
+func (s *RequestLimitsTestSuite) TestRateLimit() {
+	client := s.newClient(WithRateLimit(20, 1))
+	start := time.Now()
+	for _, err := range s.parallel(client, 3) {
+		s.NoError(err)
+	}
+	s.True(time.Since(start) >= 90*time.Millisecond, "3 requests took %v", time.Since(start))
+	var longest time.Duration
+	events, _ := s.recorded()
+	for _, event := range events {
+		if event.Wait > longest {
+			longest = event.Wait
+		}
+	}
+	s.True(longest >= 90*time.Millisecond, "Longest wait %v", longest)
+}
+
+func (s *RequestLimitsTestSuite) TestOperationLimits() {
+	client := s.newClient(WithOperationConcurrencyLimit("ListWallets", 1), WithOperationRateLimit("GetSettings", 1, 1))
+
+	// The held response blocks further ListWallets requests, but not other operations
+	held, err := client.ListWallets(s.ctx)
+	s.NoError(err)
+	for i := 0; i < 3; i++ {
+		resp, err := client.ListByronWallets(s.ctx)
+		s.NoError(err)
+		resp.Body.Close()
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = client.ListWallets(ctx)
+	s.Error(err)
+	s.True(errors.Is(err, context.DeadlineExceeded))
+	var limitErr *RequestLimitError
+	s.True(errors.As(err, &limitErr))
+	s.Equal(LimitConcurrency, limitErr.Limit)
+	s.Equal("ListWallets", limitErr.Scope)
+	events, _ := s.recorded()
+	last := events[len(events)-1]
+	s.True(last.Rejected)
+	s.True(last.Wait >= 20*time.Millisecond)
+
+	// Closing the body frees the slot
+	held.Body.Close()
+	resp, err := client.ListWallets(s.ctx)
+	s.NoError(err)
+	resp.Body.Close()
+
+	resp, err = client.GetSettings(s.ctx)
+	s.NoError(err)
+	resp.Body.Close()
+	ctx, cancel = context.WithTimeout(s.ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = client.GetSettings(ctx)
+	s.True(errors.As(err, &limitErr))
+	s.Equal(LimitRate, limitErr.Limit)
+}
+
+func (s *RequestLimitsTestSuite) TestInvalidLimits() {
+	for _, opt := range []ClientOption{
+		WithRateLimit(0, 1),
+		WithConcurrencyLimit(0),
+		WithOperationRateLimit("ListWallet", 1, 1),
+		WithOperationConcurrencyLimit("ListWallet", 1),
+	} {
+		_, err := NewClient(s.server.URL+"/v2", opt)
+		s.Error(err)
+	}
+}
@@ -0,0 +1,115 @@
+package wallet
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Headers of the W3C Trace Context specification, see https://www.w3.org/TR/trace-context/
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+)
+
+// TraceContext is the trace context of a request, as encoded in the traceparent and tracestate headers.
+type TraceContext struct {
+	TraceId [16]byte
+	// SpanId is the parent-id of the traceparent header, i.e. the id of the span that sends the request
+	SpanId [8]byte
+	Flags  byte
+	// State is the value of the tracestate header, which is propagated unmodified
+	State string
+}
+
+// TraceSampled is the flag of sampled traces
+const TraceSampled = 0x01
+
+// ParseTraceParent parses a traceparent header value like "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+func ParseTraceParent(header string) (*TraceContext, error) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return nil, fmt.Errorf("Invalid traceparent header: %v", header)
+	}
+	result := new(TraceContext)
+	var flags [1]byte
+	for _, field := range []struct {
+		value  string
+		target []byte
+	}{{parts[1], result.TraceId[:]}, {parts[2], result.SpanId[:]}, {parts[3], flags[:]}} {
+		if len(field.value) != 2*len(field.target) || strings.ToLower(field.value) != field.value {
+			return nil, fmt.Errorf("Invalid traceparent header: %v", header)
+		}
+		if _, err := hex.Decode(field.target, []byte(field.value)); err != nil {
+			return nil, fmt.Errorf("Invalid traceparent header: %v", header)
+		}
+	}
+	if result.TraceId == [16]byte{} || result.SpanId == [8]byte{} {
+		return nil, fmt.Errorf("Invalid traceparent header, the ids must not be zero: %v", header)
+	}
+	result.Flags = flags[0]
+	return result, nil
+}
+
+// String returns the traceparent header value.
+func (t *TraceContext) String() string {
+	return fmt.Sprintf("00-%x-%x-%02x", t.TraceId, t.SpanId, t.Flags)
+}
+
+// NewTraceContext starts a new sampled trace with random ids.
+func NewTraceContext() (*TraceContext, error) {
+	result := &TraceContext{Flags: TraceSampled}
+	if _, err := rand.Read(result.TraceId[:]); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(result.SpanId[:]); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// child returns a trace context with the same trace id and a new random span id.
+func (t *TraceContext) child() (*TraceContext, error) {
+	result := *t
+	if _, err := rand.Read(result.SpanId[:]); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+type traceContextKey struct{}
+
+// ContextWithTrace returns a context, whose requests are sent as part of the given trace by PropagateTraceContext().
+func ContextWithTrace(ctx context.Context, trace *TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, trace)
+}
+
+// TraceFromContext returns the trace context stored by ContextWithTrace(), or nil.
+func TraceFromContext(ctx context.Context) *TraceContext {
+	trace, _ := ctx.Value(traceContextKey{}).(*TraceContext)
+	return trace
+}
+
+// PropagateTraceContext is a RequestEditorFn, which sets the traceparent and tracestate headers of requests.
+// Each request gets a new span id. The trace id is taken from the trace context of the request context
+// (see ContextWithTrace()), or a new sampled trace is started for each request. Use it with WithRequestEditorFn().
+func PropagateTraceContext(ctx context.Context, req *http.Request) error {
+	var trace *TraceContext
+	var err error
+	if parent := TraceFromContext(ctx); parent != nil {
+		trace, err = parent.child()
+	} else {
+		trace, err = NewTraceContext()
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to create trace context: %v", err)
+	}
+	req.Header.Set(TraceParentHeader, trace.String())
+	if trace.State != "" {
+		req.Header.Set(TraceStateHeader, trace.State)
+	}
+	return nil
+}
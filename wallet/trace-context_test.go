@@ -0,0 +1,78 @@
+package wallet
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TraceContextTestSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestTraceContext(t *testing.T) {
+	testSuite := new(TraceContextTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *TraceContextTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+}
+
+func (s *TraceContextTestSuite) TestParse() {
+	header := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	trace, err := ParseTraceParent(header)
+	s.NoError(err)
+	s.Equal(header, trace.String())
+	s.Equal(byte(TraceSampled), trace.Flags)
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902bx-01",
+	} {
+		_, err := ParseTraceParent(invalid)
+		s.Error(err, invalid)
+	}
+	// Future versions may append fields
+	_, err = ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra")
+	s.NoError(err)
+}
+
+func (s *TraceContextTestSuite) TestPropagate() {
+	trace, err := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	s.NoError(err)
+
+	// Requests get new span ids of the trace in the context
+	trace.State = "vendor=value"
+	ctx := ContextWithTrace(context.Background(), trace)
+	s.Equal(trace, TraceFromContext(ctx))
+	req, err := http.NewRequest(http.MethodGet, "http://localhost/v2/wallets", nil)
+	s.NoError(err)
+	s.NoError(PropagateTraceContext(ctx, req))
+	child, err := ParseTraceParent(req.Header.Get(TraceParentHeader))
+	s.NoError(err)
+	s.Equal(trace.TraceId, child.TraceId)
+	s.NotEqual(trace.SpanId, child.SpanId)
+	s.Equal(trace.Flags, child.Flags)
+	s.Equal("vendor=value", req.Header.Get(TraceStateHeader))
+
+	// Without trace in the context, a new trace is started
+	req, err = http.NewRequest(http.MethodGet, "http://localhost/v2/wallets", nil)
+	s.NoError(err)
+	s.NoError(PropagateTraceContext(context.Background(), req))
+	started, err := ParseTraceParent(req.Header.Get(TraceParentHeader))
+	s.NoError(err)
+	s.NotEqual(trace.TraceId, started.TraceId)
+	s.Empty(req.Header.Get(TraceStateHeader))
+}
@@ -2,8 +2,12 @@ package wallet
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"math"
+	"reflect"
 	"strconv"
+	"unicode/utf8"
 )
 
 const (
@@ -15,8 +19,16 @@ const (
 
 	MetadataMapKey = "k"
 	MetadataMapVal = "v"
+
+	// MetadataStringMaxLength is the maximum length of strings and bytestrings in metadata, in bytes.
+	MetadataStringMaxLength = 64
 )
 
+// BytesKey represents a bytestring that is used as key in a metadata map.
+// Parse() returns map keys of this type instead of []byte, which cannot be used as map key.
+// EncodeMetadata() encodes values of this type as bytestrings.
+type BytesKey string
+
 // Metadata represents transaction metadata, as it is stored on the ledger.
 // This type is manually added here, because oapi-codegen fails to generate it.
 // The doc-comment below is copied from the Swagger definition.
@@ -106,11 +118,7 @@ func parseMetaValue(path string, rawVal interface{}) (interface{}, error) {
 		// This loop will be entered only once
 		switch valType {
 		case MetadataTypeInt:
-			intVal, ok := actualVal.(int) // TODO this does not match int64 when encoding
-			if !ok {
-				return nil, fmt.Errorf("%v: expected type int, but got: %s", path, str(actualVal))
-			}
-			return intVal, nil
+			return parseMetaInt(path, actualVal)
 		case MetadataTypeString:
 			strVal, ok := actualVal.(string)
 			if !ok {
@@ -142,6 +150,37 @@ func parseMetaValue(path string, rawVal interface{}) (interface{}, error) {
 	return val, nil
 }
 
+// parseMetaInt accepts the numeric types produced by encoding/json (float64 or json.Number),
+// as well as the integer types produced by EncodeMetadata().
+func parseMetaInt(path string, rawVal interface{}) (interface{}, error) {
+	switch val := rawVal.(type) {
+	case int:
+		return val, nil
+	case int64:
+		return int(val), nil
+	case uint64:
+		if val > math.MaxInt64 {
+			return val, nil
+		}
+		return int(val), nil
+	case float64:
+		if val != math.Trunc(val) {
+			return nil, fmt.Errorf("%v: expected integer, but got: %s", path, str(rawVal))
+		}
+		return int(val), nil
+	case json.Number:
+		if intVal, err := val.Int64(); err == nil {
+			return int(intVal), nil
+		}
+		uintVal, err := strconv.ParseUint(string(val), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%v: expected integer, but got: %s", path, str(rawVal))
+		}
+		return uintVal, nil
+	}
+	return nil, fmt.Errorf("%v: expected type int, but got: %s", path, str(rawVal))
+}
+
 func parseByteString(path string, rawStr string) ([]byte, error) {
 	result := make([]byte, hex.DecodedLen(len(rawStr)))
 	length, err := hex.Decode(result, []byte(rawStr))
@@ -191,6 +230,9 @@ func parseMap(path string, rawList []interface{}) (map[interface{}]interface{},
 			return nil, err
 		}
 
+		if bytesKey, isBytes := parsedKey.([]byte); isBytes {
+			parsedKey = BytesKey(bytesKey)
+		}
 		_, collision := result[parsedKey]
 		if collision {
 			// TODO any better error handling?
@@ -221,26 +263,22 @@ func encodeMetaValue(path string, val interface{}) (interface{}, error) {
 	case string:
 		encodedType = MetadataTypeString
 		encodedVal = typedVal
-		break
 
 	case []byte:
 		encodedType = MetadataTypeBytes
-		encodedVal = typedVal
-		break
+		encodedVal = hex.EncodeToString(typedVal)
 
-	case int:
-	case int8: // covers byte
-	case int16:
-	case int32: // covers rune
-	case int64:
-	case uint:
-	case uint8:
-	case uint16:
-	case uint32:
-	case uint64:
+	case BytesKey:
+		encodedType = MetadataTypeBytes
+		encodedVal = hex.EncodeToString([]byte(typedVal))
+
+	case int, int8, int16, int32, int64: // covers rune
 		encodedType = MetadataTypeInt
-		encodedVal = int64(typedVal)
-		break
+		encodedVal = reflect.ValueOf(typedVal).Int()
+
+	case uint, uint8, uint16, uint32, uint64: // covers byte
+		encodedType = MetadataTypeInt
+		encodedVal = reflect.ValueOf(typedVal).Uint()
 
 	case bool: // TODO bool is not covered when parsing
 		encodedType = MetadataTypeInt
@@ -251,10 +289,7 @@ func encodeMetaValue(path string, val interface{}) (interface{}, error) {
 		}
 
 	// Avoid unexpected behavior due to rounding
-	case float32:
-	case float64:
-	case complex64:
-	case complex128:
+	case float32, float64, complex64, complex128:
 		return nil, fmt.Errorf("%v: encoding floating point and complex values unsupported (value: %v)", path, val)
 
 	case []interface{}:
@@ -264,16 +299,14 @@ func encodeMetaValue(path string, val interface{}) (interface{}, error) {
 		if err != nil {
 			return nil, err
 		}
-		break
 
 	case map[interface{}]interface{}:
-		encodedType = MetadataTypeList
+		encodedType = MetadataTypeMap
 		var err error
 		encodedVal, err = encodeMetaMap(path, typedVal)
 		if err != nil {
 			return nil, err
 		}
-		break
 
 	default:
 		return nil, fmt.Errorf("%v: cannot encode value of unexpected type: %s", path, str(val))
@@ -293,9 +326,7 @@ func encodeMetaList(path string, listVal []interface{}) (interface{}, error) {
 		}
 		encodedList[i] = encodedVal
 	}
-	return map[string]interface{}{
-		MetadataTypeList: encodedList,
-	}, nil
+	return encodedList, nil
 }
 
 func encodeMetaMap(path string, mapVal map[interface{}]interface{}) (interface{}, error) {
@@ -316,7 +347,20 @@ func encodeMetaMap(path string, mapVal map[interface{}]interface{}) (interface{}
 			MetadataMapVal: encodedVal,
 		})
 	}
-	return map[string]interface{}{
-		MetadataTypeMap: encodedMap,
-	}, nil
+	return encodedMap, nil
+}
+
+// SplitMetadataString splits the given string into chunks of at most MetadataStringMaxLength bytes.
+// Chunks are only split at rune boundaries, so that multi-byte UTF-8 characters stay intact.
+func SplitMetadataString(s string) []string {
+	var chunks []string
+	for len(s) > MetadataStringMaxLength {
+		end := MetadataStringMaxLength
+		for end > 0 && !utf8.RuneStart(s[end]) {
+			end--
+		}
+		chunks = append(chunks, s[:end])
+		s = s[end:]
+	}
+	return append(chunks, s)
 }
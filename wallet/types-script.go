@@ -0,0 +1,373 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/godano/cardano-wallet-client/bech32"
+	"github.com/godano/cardano-wallet-client/internal/cbor"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// ScriptKeyHashPrefix is the Bech32 prefix of key hashes in scripts.
+	ScriptKeyHashPrefix = "script_vkh"
+	// ScriptHashPrefix is the Bech32 prefix of script hashes.
+	ScriptHashPrefix = "script"
+
+	scriptCosignerPrefix = "cosigner#"
+
+	// Tags of native scripts in the ledger CBOR encoding
+	scriptTagKeyHash     = 0
+	scriptTagAll         = 1
+	scriptTagAny         = 2
+	scriptTagSome        = 3
+	scriptTagActiveFrom  = 4
+	scriptTagActiveUntil = 5
+
+	// Prefix byte of native scripts when computing the script hash
+	nativeScriptHashPrefix = 0x00
+
+	maxScriptAtLeast = 255
+)
+
+// Script is a native script as used in ScriptValue (with key hashes) and ScriptTemplateValue (with cosigners).
+// Exactly one of the fields must be set. The JSON representation is the one of the cardano-wallet API,
+// so a *Script can be used wherever the generated types expect a ScriptValue or ScriptTemplateValue.
+type Script struct {
+	// Leaf of ScriptValue: the blake2b-224 hash of a verification key
+	KeyHash []byte
+
+	// Leaf of ScriptTemplateValue: the index of a cosigner
+	Cosigner *int
+
+	All         []*Script
+	Any         []*Script
+	Some        *ScriptSome
+	ActiveFrom  *uint64
+	ActiveUntil *uint64
+}
+
+// ScriptSome requires at least AtLeast of the From scripts to be satisfied.
+type ScriptSome struct {
+	AtLeast int       `json:"at_least"`
+	From    []*Script `json:"from"`
+}
+
+// ScriptKeyHash returns a script that requires a signature of the key with the given hash.
+func ScriptKeyHash(hash []byte) *Script {
+	return &Script{KeyHash: hash}
+}
+
+// ScriptCosigner returns a script template leaf that requires a signature of the given cosigner.
+func ScriptCosigner(index int) *Script {
+	return &Script{Cosigner: &index}
+}
+
+// ScriptAll returns a script that requires all given scripts to be satisfied.
+func ScriptAll(scripts ...*Script) *Script {
+	return &Script{All: scripts}
+}
+
+// ScriptAny returns a script that requires one of the given scripts to be satisfied.
+func ScriptAny(scripts ...*Script) *Script {
+	return &Script{Any: scripts}
+}
+
+// ScriptAtLeast returns a script that requires n of the given scripts to be satisfied.
+func ScriptAtLeast(n int, scripts ...*Script) *Script {
+	return &Script{Some: &ScriptSome{AtLeast: n, From: scripts}}
+}
+
+// ScriptActiveFrom returns a script that is satisfied starting at the given slot (slot >= from).
+func ScriptActiveFrom(slot uint64) *Script {
+	return &Script{ActiveFrom: &slot}
+}
+
+// ScriptActiveUntil returns a script that is satisfied before the given slot (slot < until).
+func ScriptActiveUntil(slot uint64) *Script {
+	return &Script{ActiveUntil: &slot}
+}
+
+// ParseScript parses a native script from the JSON representation of the cardano-wallet API.
+func ParseScript(data []byte) (*Script, error) {
+	script := new(Script)
+	if err := json.Unmarshal(data, script); err != nil {
+		return nil, err
+	}
+	return script, nil
+}
+
+// ScriptFromValue converts a ScriptValue or ScriptTemplateValue (e.g. map[string]interface{} from a response) to a *Script.
+func ScriptFromValue(val interface{}) (*Script, error) {
+	if script, ok := val.(*Script); ok {
+		return script, nil
+	}
+	marshalled, err := json.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+	return ParseScript(marshalled)
+}
+
+// Validate checks that exactly one field is set in the script and all nested scripts,
+// and that the parameters are within the limits of the cardano-wallet API.
+func (s *Script) Validate() error {
+	return s.validate("script")
+}
+
+func (s *Script) validate(path string) error {
+	if s == nil {
+		return fmt.Errorf("%v: missing script", path)
+	}
+	set := 0
+	for _, isSet := range []bool{s.KeyHash != nil, s.Cosigner != nil, s.All != nil, s.Any != nil,
+		s.Some != nil, s.ActiveFrom != nil, s.ActiveUntil != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("%v: expected exactly one script type, but %v are set", path, set)
+	}
+	switch {
+	case s.KeyHash != nil:
+		if len(s.KeyHash) != keyHashSize {
+			return fmt.Errorf("%v: unexpected key hash length %v, expected %v", path, len(s.KeyHash), keyHashSize)
+		}
+	case s.Cosigner != nil:
+		if *s.Cosigner < 0 {
+			return fmt.Errorf("%v: negative cosigner index %v", path, *s.Cosigner)
+		}
+	case s.All != nil:
+		return validateScripts(path+"/all", s.All)
+	case s.Any != nil:
+		return validateScripts(path+"/any", s.Any)
+	case s.Some != nil:
+		if s.Some.AtLeast < 1 || s.Some.AtLeast > maxScriptAtLeast {
+			return fmt.Errorf("%v: at_least must be between 1 and %v, but is %v", path, maxScriptAtLeast, s.Some.AtLeast)
+		}
+		if s.Some.AtLeast > len(s.Some.From) {
+			return fmt.Errorf("%v: at_least is %v, but only %v scripts are given", path, s.Some.AtLeast, len(s.Some.From))
+		}
+		return validateScripts(path+"/some", s.Some.From)
+	}
+	return nil
+}
+
+func validateScripts(path string, scripts []*Script) error {
+	if len(scripts) == 0 {
+		return fmt.Errorf("%v: empty list of scripts", path)
+	}
+	for i, script := range scripts {
+		if err := script.validate(fmt.Sprintf("%v/%v", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalJSON encodes the script in the format of the cardano-wallet API.
+func (s *Script) MarshalJSON() ([]byte, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	switch {
+	case s.KeyHash != nil:
+		encoded, err := bech32.Encode(ScriptKeyHashPrefix, s.KeyHash)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(encoded)
+	case s.Cosigner != nil:
+		return json.Marshal(scriptCosignerPrefix + strconv.Itoa(*s.Cosigner))
+	case s.All != nil:
+		return json.Marshal(map[string]interface{}{"all": s.All})
+	case s.Any != nil:
+		return json.Marshal(map[string]interface{}{"any": s.Any})
+	case s.Some != nil:
+		return json.Marshal(map[string]interface{}{"some": s.Some})
+	case s.ActiveFrom != nil:
+		return json.Marshal(map[string]interface{}{"active_from": *s.ActiveFrom})
+	default:
+		return json.Marshal(map[string]interface{}{"active_until": *s.ActiveUntil})
+	}
+}
+
+// UnmarshalJSON parses the script from the format of the cardano-wallet API.
+func (s *Script) UnmarshalJSON(data []byte) error {
+	*s = Script{}
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var leaf string
+		if err := json.Unmarshal(data, &leaf); err != nil {
+			return err
+		}
+		return s.parseLeaf(leaf)
+	}
+	var fields struct {
+		All         []*Script   `json:"all"`
+		Any         []*Script   `json:"any"`
+		Some        *ScriptSome `json:"some"`
+		ActiveFrom  *uint64     `json:"active_from"`
+		ActiveUntil *uint64     `json:"active_until"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("Failed to parse script: %v", err)
+	}
+	s.All, s.Any, s.Some = fields.All, fields.Any, fields.Some
+	s.ActiveFrom, s.ActiveUntil = fields.ActiveFrom, fields.ActiveUntil
+	return s.Validate()
+}
+
+func (s *Script) parseLeaf(leaf string) error {
+	if strings.HasPrefix(leaf, scriptCosignerPrefix) {
+		index, err := strconv.Atoi(strings.TrimPrefix(leaf, scriptCosignerPrefix))
+		if err != nil {
+			return fmt.Errorf("Invalid cosigner %v: %v", leaf, err)
+		}
+		s.Cosigner = &index
+		return s.Validate()
+	}
+	hrp, hash, err := bech32.Decode(leaf)
+	if err != nil {
+		return fmt.Errorf("Invalid script key hash %v: %v", leaf, err)
+	}
+	if !strings.HasSuffix(hrp, "_vkh") {
+		return fmt.Errorf("Unexpected Bech32 prefix %v for script key hash", hrp)
+	}
+	s.KeyHash = hash
+	return s.Validate()
+}
+
+// MarshalCBOR returns the ledger encoding of the script. Scripts with cosigners (templates) cannot be encoded.
+func (s *Script) MarshalCBOR() ([]byte, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	e := new(cbor.Encoder)
+	if err := s.encodeCBOR(e); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+func (s *Script) encodeCBOR(e *cbor.Encoder) error {
+	encodeList := func(scripts []*Script) error {
+		e.WriteArrayHeader(len(scripts))
+		for _, script := range scripts {
+			if err := script.encodeCBOR(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	switch {
+	case s.KeyHash != nil:
+		e.WriteArrayHeader(2)
+		e.WriteUint(scriptTagKeyHash)
+		e.WriteBytes(s.KeyHash)
+	case s.Cosigner != nil:
+		return fmt.Errorf("Cannot encode script template with cosigner %v, the key hash is unknown", *s.Cosigner)
+	case s.All != nil:
+		e.WriteArrayHeader(2)
+		e.WriteUint(scriptTagAll)
+		return encodeList(s.All)
+	case s.Any != nil:
+		e.WriteArrayHeader(2)
+		e.WriteUint(scriptTagAny)
+		return encodeList(s.Any)
+	case s.Some != nil:
+		e.WriteArrayHeader(3)
+		e.WriteUint(scriptTagSome)
+		e.WriteUint(uint64(s.Some.AtLeast))
+		return encodeList(s.Some.From)
+	case s.ActiveFrom != nil:
+		e.WriteArrayHeader(2)
+		e.WriteUint(scriptTagActiveFrom)
+		e.WriteUint(*s.ActiveFrom)
+	default:
+		e.WriteArrayHeader(2)
+		e.WriteUint(scriptTagActiveUntil)
+		e.WriteUint(*s.ActiveUntil)
+	}
+	return nil
+}
+
+// Hash returns the script hash, which is also the policy ID of minting policies.
+func (s *Script) Hash() ([]byte, error) {
+	encoded, err := s.MarshalCBOR()
+	if err != nil {
+		return nil, err
+	}
+	hash, _ := blake2b.New(keyHashSize, nil) // Only fails for invalid sizes
+	hash.Write([]byte{nativeScriptHashPrefix})
+	hash.Write(encoded)
+	return hash.Sum(nil), nil
+}
+
+// PolicyId returns the hex-encoded script hash.
+func (s *Script) PolicyId() (string, error) {
+	hash, err := s.Hash()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash), nil
+}
+
+// Evaluate returns true, if the script is satisfied at the given slot, when isSigner returns true for the signing leafs
+// (KeyHash or Cosigner) that have signed.
+func (s *Script) Evaluate(isSigner func(leaf *Script) bool, slot uint64) bool {
+	countSatisfied := func(scripts []*Script) int {
+		count := 0
+		for _, script := range scripts {
+			if script.Evaluate(isSigner, slot) {
+				count++
+			}
+		}
+		return count
+	}
+	switch {
+	case s.KeyHash != nil, s.Cosigner != nil:
+		return isSigner(s)
+	case s.All != nil:
+		return countSatisfied(s.All) == len(s.All)
+	case s.Any != nil:
+		return countSatisfied(s.Any) > 0
+	case s.Some != nil:
+		return countSatisfied(s.Some.From) >= s.Some.AtLeast
+	case s.ActiveFrom != nil:
+		return slot >= *s.ActiveFrom
+	case s.ActiveUntil != nil:
+		return slot < *s.ActiveUntil
+	}
+	return false
+}
+
+// SatisfiedBy returns true, if signatures of the given key hashes satisfy the script at the given slot.
+func (s *Script) SatisfiedBy(keyHashes [][]byte, slot uint64) bool {
+	return s.Evaluate(func(leaf *Script) bool {
+		for _, hash := range keyHashes {
+			if leaf.KeyHash != nil && bytes.Equal(leaf.KeyHash, hash) {
+				return true
+			}
+		}
+		return false
+	}, slot)
+}
+
+// SatisfiedByCosigners returns true, if signatures of the given cosigners satisfy the script template at the given slot.
+func (s *Script) SatisfiedByCosigners(cosigners []int, slot uint64) bool {
+	return s.Evaluate(func(leaf *Script) bool {
+		for _, cosigner := range cosigners {
+			if leaf.Cosigner != nil && *leaf.Cosigner == cosigner {
+				return true
+			}
+		}
+		return false
+	}, slot)
+}
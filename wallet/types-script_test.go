@@ -0,0 +1,143 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/godano/cardano-wallet-client/address"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/blake2b"
+)
+
+type ScriptTestSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestScript(t *testing.T) {
+	testSuite := new(ScriptTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *ScriptTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+}
+
+const testScriptKeyHash = "script_vkh18srsxr3khll7vl3w9mqfu55n6wzxxlxj7qzr2mhnyreluzt36ms"
+
+func (s *ScriptTestSuite) keyHash(b byte) []byte {
+	hash := make([]byte, keyHashSize)
+	hash[0] = b
+	return hash
+}
+
+func (s *ScriptTestSuite) TestJSONRoundTrip() {
+	input := `{"any": [
+		"` + testScriptKeyHash + `",
+		{"all": ["` + testScriptKeyHash + `", {"active_from": 100}, {"active_until": 200}]},
+		{"some": {"at_least": 1, "from": ["` + testScriptKeyHash + `"]}}
+	]}`
+	script, err := ParseScript([]byte(input))
+	s.NoError(err)
+	s.Len(script.Any, 3)
+	s.Equal(uint64(100), *script.Any[1].All[1].ActiveFrom)
+	s.Equal(1, script.Any[2].Some.AtLeast)
+
+	marshalled, err := json.Marshal(script)
+	s.NoError(err)
+	s.JSONEq(input, string(marshalled))
+
+	// Scripts can be used for the generated interface{} types
+	var value ScriptValue = script
+	converted, err := ScriptFromValue(value)
+	s.NoError(err)
+	s.Equal(script, converted)
+	var decoded interface{}
+	s.NoError(json.Unmarshal(marshalled, &decoded))
+	converted, err = ScriptFromValue(decoded)
+	s.NoError(err)
+	s.Equal(script, converted)
+}
+
+func (s *ScriptTestSuite) TestTemplate() {
+	template, err := ParseScript([]byte(`{"some": {"at_least": 2, "from": ["cosigner#0", "cosigner#1", "cosigner#2"]}}`))
+	s.NoError(err)
+	s.Equal(1, *template.Some.From[1].Cosigner)
+	s.True(template.SatisfiedByCosigners([]int{0, 2}, 0))
+	s.False(template.SatisfiedByCosigners([]int{1}, 0))
+
+	_, err = template.Hash()
+	s.Error(err, "templates have no key hashes")
+}
+
+func (s *ScriptTestSuite) TestInvalidScripts() {
+	for _, invalid := range []string{
+		`{}`,
+		`{"all": []}`,
+		`{"any": ["addr1qx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgse35a3x"]}`,
+		`{"some": {"at_least": 3, "from": ["cosigner#0", "cosigner#1"]}}`,
+		`{"some": {"at_least": 0, "from": ["cosigner#0"]}}`,
+		`{"all": ["cosigner#x"]}`,
+		`{"active_from": 1, "active_until": 2}`,
+	} {
+		_, err := ParseScript([]byte(invalid))
+		s.Error(err, invalid)
+	}
+	_, err := json.Marshal(&Script{})
+	s.Error(err)
+}
+
+func (s *ScriptTestSuite) TestHash() {
+	script := ScriptAll(ScriptKeyHash(s.keyHash(0x01)), ScriptActiveUntil(100))
+	encoded, err := script.MarshalCBOR()
+	s.NoError(err)
+	// [1, [[0, h'01...'], [5, 100]]]
+	expected := "8201828200581c01" + strings.Repeat("00", keyHashSize-1) + "82051864"
+	s.Equal(expected, hex.EncodeToString(encoded))
+
+	hash, err := script.Hash()
+	s.NoError(err)
+	s.Len(hash, keyHashSize)
+	hasher, _ := blake2b.New(keyHashSize, nil)
+	hasher.Write(append([]byte{0x00}, encoded...))
+	s.Equal(hasher.Sum(nil), hash)
+
+	policyId, err := script.PolicyId()
+	s.NoError(err)
+	s.Equal(hex.EncodeToString(hash), policyId)
+}
+
+func (s *ScriptTestSuite) TestEvaluate() {
+	alice, bob, carol := s.keyHash(1), s.keyHash(2), s.keyHash(3)
+	script := ScriptAny(
+		ScriptAll(ScriptKeyHash(alice), ScriptActiveFrom(100), ScriptActiveUntil(200)),
+		ScriptAtLeast(2, ScriptKeyHash(alice), ScriptKeyHash(bob), ScriptKeyHash(carol)),
+	)
+	s.True(script.SatisfiedBy([][]byte{alice}, 100))
+	s.True(script.SatisfiedBy([][]byte{alice}, 199))
+	s.False(script.SatisfiedBy([][]byte{alice}, 99))
+	s.False(script.SatisfiedBy([][]byte{alice}, 200))
+	s.True(script.SatisfiedBy([][]byte{bob, carol}, 0))
+	s.False(script.SatisfiedBy([][]byte{bob}, 150))
+	s.False(script.SatisfiedBy(nil, 150))
+}
+
+func (s *ScriptTestSuite) TestScriptAddress() {
+	script := ScriptAtLeast(1, ScriptKeyHash(s.keyHash(1)), ScriptKeyHash(s.keyHash(2)))
+	hash, err := script.Hash()
+	s.NoError(err)
+
+	addr, err := NewAddressBuilder().Payment(ScriptCredential(script)).Offline(address.NetworkTestnet)
+	s.NoError(err)
+	s.Equal(address.TypeEnterpriseScript, addr.Type)
+	s.Equal(hash, addr.Payment.Hash)
+
+	body, err := NewAddressBuilder().Payment(ScriptCredential(script)).Validation(ScriptValidationRequired).Body()
+	s.NoError(err)
+	marshalled, err := json.Marshal(body)
+	s.NoError(err)
+	s.Contains(string(marshalled), `"some":{"at_least":1`)
+}
@@ -0,0 +1,183 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/godano/cardano-wallet-client/bech32"
+)
+
+const (
+	// VotingRegistrationLabel is the metadata label of Catalyst voting registrations (CIP-15/CIP-36).
+	VotingRegistrationLabel = 61284
+	// VotingSignatureLabel is the metadata label of the signature of a Catalyst voting registration.
+	VotingSignatureLabel = 61285
+
+	// DefaultVotingRegistrationAmount is the amount of Lovelace that a voting registration transaction
+	// sends to the wallet itself. Every transaction needs at least one output.
+	DefaultVotingRegistrationAmount = 1000000
+
+	// The wallet signs voting registrations with its stake key
+	votingKeyRole  = "mutable_account"
+	votingKeyIndex = "0"
+
+	rewardAddressHeader = 0xE0 // Shelley reward address with a key hash stake credential
+	keyHashSize         = 28
+)
+
+// VotingRegistration contains the fields of a Catalyst voting registration, as stored under label 61284.
+type VotingRegistration struct {
+	VotingKey     []byte // Ed25519 public key that is used for voting
+	StakeKey      []byte // Ed25519 public stake key of the wallet
+	RewardAddress []byte // Raw reward address that receives the voting rewards
+	Nonce         uint64 // Usually the current slot number
+}
+
+// Metadata returns the registration as transaction metadata under label 61284.
+func (r *VotingRegistration) Metadata() (Metadata, error) {
+	return EncodeMetadata(map[uint]interface{}{
+		VotingRegistrationLabel: map[interface{}]interface{}{
+			1: r.VotingKey,
+			2: r.StakeKey,
+			3: r.RewardAddress,
+			4: r.Nonce,
+		},
+	})
+}
+
+// VotingRegistrationRequest configures BuildVotingRegistration.
+type VotingRegistrationRequest struct {
+	WalletId  string
+	VotingKey []byte
+
+	// Passphrase of the wallet, used for SignMetadata and included in the resulting body. If empty, the passphrase
+	// must be inserted by a client created with WithPassphraseProvider.
+	Passphrase string
+
+	// Amount of Lovelace sent to the wallet's own address. Defaults to DefaultVotingRegistrationAmount.
+	Amount int
+}
+
+// ParseVotingKey parses a 32-byte Ed25519 public key, given either hex-encoded or Bech32-encoded.
+// See ParsePublicKey.
+func ParseVotingKey(key string) ([]byte, error) {
+	return ParsePublicKey(key)
+}
+
+// BuildVotingRegistration creates a Catalyst voting registration (CIP-15/CIP-36) for the given wallet.
+// The stake key is queried through GetWalletKey, the nonce is the current slot of the node, and the registration
+// is signed by the wallet through SignMetadata. The result is a body for PostTransaction, which sends a small
+// amount to the wallet's own address and carries the registration and its signature as metadata.
+func BuildVotingRegistration(ctx context.Context, client ClientWithResponsesInterface, req *VotingRegistrationRequest) (PostTransactionJSONRequestBody, *VotingRegistration, error) {
+	if len(req.VotingKey) != 32 {
+		return nil, nil, fmt.Errorf("Voting key must be 32 bytes, but has %v bytes", len(req.VotingKey))
+	}
+	amount := req.Amount
+	if amount == 0 {
+		amount = DefaultVotingRegistrationAmount
+	}
+
+	keyResp, err := client.GetWalletKeyWithResponse(ctx, req.WalletId, votingKeyRole, votingKeyIndex)
+	if err != nil {
+		return nil, nil, err
+	}
+	if keyResp.JSON200 == nil {
+		return nil, nil, unexpectedResponse("GetWalletKey", keyResp.HTTPResponse, keyResp.Body)
+	}
+	_, stakeKey, err := bech32.Decode(*keyResp.JSON200)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to decode stake key %v: %v", *keyResp.JSON200, err)
+	}
+
+	// The payment goes to the first address of the wallet, which also tells us the network
+	addrResp, err := client.ListAddressesWithResponse(ctx, req.WalletId, new(ListAddressesParams))
+	if err != nil {
+		return nil, nil, err
+	}
+	if addrResp.JSON200 == nil {
+		return nil, nil, unexpectedResponse("ListAddresses", addrResp.HTTPResponse, addrResp.Body)
+	}
+	if len(*addrResp.JSON200) == 0 {
+		return nil, nil, fmt.Errorf("Wallet %v does not have any addresses", req.WalletId)
+	}
+	ownAddress := (*addrResp.JSON200)[0].Id
+	_, ownAddressBytes, err := bech32.Decode(ownAddress)
+	if err != nil || len(ownAddressBytes) == 0 {
+		return nil, nil, fmt.Errorf("Failed to decode wallet address %v: %v", ownAddress, err)
+	}
+	networkId := ownAddressBytes[0] & 0x0F
+
+	netResp, err := client.GetNetworkInformationWithResponse(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if netResp.JSON200 == nil {
+		return nil, nil, unexpectedResponse("GetNetworkInformation", netResp.HTTPResponse, netResp.Body)
+	}
+
+	registration := &VotingRegistration{
+		VotingKey:     req.VotingKey,
+		StakeKey:      stakeKey,
+		RewardAddress: rewardAddress(networkId, stakeKey),
+		Nonce:         uint64(netResp.JSON200.NodeTip.AbsoluteSlotNumber),
+	}
+	registrationMeta, err := registration.Metadata()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signature, err := signVotingRegistration(ctx, client, req, registrationMeta)
+	if err != nil {
+		return nil, nil, err
+	}
+	signatureMeta, err := EncodeMetadata(map[uint]interface{}{
+		VotingSignatureLabel: map[interface{}]interface{}{1: signature},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body := map[string]interface{}{
+		"payments": []interface{}{
+			map[string]interface{}{
+				"address": ownAddress,
+				"amount": map[string]interface{}{
+					"quantity": amount,
+					"unit":     "lovelace",
+				},
+			},
+		},
+		"metadata": Metadata{
+			VotingRegistrationLabel: registrationMeta[VotingRegistrationLabel],
+			VotingSignatureLabel:    signatureMeta[VotingSignatureLabel],
+		},
+	}
+	if req.Passphrase != "" {
+		body["passphrase"] = req.Passphrase
+	}
+	return body, registration, nil
+}
+
+func signVotingRegistration(ctx context.Context, client ClientWithResponsesInterface, req *VotingRegistrationRequest, meta Metadata) ([]byte, error) {
+	signMeta := new(SignMetadataJSONBody_Metadata)
+	for label, val := range meta {
+		signMeta.Set(fmt.Sprint(label), val)
+	}
+	resp, err := client.SignMetadataWithResponse(ctx, req.WalletId, votingKeyRole, votingKeyIndex, SignMetadataJSONRequestBody{
+		Metadata:   signMeta,
+		Passphrase: req.Passphrase,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, unexpectedResponse("SignMetadata", resp.HTTPResponse, resp.Body)
+	}
+	return resp.Body, nil
+}
+
+// rewardAddress returns the raw reward address for the given network and public stake key.
+func rewardAddress(networkId byte, stakeKey []byte) []byte {
+	return append([]byte{rewardAddressHeader | networkId}, keyHash(stakeKey)...)
+}
@@ -0,0 +1,129 @@
+package wallet
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/godano/cardano-wallet-client/bech32"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type VotingTestSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestVoting(t *testing.T) {
+	testSuite := new(VotingTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *VotingTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+}
+
+// TestBuildVotingRegistration runs BuildVotingRegistration against a fake cardano-wallet server.
+func (s *VotingTestSuite) TestBuildVotingRegistration() {
+	stakeKey := make([]byte, 32)
+	stakeKey[0] = 0x42
+	stakeKeyBech32, err := bech32.Encode("stake_vk", stakeKey)
+	s.NoError(err)
+	ownAddress, err := bech32.Encode("addr_test", append([]byte{0x00}, make([]byte, 56)...))
+	s.NoError(err)
+	signature := make([]byte, 64)
+	signature[63] = 0x17
+
+	var signedMetadata map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/wallets/w1/keys/mutable_account/0", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stakeKeyBech32)
+	})
+	mux.HandleFunc("/v2/wallets/w1/addresses", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]map[string]interface{}{{"id": ownAddress, "state": "unused", "derivation_path": []string{}}})
+	})
+	mux.HandleFunc("/v2/network/information", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"node_tip": map[string]interface{}{"absolute_slot_number": 1234}})
+	})
+	mux.HandleFunc("/v2/wallets/w1/signatures/mutable_account/0", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req struct {
+			Metadata   map[string]interface{} `json:"metadata"`
+			Passphrase string                 `json:"passphrase"`
+		}
+		s.NoError(json.Unmarshal(body, &req))
+		s.Equal("secret12345", req.Passphrase)
+		signedMetadata = req.Metadata
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(signature)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithResponses(server.URL + "/v2")
+	s.NoError(err)
+	votingKey := make([]byte, 32)
+	votingKey[0] = 0x01
+	body, registration, err := BuildVotingRegistration(context.Background(), client, &VotingRegistrationRequest{
+		WalletId:   "w1",
+		VotingKey:  votingKey,
+		Passphrase: "secret12345",
+	})
+	s.NoError(err)
+
+	s.Equal(uint64(1234), registration.Nonce)
+	s.Equal(stakeKey, registration.StakeKey)
+	s.Len(registration.RewardAddress, 29)
+	s.Equal(byte(0xE0), registration.RewardAddress[0]) // Testnet reward address
+	s.Contains(signedMetadata, "61284")
+
+	// Pass the body through JSON, like it would be sent to the server
+	marshalled, err := json.Marshal(body)
+	s.NoError(err)
+	var sent struct {
+		Metadata Metadata `json:"metadata"`
+	}
+	s.NoError(json.Unmarshal(marshalled, &sent))
+	parsed, err := sent.Metadata.Parse()
+	s.NoError(err)
+	s.Equal(map[interface{}]interface{}{1: signature}, parsed[VotingSignatureLabel])
+	s.Equal(votingKey, parsed[VotingRegistrationLabel].(map[interface{}]interface{})[1])
+	s.Equal("secret12345", body.(map[string]interface{})["passphrase"])
+
+	// Without passphrase, the passphrase provider of the client signs and the body contains no passphrase
+	client, err = NewClientWithResponses(server.URL+"/v2", WithPassphraseProvider(PassphraseFunc(func(context.Context) ([]byte, error) {
+		return []byte("secret12345"), nil
+	})))
+	s.NoError(err)
+	body, _, err = BuildVotingRegistration(context.Background(), client, &VotingRegistrationRequest{
+		WalletId:  "w1",
+		VotingKey: votingKey,
+	})
+	s.NoError(err)
+	s.NotContains(body, "passphrase")
+}
+
+func (s *VotingTestSuite) TestParseVotingKey() {
+	key := make([]byte, 32)
+	key[31] = 0xff
+	parsed, err := ParseVotingKey(hex.EncodeToString(key))
+	s.NoError(err)
+	s.Equal(key, parsed)
+
+	encoded, err := bech32.Encode("ed25519_pk", key)
+	s.NoError(err)
+	parsed, err = ParseVotingKey(encoded)
+	s.NoError(err)
+	s.Equal(key, parsed)
+
+	_, err = ParseVotingKey("abcd")
+	s.Error(err)
+}
@@ -0,0 +1,210 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/godano/cardano-wallet-client/hdkey"
+	"github.com/godano/cardano-wallet-client/mnemonic"
+)
+
+// Limits of the PostWallet request body, as defined in the API specification
+const (
+	MinMnemonicWords             = 15
+	MaxMnemonicWords             = 24
+	MinMnemonicSecondFactorWords = 9
+	MaxMnemonicSecondFactorWords = 12
+	MinPassphraseLength          = 10
+	MaxPassphraseLength          = 255
+	MinAddressPoolGap            = 10
+	MaxAddressPoolGap            = 100000
+	DefaultAddressPoolGap        = 20
+	MinByronMnemonicWords        = 12
+	MaxByronMnemonicWords        = 24
+)
+
+// Styles of Byron wallets, see PostByronWallet
+const (
+	ByronWalletStyleRandom = "random"
+	ByronWalletStyleIcarus = "icarus"
+	ByronWalletStyleTrezor = "trezor"
+	ByronWalletStyleLedger = "ledger"
+)
+
+// ByronWalletStyles contains all styles accepted by ByronWalletPostData.
+var ByronWalletStyles = []string{ByronWalletStyleRandom, ByronWalletStyleIcarus, ByronWalletStyleTrezor, ByronWalletStyleLedger}
+
+// WalletPostData is the body of PostWallet for creating or restoring a Shelley wallet from a mnemonic sentence.
+// The mnemonic sentences are validated when marshalling, so invalid phrases are never sent to the server.
+type WalletPostData struct {
+	Name                 string   `json:"name"`
+	MnemonicSentence     []string `json:"mnemonic_sentence"`
+	MnemonicSecondFactor []string `json:"mnemonic_second_factor,omitempty"`
+	Passphrase           string   `json:"passphrase"`
+	AddressPoolGap       *int     `json:"address_pool_gap,omitempty"`
+}
+
+// NewWalletPostData returns a validated body for PostWallet. The second factor is optional and can be nil.
+func NewWalletPostData(name string, passphrase string, sentence []string, secondFactor []string) (*WalletPostData, error) {
+	data := &WalletPostData{
+		Name:                 name,
+		MnemonicSentence:     sentence,
+		MnemonicSecondFactor: secondFactor,
+		Passphrase:           passphrase,
+	}
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Validate checks the name, passphrase and mnemonic sentences, including the BIP-39 checksums.
+func (d *WalletPostData) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("Missing wallet name")
+	}
+	if err := ValidatePassphrase(d.Passphrase); err != nil {
+		return err
+	}
+	if err := mnemonic.ValidateLength(d.MnemonicSentence, MinMnemonicWords, MaxMnemonicWords); err != nil {
+		return fmt.Errorf("Invalid mnemonic sentence: %v", err)
+	}
+	if d.AddressPoolGap != nil && (*d.AddressPoolGap < MinAddressPoolGap || *d.AddressPoolGap > MaxAddressPoolGap) {
+		return fmt.Errorf("Address pool gap must be between %v and %v", MinAddressPoolGap, MaxAddressPoolGap)
+	}
+	if d.MnemonicSecondFactor != nil {
+		err := mnemonic.ValidateLength(d.MnemonicSecondFactor, MinMnemonicSecondFactorWords, MaxMnemonicSecondFactorWords)
+		if err != nil {
+			return fmt.Errorf("Invalid mnemonic second factor: %v", err)
+		}
+	}
+	return nil
+}
+
+// MarshalJSON validates the data before marshalling it.
+func (d WalletPostData) MarshalJSON() ([]byte, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	type plainWalletPostData WalletPostData // Avoid recursion
+	return json.Marshal(plainWalletPostData(d))
+}
+
+// ByronWalletPostData is the body of PostByronWallet for restoring a Byron wallet from a mnemonic sentence.
+// Like WalletPostData, it is validated when marshalling.
+type ByronWalletPostData struct {
+	Style            string   `json:"style"`
+	Name             string   `json:"name"`
+	MnemonicSentence []string `json:"mnemonic_sentence"`
+	Passphrase       string   `json:"passphrase"`
+}
+
+// NewByronWalletPostData returns a validated body for PostByronWallet. The style must be one of ByronWalletStyles.
+func NewByronWalletPostData(style string, name string, passphrase string, sentence []string) (*ByronWalletPostData, error) {
+	data := &ByronWalletPostData{
+		Style:            style,
+		Name:             name,
+		MnemonicSentence: sentence,
+		Passphrase:       passphrase,
+	}
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Validate checks the style, name, passphrase and mnemonic sentence, including the BIP-39 checksum.
+func (d *ByronWalletPostData) Validate() error {
+	validStyle := false
+	for _, style := range ByronWalletStyles {
+		validStyle = validStyle || style == d.Style
+	}
+	if !validStyle {
+		return fmt.Errorf("Unknown Byron wallet style %v, expected one of %v", d.Style, ByronWalletStyles)
+	}
+	if d.Name == "" {
+		return fmt.Errorf("Missing wallet name")
+	}
+	if err := ValidatePassphrase(d.Passphrase); err != nil {
+		return err
+	}
+	if err := mnemonic.ValidateLength(d.MnemonicSentence, MinByronMnemonicWords, MaxByronMnemonicWords); err != nil {
+		return fmt.Errorf("Invalid mnemonic sentence: %v", err)
+	}
+	return nil
+}
+
+// MarshalJSON validates the data before marshalling it.
+func (d ByronWalletPostData) MarshalJSON() ([]byte, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	type plainByronWalletPostData ByronWalletPostData // Avoid recursion
+	return json.Marshal(plainByronWalletPostData(d))
+}
+
+// AccountPostData is the body of PostWallet for restoring a watch-only Shelley wallet from an extended account public key.
+// Watch-only wallets track balances and transactions, but cannot sign. Like WalletPostData, it is validated when marshalling.
+type AccountPostData struct {
+	Name             string `json:"name"`
+	AccountPublicKey string `json:"account_public_key"`
+	AddressPoolGap   *int   `json:"address_pool_gap,omitempty"`
+}
+
+// NewAccountPostData returns a validated body for PostWallet. The account public key can be encoded as Bech32 (acct_xvk...)
+// or as hex string, it is converted to the hex format expected by the API.
+func NewAccountPostData(name string, accountPublicKey string) (*AccountPostData, error) {
+	key, err := hdkey.ParseXPub(accountPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid account public key: %v", err)
+	}
+	data := &AccountPostData{
+		Name:             name,
+		AccountPublicKey: hex.EncodeToString(key.Bytes()),
+	}
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Validate checks the name, the account public key (64 bytes hex-encoded) and the address pool gap.
+func (d *AccountPostData) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("Missing wallet name")
+	}
+	key, err := hex.DecodeString(d.AccountPublicKey)
+	if err == nil {
+		_, err = hdkey.NewXPub(key)
+	}
+	if err != nil {
+		return fmt.Errorf("Invalid account public key: %v", err)
+	}
+	if d.AddressPoolGap != nil && (*d.AddressPoolGap < MinAddressPoolGap || *d.AddressPoolGap > MaxAddressPoolGap) {
+		return fmt.Errorf("Address pool gap must be between %v and %v", MinAddressPoolGap, MaxAddressPoolGap)
+	}
+	return nil
+}
+
+// MarshalJSON validates the data before marshalling it.
+func (d AccountPostData) MarshalJSON() ([]byte, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	type plainAccountPostData AccountPostData // Avoid recursion
+	return json.Marshal(plainAccountPostData(d))
+}
+
+// ValidatePassphrase checks the length limits of wallet passphrases.
+func ValidatePassphrase(passphrase string) error {
+	return validatePassphraseLength(utf8.RuneCountInString(passphrase))
+}
+
+func validatePassphraseLength(length int) error {
+	if length < MinPassphraseLength || length > MaxPassphraseLength {
+		return fmt.Errorf("Passphrase must have between %v and %v characters", MinPassphraseLength, MaxPassphraseLength)
+	}
+	return nil
+}
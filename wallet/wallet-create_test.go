@@ -0,0 +1,176 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/godano/cardano-wallet-client/mnemonic"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type WalletCreateTestSuite struct {
+	suite.Suite
+	*require.Assertions
+}
+
+func TestWalletCreate(t *testing.T) {
+	testSuite := new(WalletCreateTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *WalletCreateTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+}
+
+func (s *WalletCreateTestSuite) TestValidData() {
+	sentence, err := mnemonic.Generate(mnemonic.DefaultWords)
+	s.NoError(err)
+	secondFactor, err := mnemonic.Generate(mnemonic.SecondFactorWords)
+	s.NoError(err)
+	data, err := NewWalletPostData("test", "0123456789", sentence, secondFactor)
+	s.NoError(err)
+
+	marshalled, err := json.Marshal(data)
+	s.NoError(err)
+	var decoded map[string]interface{}
+	s.NoError(json.Unmarshal(marshalled, &decoded))
+	s.Equal("test", decoded["name"])
+	s.Len(decoded["mnemonic_sentence"], mnemonic.DefaultWords)
+	s.Len(decoded["mnemonic_second_factor"], mnemonic.SecondFactorWords)
+	s.NotContains(decoded, "address_pool_gap")
+}
+
+func (s *WalletCreateTestSuite) TestInvalidData() {
+	sentence, err := mnemonic.Generate(mnemonic.DefaultWords)
+	s.NoError(err)
+	short, err := mnemonic.Generate(12)
+	s.NoError(err)
+	badChecksum := strings.Fields(strings.Repeat("abandon ", 24)) // Valid with "art" as last word
+
+	for _, data := range []*WalletPostData{
+		{Name: "", Passphrase: "0123456789", MnemonicSentence: sentence},
+		{Name: "test", Passphrase: "short", MnemonicSentence: sentence},
+		{Name: "test", Passphrase: "0123456789", MnemonicSentence: short},
+		{Name: "test", Passphrase: "0123456789", MnemonicSentence: badChecksum},
+		{Name: "test", Passphrase: "0123456789", MnemonicSentence: sentence, MnemonicSecondFactor: sentence},
+	} {
+		s.Error(data.Validate())
+		_, err := NewWalletPostData(data.Name, data.Passphrase, data.MnemonicSentence, data.MnemonicSecondFactor)
+		s.Error(err)
+		_, err = json.Marshal(data)
+		s.Error(err)
+	}
+}
+
+func (s *WalletCreateTestSuite) TestInvalidDataIsNotSent() {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	s.NoError(err)
+
+	data := &WalletPostData{Name: "test", Passphrase: "0123456789", MnemonicSentence: strings.Fields(strings.Repeat("abandon ", 15))}
+	_, err = client.PostWallet(context.Background(), data)
+	s.Error(err)
+	s.Equal(0, requests)
+}
+
+func (s *WalletCreateTestSuite) TestByronData() {
+	sentence, err := mnemonic.Generate(12)
+	s.NoError(err)
+	for _, style := range ByronWalletStyles {
+		data, err := NewByronWalletPostData(style, "test", "0123456789", sentence)
+		s.NoError(err)
+		marshalled, err := json.Marshal(data)
+		s.NoError(err)
+		s.Contains(string(marshalled), `"style":"`+style+`"`)
+	}
+	_, err = NewByronWalletPostData("daedalus", "test", "0123456789", sentence)
+	s.Error(err)
+	short, err := mnemonic.Generate(9)
+	s.NoError(err)
+	_, err = NewByronWalletPostData(ByronWalletStyleRandom, "test", "0123456789", short)
+	s.Error(err)
+}
+
+func (s *WalletCreateTestSuite) TestAddressPoolGap() {
+	sentence, err := mnemonic.Generate(mnemonic.DefaultWords)
+	s.NoError(err)
+	data, err := NewWalletPostData("test", "0123456789", sentence, nil)
+	s.NoError(err)
+	for gap, valid := range map[int]bool{9: false, 10: true, DefaultAddressPoolGap: true, 100001: false} {
+		gap := gap
+		data.AddressPoolGap = &gap
+		s.Equal(valid, data.Validate() == nil, "gap %v", gap)
+	}
+}
+
+func (s *WalletCreateTestSuite) TestWaitForWallet() {
+	states := []string{
+		`{"status": "syncing", "progress": {"quantity": 10.5, "unit": "percent"}}`,
+		`{"status": "syncing", "progress": {"quantity": 99, "unit": "percent"}}`,
+		`{"status": "ready"}`,
+	}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Equal("/v2/byron-wallets/w1", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "w1", "state": ` + states[requests] + `}`))
+		requests++
+	}))
+	defer server.Close()
+	client, err := NewClientWithResponses(server.URL + "/v2")
+	s.NoError(err)
+
+	var progress []float64
+	err = WaitForWallet(context.Background(), client, "w1", true, time.Millisecond, func(state *WalletSyncState) {
+		progress = append(progress, state.Progress)
+	})
+	s.NoError(err)
+	s.Equal([]float64{10.5, 99, 0}, progress)
+
+	// Cancel while the wallet is still syncing
+	ctx, cancel := context.WithCancel(context.Background())
+	requests = 0
+	err = WaitForWallet(ctx, client, "w1", true, time.Hour, func(state *WalletSyncState) {
+		cancel()
+	})
+	s.Equal(context.Canceled, err)
+	s.Equal(1, requests)
+}
+
+func (s *WalletCreateTestSuite) TestAccountData() {
+	const bech32Key = "acct_xvk1eame4ge0x5yrwpuqs5eyw89kfmjpgfkfh02xzdx6c2k9k2swcr5clf0u634tm82x6nv2j750x3j7938g70ya4k0lv6pr59s7etw2vpqgfmule"
+	data, err := NewAccountPostData("watch", bech32Key)
+	s.NoError(err)
+	s.Len(data.AccountPublicKey, 128)
+
+	// The hex encoding is accepted as well
+	fromHex, err := NewAccountPostData("watch", data.AccountPublicKey)
+	s.NoError(err)
+	s.Equal(data, fromHex)
+
+	encoded, err := json.Marshal(data)
+	s.NoError(err)
+	s.JSONEq(`{"name": "watch", "account_public_key": "`+data.AccountPublicKey+`"}`, string(encoded))
+
+	_, err = NewAccountPostData("", bech32Key)
+	s.Error(err)
+	_, err = NewAccountPostData("watch", data.AccountPublicKey[:64])
+	s.Error(err)
+	_, err = NewAccountPostData("watch", "addr_vk1w0l2sr2zgfm26ztc6nl9xy8ghsk5sh6ldwemlpmp9xylzy4dtf7st80zhd")
+	s.Error(err)
+
+	gap := 5
+	data.AddressPoolGap = &gap
+	_, err = json.Marshal(data)
+	s.Error(err)
+}
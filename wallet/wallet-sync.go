@@ -0,0 +1,97 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Values of WalletSyncState.Status
+const (
+	WalletStatusReady         = "ready"
+	WalletStatusSyncing       = "syncing"
+	WalletStatusNotResponding = "not_responding"
+)
+
+// DefaultWalletPollInterval is the default interval between requests in WaitForWallet.
+const DefaultWalletPollInterval = 2 * time.Second
+
+// WalletSyncState is the state of a Shelley or Byron wallet, as returned by GetWallet and GetByronWallet.
+type WalletSyncState struct {
+	Status string
+	// Progress is the restoration progress in percent, only set while syncing
+	Progress float64
+}
+
+// WaitForWallet polls the state of the given wallet until its status is "ready", or the context is done.
+// If onProgress is not nil, it is called with every received state. An interval of 0 selects DefaultWalletPollInterval.
+func WaitForWallet(ctx context.Context, client ClientWithResponsesInterface, walletId string, isByron bool,
+	interval time.Duration, onProgress func(state *WalletSyncState)) error {
+	if interval <= 0 {
+		interval = DefaultWalletPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		state, err := GetWalletSyncState(ctx, client, walletId, isByron)
+		if err != nil {
+			return err
+		}
+		if onProgress != nil {
+			onProgress(state)
+		}
+		if state.Status == WalletStatusReady {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetWalletSyncState queries the state of a Shelley wallet (GetWallet) or Byron wallet (GetByronWallet).
+func GetWalletSyncState(ctx context.Context, client ClientWithResponsesInterface, walletId string, isByron bool) (*WalletSyncState, error) {
+	var operation string
+	var resp *http.Response
+	var body []byte
+	if isByron {
+		operation = "GetByronWallet"
+		parsed, err := client.GetByronWalletWithResponse(ctx, walletId)
+		if err != nil {
+			return nil, err
+		}
+		resp, body = parsed.HTTPResponse, parsed.Body
+	} else {
+		operation = "GetWallet"
+		parsed, err := client.GetWalletWithResponse(ctx, walletId)
+		if err != nil {
+			return nil, err
+		}
+		resp, body = parsed.HTTPResponse, parsed.Body
+	}
+	if resp == nil || resp.StatusCode != http.StatusOK {
+		return nil, unexpectedResponse(operation, resp, body)
+	}
+
+	// Byron and Shelley wallets share the format of the state field
+	var parsed struct {
+		State struct {
+			Status   string `json:"status"`
+			Progress *struct {
+				Quantity float64 `json:"quantity"`
+			} `json:"progress"`
+		} `json:"state"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("%v: failed to parse wallet state: %v", operation, err)
+	}
+	state := &WalletSyncState{Status: parsed.State.Status}
+	if parsed.State.Progress != nil {
+		state.Progress = parsed.State.Progress.Quantity
+	}
+	return state, nil
+}
@@ -0,0 +1,81 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// signingOperations are the operations on Shelley wallets that require the wallet passphrase, i.e. the private keys.
+// They cannot succeed for watch-only wallets restored from an account public key.
+var signingOperations = map[string]bool{
+	"PostTransaction":      true,
+	"JoinStakePool":        true,
+	"QuitStakePool":        true,
+	"PutWalletPassphrase":  true,
+	"PostAccountKey":       true,
+	"SignMetadata":         true,
+	"MigrateShelleyWallet": true,
+}
+
+// IsSigningOperation returns true, if the operation with the given Id requires the private keys of a Shelley wallet.
+func IsSigningOperation(operationId string) bool {
+	return signingOperations[operationId]
+}
+
+// WatchOnlyWalletError is returned by requests rejected by the watch-only guard, see WithWatchOnlyGuard().
+type WatchOnlyWalletError struct {
+	Operation string
+	WalletId  string
+}
+
+func (e *WatchOnlyWalletError) Error() string {
+	return fmt.Sprintf("%v: wallet %v is watch-only (restored from an account public key) and cannot sign", e.Operation, e.WalletId)
+}
+
+// IsWatchOnly queries the given Shelley wallet through GetWallet and returns true, if it has no passphrase.
+// This is the case for wallets restored from an account public key, see AccountPostData.
+func IsWatchOnly(ctx context.Context, client ClientWithResponsesInterface, walletId string) (bool, error) {
+	resp, err := client.GetWalletWithResponse(ctx, walletId)
+	if err != nil {
+		return false, err
+	}
+	if resp.StatusCode() != http.StatusOK || resp.JSON200 == nil {
+		return false, unexpectedResponse("GetWallet", resp.HTTPResponse, resp.Body)
+	}
+	return resp.JSON200.Passphrase == nil, nil
+}
+
+// WithWatchOnlyGuard returns a ClientOption that rejects signing operations (see IsSigningOperation) on watch-only
+// wallets with a *WatchOnlyWalletError, instead of sending them to the server.
+// The wallet is queried through GetWallet before every checked request.
+func WithWatchOnlyGuard() ClientOption {
+	return func(c *Client) error {
+		guard := &watchOnlyGuard{client: &ClientWithResponses{ClientInterface: c}}
+		c.RequestEditors = append(c.RequestEditors, guard.checkRequest)
+		return nil
+	}
+}
+
+type watchOnlyGuard struct {
+	client ClientWithResponsesInterface
+}
+
+func (g *watchOnlyGuard) checkRequest(ctx context.Context, req *http.Request) error {
+	op := OperationForRequest(req)
+	if op == nil || !signingOperations[op.Id] {
+		return nil
+	}
+	walletId := op.PathParameters(req.URL.Path)["walletId"]
+	if walletId == "" {
+		return nil
+	}
+	watchOnly, err := IsWatchOnly(ctx, g.client, walletId)
+	if err != nil {
+		return fmt.Errorf("Watch-only guard failed to query wallet %v: %v", walletId, err)
+	}
+	if watchOnly {
+		return &WatchOnlyWalletError{Operation: op.Id, WalletId: walletId}
+	}
+	return nil
+}
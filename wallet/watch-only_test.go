@@ -0,0 +1,86 @@
+package wallet
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type WatchOnlyTestSuite struct {
+	suite.Suite
+	*require.Assertions
+
+	server    *httptest.Server
+	submitted int
+}
+
+func TestWatchOnly(t *testing.T) {
+	testSuite := new(WatchOnlyTestSuite)
+	suite.Run(t, testSuite)
+}
+
+func (s *WatchOnlyTestSuite) SetupSuite() {
+	s.Assertions = s.Require()
+}
+
+func (s *WatchOnlyTestSuite) SetupTest() {
+	s.submitted = 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/wallets/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/wallets/watch":
+			w.Write([]byte(`{"id": "watch"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/wallets/full":
+			w.Write([]byte(`{"id": "full", "passphrase": {"last_updated_at": "2021-01-01T00:00:00Z"}}`))
+		default:
+			s.submitted++
+			w.Write([]byte("{}"))
+		}
+	})
+	s.server = httptest.NewServer(mux)
+}
+
+func (s *WatchOnlyTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *WatchOnlyTestSuite) TestIsWatchOnly() {
+	client, err := NewClientWithResponses(s.server.URL + "/v2")
+	s.NoError(err)
+	watchOnly, err := IsWatchOnly(context.Background(), client, "watch")
+	s.NoError(err)
+	s.True(watchOnly)
+	watchOnly, err = IsWatchOnly(context.Background(), client, "full")
+	s.NoError(err)
+	s.False(watchOnly)
+}
+
+func (s *WatchOnlyTestSuite) TestGuard() {
+	client, err := NewClient(s.server.URL+"/v2", WithWatchOnlyGuard())
+	s.NoError(err)
+	ctx := context.Background()
+	body := `{"passphrase": "0123456789", "payments": []}`
+
+	_, err = client.PostTransactionWithBody(ctx, "watch", "application/json", strings.NewReader(body))
+	s.Error(err)
+	s.IsType(new(WatchOnlyWalletError), err)
+	s.Equal(&WatchOnlyWalletError{Operation: "PostTransaction", WalletId: "watch"}, err)
+	s.Equal(0, s.submitted)
+
+	resp, err := client.PostTransactionWithBody(ctx, "full", "application/json", strings.NewReader(body))
+	s.NoError(err)
+	s.Equal(http.StatusOK, resp.StatusCode)
+	s.Equal(1, s.submitted)
+
+	// Operations that do not sign are not checked
+	resp, err = client.PostTransactionFeeWithBody(ctx, "watch", "application/json", strings.NewReader(body))
+	s.NoError(err)
+	s.Equal(http.StatusOK, resp.StatusCode)
+	s.Equal(2, s.submitted)
+}